@@ -0,0 +1,180 @@
+// Command gounion-gen generates helper code for union interfaces
+// (interfaces with unexported marker methods).
+//
+// Usage:
+//
+//	//go:generate gounion-gen -type=Shape -target=registry
+//
+// The generated code is written to gounion_gen.go in the package directory.
+// With -check, gounion-gen reports whether that file is up to date instead
+// of writing it, which is useful in CI to catch stale generated code.
+//
+// With -lock=FILE, the discriminators identifying members in encoded values
+// are checked against those recorded in FILE, which is then updated with
+// the discriminators of new members, so that committing it catches changes
+// breaking wire compatibility: changed discriminators, and members renamed
+// or removed along with theirs. Removed members are retired by prefixing
+// their name with "-" in FILE, which keeps their discriminator from being
+// reused.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"github.com/YuitoSato/gounion/internal/gen"
+
+	"golang.org/x/tools/go/packages"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of union names; default all unions in the package")
+	targets   = flag.String("target", "registry", "comma-separated list of targets: "+strings.Join(gen.Targets(), ", "))
+	output    = flag.String("output", "gounion_gen.go", "output file name, relative to the package directory")
+	check     = flag.Bool("check", false, "report whether the output file is up to date instead of writing it")
+	lockFile  = flag.String("lock", "", "JSON file of member discriminators to check against and update")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: gounion-gen [flags] [package]\n\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	pattern := "."
+	if flag.NArg() > 0 {
+		pattern = flag.Arg(0)
+	}
+
+	if err := run(pattern); err != nil {
+		fmt.Fprintf(os.Stderr, "gounion-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(pattern string) error {
+	pkg, err := loadPackage(pattern, *output)
+	if err != nil {
+		return err
+	}
+
+	var lock registry.DiscriminatorLock
+	if *lockFile != "" {
+		if lock, err = readLock(*lockFile); err != nil {
+			return err
+		}
+	}
+
+	src, err := gen.Generate(pkg.Types, gen.Options{
+		Types:   splitList(*typeNames),
+		Targets: splitList(*targets),
+		Files:   pkg.Syntax,
+		Lock:    lock,
+	})
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(filepath.Dir(pkg.GoFiles[0]), *output)
+	var lockData bytes.Buffer
+	if lock != nil {
+		if _, err := lock.WriteTo(&lockData); err != nil {
+			return err
+		}
+	}
+	if *check {
+		current, err := os.ReadFile(path)
+		if err != nil || !bytes.Equal(current, src) {
+			return fmt.Errorf("%s is out of date; run gounion-gen", path)
+		}
+		if lock != nil {
+			if current, err := os.ReadFile(*lockFile); err != nil || !bytes.Equal(current, lockData.Bytes()) {
+				return fmt.Errorf("%s is out of date; run gounion-gen", *lockFile)
+			}
+		}
+		return nil
+	}
+
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		return err
+	}
+	if lock != nil {
+		return os.WriteFile(*lockFile, lockData.Bytes(), 0o644)
+	}
+	return nil
+}
+
+// readLock reads the discriminator lock in path, or returns an empty lock
+// if the file does not exist yet.
+func readLock(path string) (registry.DiscriminatorLock, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return registry.DiscriminatorLock{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	lock, err := registry.ReadDiscriminatorLock(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if lock == nil {
+		lock = registry.DiscriminatorLock{}
+	}
+	return lock, nil
+}
+
+// loadPackage loads and type-checks the package matching pattern. The
+// previously generated output file is reduced to its package clause, so a
+// stale file referencing removed members doesn't break type checking.
+func loadPackage(pattern, output string) (*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax | packages.NeedTypes,
+		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+			mode := parser.ParseComments
+			if filepath.Base(filename) == output {
+				mode = parser.PackageClauseOnly
+			}
+			return parser.ParseFile(fset, filename, src, mode)
+		},
+	}
+
+	pkgs, err := packages.Load(cfg, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, fmt.Errorf("failed to load %s", pattern)
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%s matches %d packages, want exactly one", pattern, len(pkgs))
+	}
+	if len(pkgs[0].GoFiles) == 0 {
+		return nil, fmt.Errorf("%s has no Go files", pattern)
+	}
+
+	return pkgs[0], nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty elements.
+func splitList(s string) []string {
+	var list []string
+	for _, elem := range strings.Split(s, ",") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			list = append(list, elem)
+		}
+	}
+	return list
+}
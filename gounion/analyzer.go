@@ -1,6 +1,11 @@
 package gounion
 
 import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
@@ -8,22 +13,155 @@ import (
 
 // Analyzer is the gounion analyzer that checks exhaustiveness of type switches
 // on union interfaces (interfaces with private marker methods).
-var Analyzer = &analysis.Analyzer{
-	Name:      "gounion",
-	Doc:       "checks exhaustiveness of type switches on union interfaces",
-	Run:       run,
-	Requires:  []*analysis.Analyzer{inspect.Analyzer},
-	FactTypes: []analysis.Fact{new(UnionInterface)},
+var Analyzer = newAnalyzer(&config{})
+
+// newAnalyzer creates a gounion analyzer whose options are read from cfg.
+// The options are also registered as flags on the analyzer.
+func newAnalyzer(cfg *config) *analysis.Analyzer {
+	a := &analysis.Analyzer{
+		Name: "gounion",
+		Doc:  "checks exhaustiveness of type switches on union interfaces",
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			return run(pass, cfg)
+		},
+		Requires:   []*analysis.Analyzer{inspect.Analyzer},
+		FactTypes:  []analysis.Fact{new(UnionInterface), new(EnumType)},
+		ResultType: resultType,
+	}
+	cfg.registerFlags(&a.Flags)
+	return a
 }
 
-func run(pass *analysis.Pass) (interface{}, error) {
+func run(pass *analysis.Pass, cfg *config) (interface{}, error) {
 	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	findings := recordFindings(pass)
+	defer findingRecorders.Delete(pass)
+	if err := installReporter(pass, cfg); err != nil {
+		return nil, err
+	}
+
+	// Pre-scan: most packages in a large dependency graph neither declare
+	// interfaces nor contain type switches, so skip them cheaply.
+	hasInterfaces, hasTypeSwitches := scanPackage(inspect)
+
+	if hasInterfaces && cfg.Debug {
+		debugInterfaces(pass, inspect, cfg)
+	}
 
 	// Phase 1: Detect union interfaces and export facts
-	exportUnionFacts(pass, inspect)
+	if hasInterfaces && !cfg.LazyFacts {
+		exportUnionFacts(pass, inspect, cfg.ExcludeEmbedded)
+	}
+
+	// Export facts for enums declared with //gounion:enum
+	if directiveLines(pass, registry.EnumDirective) != nil {
+		exportEnumFacts(pass)
+	}
+
+	// Check the members of unions frozen with //gounion:frozen
+	if hasInterfaces {
+		checkFrozenUnions(pass, inspect, cfg.ExcludeEmbedded)
+	}
+
+	// Check the naming of marker methods
+	if hasInterfaces && cfg.MarkerName != "" {
+		markerName, err := cfg.markerNameTemplate()
+		if err != nil {
+			return nil, err
+		}
+		checkMarkerNames(pass, inspect, markerName)
+	}
+
+	// Report unions with too many members
+	if hasInterfaces && cfg.MaxMembers > 0 {
+		checkUnionSizes(pass, inspect, cfg.MaxMembers, cfg.ExcludeEmbedded)
+	}
+
+	// Report types that are members of several unions
+	if hasInterfaces && cfg.SharedMembers {
+		checkSharedMembers(pass, cfg.ExcludeEmbedded)
+	}
+
+	// Report unions with the same members as another one
+	if hasInterfaces && cfg.IdenticalUnions {
+		checkIdenticalUnions(pass, cfg.ExcludeEmbedded)
+	}
+
+	// Report members with invalid or changed discriminators
+	if hasInterfaces && (cfg.Discriminators || cfg.DiscriminatorLock != "") {
+		lock, err := cfg.discriminatorLock()
+		if err != nil {
+			return nil, err
+		}
+		checkDiscriminators(pass, cfg.ExcludeEmbedded, lock)
+	}
+
+	cache := newUnionCache(pass, cfg)
 
 	// Phase 2: Check type switch exhaustiveness
-	checkTypeSwitches(pass, inspect)
+	result := new(Result)
+	if hasInterfaces {
+		result.Unions = declaredUnions(pass.Pkg, cfg.ExcludeEmbedded)
+	}
+	if name, ok := strings.CutPrefix(cfg.Explain, pass.Pkg.Path()+"."); ok {
+		if typeName, ok := pass.Pkg.Scope().Lookup(name).(*types.TypeName); ok {
+			result.Explanation = explainUnion(pass, cfg, typeName)
+		}
+	}
+	if hasTypeSwitches {
+		defaultBody, err := cfg.defaultBodyTemplate()
+		if err != nil {
+			return nil, err
+		}
+		result.Switches = checkTypeSwitches(pass, inspect, cfg, cache, defaultBody)
+	}
+
+	// Check switch exhaustiveness on enums
+	checkEnumSwitches(pass, inspect, cfg)
+
+	// Phase 3: Check calls to the gounionrt helpers
+	if importsRuntime(pass.Pkg) {
+		result.Uses = append(result.Uses, checkRuntimeCalls(pass, inspect, cache)...)
+	}
+
+	// Phase 4: Check errors.As chains on error unions
+	if cfg.ErrorsAs && importsErrors(pass.Pkg) {
+		checkErrorsAsChains(pass, inspect, cache)
+	}
+
+	// Phase 5: Check composite literals marked with //gounion:all-members
+	result.Uses = append(result.Uses, checkAllMembersLiterals(pass, inspect, cache)...)
+
+	// List the files generated by gounion-gen referring to unions
+	result.Uses = append(result.Uses, generatedUses(pass, cache)...)
+
+	if cfg.Summary {
+		reportSummary(pass, result.Switches)
+	}
+
+	result.Findings = findings.findings
+	return result, nil
+}
+
+// scanPackage reports whether the package contains any interface types
+// and any type switch statements. It stops as soon as both are found.
+func scanPackage(inspect *inspector.Inspector) (hasInterfaces, hasTypeSwitches bool) {
+	nodeFilter := []ast.Node{
+		(*ast.InterfaceType)(nil),
+		(*ast.TypeSwitchStmt)(nil),
+	}
+
+	for n := range inspect.PreorderSeq(nodeFilter...) {
+		switch n.(type) {
+		case *ast.InterfaceType:
+			hasInterfaces = true
+		case *ast.TypeSwitchStmt:
+			hasTypeSwitches = true
+		}
+		if hasInterfaces && hasTypeSwitches {
+			break
+		}
+	}
 
-	return nil, nil
+	return hasInterfaces, hasTypeSwitches
 }
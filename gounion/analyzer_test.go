@@ -1,20 +1,540 @@
 package gounion_test
 
 import (
+	"go/ast"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/YuitoSato/gounion/gounion"
+	"github.com/YuitoSato/gounion/gounion/fixtures"
 
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/types/typeutil"
 )
 
 func TestAnalyzer(t *testing.T) {
 	testdata := analysistest.TestData()
 
 	// Run tests on all test packages
-	// The order matters: union must be analyzed before consumer
-	analysistest.Run(t, testdata, gounion.Analyzer,
+	// The order matters: union and enum must be analyzed before consumer
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
 		"union",
+		"enum",
 		"consumer",
+		"matcher",
+		"allmembers",
+		"intersection",
+		"identity",
+		"generic",
+		"manymembers",
+		"layered",
+		"fileignore",
+		"lintignore",
+		"ignore",
+		"widened",
+		"aliases",
+		"fillcases",
 	)
 }
+
+func TestAnalyzerIgnoreDate(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("ignore-date", "2030-06-30"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("ignore-date", "")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"ignoredate",
+	)
+}
+
+func TestAnalyzerFileIgnoreCategory(t *testing.T) {
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, gounion.Analyzer, "fileignore")
+
+	// Directive diagnostics are in the ignore category, so that they can be
+	// told from those of switches.
+	found := 0
+	for _, r := range results {
+		for _, d := range r.Diagnostics {
+			if strings.Contains(d.Message, "//gounion:file-ignore directive") {
+				found++
+				if d.Category != "ignore" {
+					t.Errorf("%q has category %q, want ignore", d.Message, d.Category)
+				}
+			}
+		}
+	}
+	if found != 2 {
+		t.Errorf("got %d directive diagnostics, want 2", found)
+	}
+}
+
+func TestAnalyzerFrozen(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"frozen",
+	)
+}
+
+func TestAnalyzerLazyFacts(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("lazy-facts", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("lazy-facts", "false")
+
+	// In lazy mode no facts are exported, so only the consumer package
+	// (which expects diagnostics but no facts) is checked.
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"consumer",
+	)
+}
+
+func TestAnalyzerMaxFileLines(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("max-file-lines", "40"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("max-file-lines", "0")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"largefile",
+	)
+}
+
+func TestAnalyzerCheckReportUnhandled(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("check-report-unhandled", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("check-report-unhandled", "false")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"reportunhandled",
+	)
+}
+
+func TestAnalyzerCheckUnhandledMember(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("check-unhandled-member", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("check-unhandled-member", "false")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"unhandledmember",
+	)
+}
+
+func TestAnalyzerDiscriminators(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	lock := filepath.Join(testdata, "discriminators.lock")
+	if err := gounion.Analyzer.Flags.Set("discriminator-lock", lock); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("discriminator-lock", "")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"discriminator",
+	)
+}
+
+func TestAnalyzerUnionOverrides(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("strict-default", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("strict-default", "false")
+
+	if err := gounion.Analyzer.Flags.Set("union", "overrides.Event:strict-default=false"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("union", "")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"overrides",
+	)
+}
+
+func TestAnalyzerSummary(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("summary", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("summary", "false")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"summary",
+	)
+}
+
+func TestAnalyzerDefiniteResult(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("definite-result", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("definite-result", "false")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"definiteresult",
+	)
+}
+
+func TestAnalyzerRequireDefault(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("require-default", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("require-default", "false")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"requiredefault",
+	)
+}
+
+func TestAnalyzerLineDirectives(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("line-directives", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("line-directives", "false")
+
+	results := analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"linedirective",
+	)
+
+	// Diagnostics stay in the generated file, with the template position
+	// as related information, and are recorded as findings.
+	for _, r := range results {
+		result := r.Result.(*gounion.Result)
+		for _, d := range r.Diagnostics {
+			if len(d.Related) == 0 {
+				continue
+			}
+			if posn := r.Pass.Fset.PositionFor(d.Pos, false); filepath.Base(posn.Filename) != "linedirective.go" || posn.Line != 23 {
+				t.Errorf("diagnostic at %s, want linedirective.go:23", posn)
+			}
+			if len(d.Related) != 1 || d.Related[0].Pos != d.Pos || d.Related[0].Message != "generated from shapes.templ:11" {
+				t.Errorf("related information = %v, want the template position", d.Related)
+			}
+			var finding *gounion.Finding
+			for i, f := range result.Findings {
+				if f.Pos == d.Pos {
+					finding = &result.Findings[i]
+				}
+			}
+			want := []string{"union.*Rectangle", "union.*Triangle"}
+			if finding == nil || finding.Union != "Shape" || finding.UnionPkg != "union" || !reflect.DeepEqual(finding.Missing, want) {
+				t.Errorf("finding = %+v, want one about Shape missing %q", finding, want)
+			}
+			return
+		}
+	}
+	t.Error("no diagnostic reported with the template position")
+}
+
+func TestAnalyzerBuildConstraints(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"constraints",
+	)
+}
+
+func TestAnalyzerPerMember(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("per-member", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("per-member", "false")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"permember",
+	)
+}
+
+func TestAnalyzerGroupCases(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	for _, name := range []string{"per-member", "group-cases"} {
+		if err := gounion.Analyzer.Flags.Set(name, "true"); err != nil {
+			t.Fatal(err)
+		}
+		defer gounion.Analyzer.Flags.Set(name, "false")
+	}
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"groupcases",
+	)
+}
+
+func TestAnalyzerSettings(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	for name, value := range map[string]string{
+		"skip-tests":  "true",
+		"exclude":     "settings/generated/...",
+		"terminators": "log.Fatal,log.Fatalf,log.Logger.Panic*,settings.Logger.Fatal*",
+	} {
+		if err := gounion.Analyzer.Flags.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func() {
+		gounion.Analyzer.Flags.Set("skip-tests", "false")
+		gounion.Analyzer.Flags.Set("exclude", "")
+		gounion.Analyzer.Flags.Set("terminators", "")
+	}()
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"settings",
+		"settings/generated",
+	)
+}
+
+func TestPluginSettings(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	// The settings of TestAnalyzerSettings, as decoded from YAML, set the
+	// flags of the plugin's analyzer.
+	plugin, err := gounion.New(map[string]any{
+		"skip-tests":     true,
+		"max-file-lines": 1000,
+		"exclude":        []any{"settings/generated/..."},
+		"terminators":    []any{"log.Fatal", "log.Fatalf", "log.Logger.Panic*", "settings.Logger.Fatal*"},
+		"unions": map[string]any{
+			"settings.Shape": map[string]any{"strict-default": false},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	analyzers, err := plugin.BuildAnalyzers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := analyzers[0].Flags.Lookup("terminators").Value.String(), "log.Fatal,log.Fatalf,log.Logger.Panic*,settings.Logger.Fatal*"; got != want {
+		t.Errorf("terminators flag = %q, want %q", got, want)
+	}
+
+	analysistest.RunWithSuggestedFixes(t, testdata, analyzers[0],
+		"settings",
+		"settings/generated",
+	)
+
+	for _, settings := range []map[string]any{
+		{"strict-defaults": true},
+		{"union": "settings.Shape:strict-default=true"},
+		{"max-file-lines": "many"},
+		{"unions": []any{"settings.Shape"}},
+	} {
+		if _, err := gounion.New(settings); err == nil {
+			t.Errorf("New(%v): got nil error", settings)
+		}
+	}
+}
+
+func TestAnalyzerExcludeEmbedded(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("exclude-embedded", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("exclude-embedded", "false")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"embedded",
+	)
+}
+
+func TestAnalyzerErrorsAs(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("errors-as", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("errors-as", "false")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"errorsas",
+	)
+}
+
+func TestAnalyzerMarkerName(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("marker-name", "is{{.Interface}}"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("marker-name", "")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"naming",
+	)
+}
+
+func TestAnalyzerOnlyModuleUnions(t *testing.T) {
+	testdata := filepath.Join(analysistest.TestData(), "modules", "app")
+
+	if err := gounion.Analyzer.Flags.Set("only-module-unions", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("only-module-unions", "false")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"./...",
+	)
+}
+
+func TestAnalyzerStructural(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("structural", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("structural", "false")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"structural",
+	)
+}
+
+func TestAnalyzerDefaultBody(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	for name, value := range map[string]string{
+		"require-default": "true",
+		"default-body":    `return "", unhandled({{printf "%q" .Union}}, {{.Var}})`,
+	} {
+		if err := gounion.Analyzer.Flags.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer gounion.Analyzer.Flags.Set("require-default", "false")
+	defer gounion.Analyzer.Flags.Set("default-body", "")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"defaultbody",
+	)
+}
+
+func TestAnalyzerMatchInstantiations(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("match-instantiations", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("match-instantiations", "false")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"instantiations",
+	)
+}
+
+func TestAnalyzerMaxMembers(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("max-members", "3"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("max-members", "0")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"maxmembers",
+	)
+}
+
+func TestAnalyzerSharedMembers(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("shared-members", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("shared-members", "false")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"shared",
+	)
+}
+
+func TestAnalyzerIdenticalUnions(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	if err := gounion.Analyzer.Flags.Set("identical-unions", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("identical-unions", "false")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"identical",
+	)
+}
+
+func TestAnalyzerUnknownMembers(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	for name, value := range map[string]string{
+		"strict-default":  "true",
+		"unknown-members": "Unknown*",
+	} {
+		if err := gounion.Analyzer.Flags.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer gounion.Analyzer.Flags.Set("strict-default", "false")
+	defer gounion.Analyzer.Flags.Set("unknown-members", "")
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"unknownmember",
+	)
+}
+
+func TestAnalyzerTerminalDetector(t *testing.T) {
+	testdata := analysistest.TestData()
+
+	unregister := gounion.RegisterTerminalDetector(func(pass *analysis.Pass, body []ast.Stmt) bool {
+		stmt, ok := body[len(body)-1].(*ast.ExprStmt)
+		if !ok {
+			return false
+		}
+		call, ok := stmt.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		return ok && fn.Pkg().Path() == "terminal" && fn.Name() == "Alert"
+	})
+	defer unregister()
+
+	analysistest.RunWithSuggestedFixes(t, testdata, gounion.Analyzer,
+		"terminal",
+	)
+}
+
+func TestAnalyzerFixtures(t *testing.T) {
+	for name, value := range fixtures.Flags {
+		if err := gounion.Analyzer.Flags.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+		defer gounion.Analyzer.Flags.Set(name, gounion.Analyzer.Flags.Lookup(name).DefValue)
+	}
+
+	analysistest.RunWithSuggestedFixes(t, fixtures.Dir(t), gounion.Analyzer, fixtures.Packages...)
+}
@@ -0,0 +1,112 @@
+package gounion_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/YuitoSato/gounion/gounion"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+func BenchmarkAnalyzer(b *testing.B) {
+	sizes := []struct {
+		unions, members, switches int
+	}{
+		{unions: 10, members: 5, switches: 100},
+		{unions: 10, members: 50, switches: 100},
+		{unions: 100, members: 5, switches: 1000},
+		{unions: 100, members: 50, switches: 1000},
+	}
+
+	for _, size := range sizes {
+		name := fmt.Sprintf("unions=%d/members=%d/switches=%d", size.unions, size.members, size.switches)
+		b.Run(name, func(b *testing.B) {
+			pkgs := loadSyntheticPackages(b, size.unions, size.members, size.switches)
+			analyzers := []*analysis.Analyzer{gounion.Analyzer}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := checker.Analyze(analyzers, pkgs, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// loadSyntheticPackages writes a GOPATH-style project with a package
+// declaring the given number of unions and members, and a package
+// containing the given number of type switches over them, then loads it.
+func loadSyntheticPackages(b *testing.B, unions, members, switches int) []*packages.Package {
+	b.Helper()
+
+	dir := b.TempDir()
+	writeFile(b, filepath.Join(dir, "src", "unions", "unions.go"), generateUnions(unions, members))
+	writeFile(b, filepath.Join(dir, "src", "switches", "switches.go"), generateSwitches(unions, members, switches))
+
+	cfg := &packages.Config{
+		Mode: packages.LoadAllSyntax,
+		Dir:  dir,
+		Env:  append(os.Environ(), "GOPATH="+dir, "GO111MODULE=off", "GOWORK=off", "GOFLAGS="),
+	}
+	pkgs, err := packages.Load(cfg, "unions", "switches")
+	if err != nil {
+		b.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		b.Fatal("failed to load synthetic packages")
+	}
+	return pkgs
+}
+
+// generateUnions generates a package with unions U0..Un, each with members
+// U<i>M0..U<i>Mm implementing the marker on the pointer type.
+func generateUnions(unions, members int) string {
+	var sb strings.Builder
+	sb.WriteString("package unions\n")
+	for u := 0; u < unions; u++ {
+		fmt.Fprintf(&sb, "\ntype U%d interface {\n\tisU%d()\n}\n", u, u)
+		for m := 0; m < members; m++ {
+			fmt.Fprintf(&sb, "\ntype U%dM%d struct{}\n\nfunc (*U%dM%d) isU%d() {}\n", u, m, u, m, u)
+		}
+	}
+	return sb.String()
+}
+
+// generateSwitches generates a package with type switches spread evenly
+// over the unions. Every other switch omits its last member.
+func generateSwitches(unions, members, switches int) string {
+	var sb strings.Builder
+	sb.WriteString("package switches\n\nimport \"unions\"\n")
+	for s := 0; s < switches; s++ {
+		u := s % unions
+		fmt.Fprintf(&sb, "\nfunc S%d(v unions.U%d) int {\n\tswitch v.(type) {\n", s, u)
+		handled := members
+		if s%2 == 1 {
+			handled--
+		}
+		for m := 0; m < handled; m++ {
+			fmt.Fprintf(&sb, "\tcase *unions.U%dM%d:\n\t\treturn %d\n", u, m, m)
+		}
+		sb.WriteString("\t}\n\treturn -1\n}\n")
+	}
+	return sb.String()
+}
+
+func writeFile(b *testing.B, path, content string) {
+	b.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		b.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		b.Fatal(err)
+	}
+}
@@ -0,0 +1,289 @@
+package gounion
+
+import (
+	"bytes"
+	"go/build/constraint"
+	"go/token"
+	"go/types"
+	"strings"
+	"sync"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"golang.org/x/tools/go/analysis"
+)
+
+// unionInfo is the per-pass view of a union interface: its fact plus
+// member data precomputed once and shared by every switch on the union.
+type unionInfo struct {
+	fact      *UnionInterface
+	pkg       *types.Package    // package declaring the members
+	policy    policy            // options in effect for the union
+	index     map[memberKey]int // member -> position in fact.Members
+	qualified []string          // member names qualified with the union's package, for display
+	unknown   []bool            // whether each member matches -unknown-members, nil if none does
+
+	constraints []constraint.Expr     // build constraint of each member, nil if none is constrained
+	required    map[string][]presence // file constraint -> presence of the members under it
+}
+
+// newUnionInfo precomputes the canonical member set of a union.
+func newUnionInfo(fact *UnionInterface, unionPkg *types.Package, p policy) *unionInfo {
+	info := &unionInfo{
+		fact:      fact,
+		pkg:       unionPkg,
+		policy:    p,
+		index:     make(map[memberKey]int, len(fact.Members)),
+		qualified: make([]string, len(fact.Members)),
+	}
+	for i, member := range fact.Members {
+		info.index[parseMember(unionPkg, member)] = i
+		// Format with package name for external references
+		if unionPkg != nil {
+			info.qualified[i] = unionPkg.Name() + "." + member
+		} else {
+			info.qualified[i] = member
+		}
+	}
+	if fact.Constraints != nil {
+		info.constraints = make([]constraint.Expr, len(fact.Members))
+		info.required = make(map[string][]presence)
+		for i, c := range fact.Constraints {
+			if expr, err := constraint.Parse("//go:build " + c); c != "" && err == nil {
+				info.constraints[i] = expr
+			}
+		}
+	}
+	return info
+}
+
+// presence tells in which build configurations satisfying the constraint of
+// a file a member is declared.
+type presence uint8
+
+const (
+	presentAlways    presence = iota // in every configuration: checks in the file must handle it
+	presentSometimes                 // in some configurations: checks in the file need a default for it
+	presentNever                     // in none: checks in the file can't see it
+)
+
+// requiredIn returns the presence of each member under the file constraint
+// expr. It returns nil if every member is always present.
+func (u *unionInfo) requiredIn(expr constraint.Expr) []presence {
+	if u.constraints == nil {
+		return nil
+	}
+
+	key := ""
+	if expr != nil {
+		key = expr.String()
+	}
+	if required, ok := u.required[key]; ok {
+		return required
+	}
+
+	required := make([]presence, len(u.constraints))
+	for i, c := range u.constraints {
+		switch {
+		case implies(expr, c):
+			required[i] = presentAlways
+		case satisfiable(and(expr, c)):
+			required[i] = presentSometimes
+		default:
+			required[i] = presentNever
+		}
+	}
+	u.required[key] = required
+
+	return required
+}
+
+// unionCache memoizes union fact lookups for the duration of a pass,
+// so that many switches on the same union import its fact only once.
+// In lazy mode, membership is derived from type information instead of facts.
+type unionCache struct {
+	pass  *analysis.Pass
+	cfg   *config
+	infos map[*types.TypeName]*unionInfo
+	files *fileConstraintIndex
+
+	structural []*types.TypeName // candidate unions for matchStructural, computed on first use
+}
+
+// newUnionCache creates an empty cache bound to the given pass.
+func newUnionCache(pass *analysis.Pass, cfg *config) *unionCache {
+	return &unionCache{
+		pass:  pass,
+		cfg:   cfg,
+		infos: make(map[*types.TypeName]*unionInfo),
+		files: newFileConstraintIndex(pass),
+	}
+}
+
+// lookup returns the union info for the given interface type name,
+// or nil if it is not a union interface.
+func (c *unionCache) lookup(obj *types.TypeName) *unionInfo {
+	if info, ok := c.infos[obj]; ok {
+		return info
+	}
+
+	if c.cfg.OnlyModuleUnions && !inCurrentModule(c.pass, obj.Pkg()) {
+		c.infos[obj] = nil
+		return nil
+	}
+
+	if iface, ok := obj.Type().Underlying().(*types.Interface); ok && len(registry.EmbeddedUnions(iface)) > 1 {
+		info := c.intersection(iface, c.cfg.policyFor(obj))
+		c.infos[obj] = info
+		return info
+	}
+
+	var fact *UnionInterface
+	if c.cfg.LazyFacts {
+		fact = registry.UnionOf(obj, c.cfg.ExcludeEmbedded)
+	} else if imported := new(UnionInterface); c.pass.ImportObjectFact(obj, imported) {
+		fact = imported
+	}
+
+	var info *unionInfo
+	if fact != nil {
+		p := c.cfg.policyFor(obj)
+		unknown := c.cfg.unknownMembers(fact)
+		if unknown != nil {
+			// An open union accepts defaults handling the other members;
+			// guards are still checked, as without StrictDefault.
+			p.StrictDefault = false
+		}
+		info = newUnionInfo(fact, obj.Pkg(), p)
+		info.unknown = unknown
+	}
+	c.infos[obj] = info
+
+	return info
+}
+
+// intersection returns the union info for an interface embedding several
+// unions, whose members are the types belonging to all of them, or nil if
+// one of the embedded unions is not checked (see lookup).
+func (c *unionCache) intersection(iface *types.Interface, p policy) *unionInfo {
+	var infos []*unionInfo
+	for _, obj := range registry.EmbeddedUnions(iface) {
+		info := c.lookup(obj)
+		if info == nil {
+			return nil
+		}
+		infos = append(infos, info)
+	}
+
+	first := infos[0]
+	fact := &UnionInterface{MarkerMethod: first.fact.MarkerMethod}
+	var constraints []string
+	for i, member := range first.fact.Members {
+		constraint := first.constraintOf(i)
+		inAll := true
+		for _, other := range infos[1:] {
+			j, ok := other.index[parseMember(first.pkg, member)]
+			if !ok {
+				inAll = false
+				break
+			}
+			constraint = andConstraints(constraint, other.constraintOf(j))
+		}
+		if !inAll {
+			continue
+		}
+		fact.Members = append(fact.Members, member)
+		constraints = append(constraints, constraint)
+	}
+	for _, constraint := range constraints {
+		if constraint != "" {
+			fact.Constraints = constraints
+			break
+		}
+	}
+
+	return newUnionInfo(fact, first.pkg, p)
+}
+
+// constraintOf returns the build constraint of the i'th member, or "".
+func (u *unionInfo) constraintOf(i int) string {
+	if u.fact.Constraints == nil {
+		return ""
+	}
+	return u.fact.Constraints[i]
+}
+
+// matchStructural returns the union whose interface type is identical to
+// the anonymous interface typ, or nil. Candidates are the unions of the
+// current package and the unions with facts from its dependencies.
+func (c *unionCache) matchStructural(typ types.Type) *types.TypeName {
+	iface, ok := types.Unalias(typ).(*types.Interface)
+	if !ok {
+		return nil
+	}
+
+	if c.structural == nil {
+		c.structural = []*types.TypeName{}
+		for _, u := range FindUnions(c.pass.Pkg) {
+			c.structural = append(c.structural, u.Obj)
+		}
+		for _, f := range c.pass.AllObjectFacts() {
+			obj, ok := f.Object.(*types.TypeName)
+			if _, isUnion := f.Fact.(*UnionInterface); ok && isUnion && obj.Pkg() != c.pass.Pkg {
+				c.structural = append(c.structural, obj)
+			}
+		}
+	}
+
+	for _, obj := range c.structural {
+		if types.Identical(iface, obj.Type().Underlying()) {
+			return obj
+		}
+	}
+	return nil
+}
+
+// caseKey returns the key of a type handled by a case (or helper argument),
+// matched per instantiation if MatchInstantiations is set.
+func (c *unionCache) caseKey(typ types.Type) (memberKey, bool) {
+	return memberKeyOf(typ, c.cfg.MatchInstantiations)
+}
+
+// inCurrentModule reports whether pkg belongs to the module being analyzed.
+// Without module information (e.g. in GOPATH mode), every package does.
+func inCurrentModule(pass *analysis.Pass, pkg *types.Package) bool {
+	if pass.Module == nil || pass.Module.Path == "" || pkg == nil {
+		return true
+	}
+	path := pkg.Path()
+	return path == pass.Module.Path || strings.HasPrefix(path, pass.Module.Path+"/")
+}
+
+// requiredAt returns the presence of the members of union for a check at
+// pos, as computed by unionInfo.requiredIn for the build constraints of
+// pos's file.
+func (c *unionCache) requiredAt(union *unionInfo, pos token.Pos) []presence {
+	if union.constraints == nil {
+		return nil
+	}
+	return union.requiredIn(c.files.at(pos))
+}
+
+// bufferPool holds buffers reused for building diagnostic messages.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// joinNames joins names with ", " using a pooled buffer.
+func joinNames(names []string) string {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer bufferPool.Put(buf)
+	buf.Reset()
+
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(name)
+	}
+	return buf.String()
+}
@@ -0,0 +1,412 @@
+package gounion
+
+import (
+	"flag"
+	"fmt"
+	"go/types"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/YuitoSato/gounion/internal/settings"
+)
+
+// config holds the options controlling the analyzer. It is bound to the
+// analyzer's flags, through which every driver sets it, including the
+// golangci-lint plugin.
+type config struct {
+	// Common holds the options shared by the union and enum checks:
+	// StrictDefault, SkipTests, Include, Exclude and Terminators.
+	settings.Common
+
+	// LazyFacts skips fact export and derives union membership on demand,
+	// only for unions that are actually switched on. Unions from other
+	// packages are resolved from their type information rather than from
+	// facts, so this mode is intended for single-module CLI runs.
+	LazyFacts bool
+
+	// MaxFileLines skips switch checking in files with more lines than
+	// this (typically generated code). Such files are still scanned for
+	// union interfaces and members. Zero means no limit.
+	MaxFileLines int
+
+	// CheckReportUnhandled still checks switches whose default case ends
+	// with gounionrt.ReportUnhandled, instead of accepting it as an
+	// acknowledged escape hatch.
+	CheckReportUnhandled bool
+
+	// CheckUnhandledMember still checks switches whose default case
+	// returns gounionrt.ErrUnhandledMember, possibly wrapped, instead of
+	// accepting it as an acknowledged escape hatch.
+	CheckUnhandledMember bool
+
+	// RequireDefault reports exhaustive switches that have no default
+	// case, so that a defensive default guards against members added in
+	// other versions of the union's module.
+	RequireDefault bool
+
+	// DefaultBody is a text/template for the body of the default case
+	// inserted by the RequireDefault fix, e.g.
+	// `return nil, errdefs.Internal("unhandled %T", {{.Var}})`. It is
+	// executed with the union name as .Union and the switch variable (or
+	// the switched expression) as .Var. Empty means a panic.
+	DefaultBody string
+
+	// Summary additionally reports one diagnostic per package summarizing
+	// how many union switches are non-exhaustive and for which unions.
+	Summary bool
+
+	// LineDirectives adds the original source position to diagnostics in
+	// code generated with //line directives (e.g. templ or goyacc output),
+	// as related information at their position in the generated Go file,
+	// for drivers that report that position.
+	LineDirectives bool
+
+	// PerMember reports one diagnostic per missing member of a switch,
+	// each with a suggested fix adding that member's case, instead of one
+	// diagnostic listing all missing members.
+	PerMember bool
+
+	// GroupCases makes the PerMember fixes add missing members to the
+	// last case listing several types (e.g. case *Circle, *Rectangle:),
+	// if the switch has one, instead of adding a case per member.
+	GroupCases bool
+
+	// MarkerName is a text/template for the expected name of the marker
+	// method of each union declared in the analyzed packages, executed
+	// with the interface name as .Interface, e.g. "is{{.Interface}}".
+	// Empty disables the check.
+	MarkerName string
+
+	// ErrorsAs checks that if/else-if chains of errors.As calls on the
+	// same error cover every member of the error union their targets
+	// belong to. An error union is a union whose members all implement
+	// error.
+	ErrorsAs bool
+
+	// MaxListedMembers is the number of members listed in a diagnostic,
+	// e.g. of missing cases, before the rest is summarized as "+N more"
+	// and listed as related information instead. Zero means 5; a negative
+	// value lists all members.
+	MaxListedMembers int
+
+	// MaxMembers reports unions declared in the analyzed packages that
+	// have more members than this, whose switches are likely to have
+	// become unmanageable. Zero disables the check.
+	MaxMembers int
+
+	// SharedMembers reports types that are members of several unions of
+	// their package, which makes switches confusing and refactors risky.
+	SharedMembers bool
+
+	// IdenticalUnions reports unions with exactly the same members as
+	// another union of their package, which usually duplicate one
+	// abstraction.
+	IdenticalUnions bool
+
+	// Discriminators reports members of unions whose discriminator, the
+	// string identifying them in encoded values, set by a discriminator
+	// struct tag or else their name, is empty or shared with another
+	// member.
+	Discriminators bool
+
+	// DiscriminatorLock is a JSON file recording the discriminators of
+	// members, as written by gounion-gen -lock. Members whose
+	// discriminator changed since, locked members renamed or removed
+	// without being retired, and reuses of the discriminators of retired
+	// members are reported too, as they break decoding values encoded
+	// before. Setting it implies Discriminators.
+	DiscriminatorLock string
+
+	// ExcludeEmbedded excludes from the members of a union the types that
+	// only have its marker method through an embedded field (wrappers of
+	// a member), instead of declaring it themselves.
+	ExcludeEmbedded bool
+
+	// OnlyModuleUnions skips checks on unions declared outside the module
+	// being analyzed, such as unions of third-party dependencies. It has no
+	// effect when the driver does not provide module information.
+	OnlyModuleUnions bool
+
+	// Structural matches switches on anonymous interface types to the
+	// union whose interface is structurally identical, e.g. after an
+	// interface literal was used in place of the named union.
+	Structural bool
+
+	// MatchInstantiations matches cases on instantiated generic members
+	// per instantiation: case *Some[int] then covers only Some[int], not
+	// the generic member Some, which requires a default case.
+	MatchInstantiations bool
+
+	// Debug logs to stderr why each interface is or is not a union, and
+	// why type switches are not checked.
+	Debug bool
+
+	// DefiniteResult reports the cases of type switches on unions that
+	// neither return nor assign the variables returned after the switch,
+	// when other cases do, so that a forgotten return in one case does not
+	// silently return zero values.
+	DefiniteResult bool
+
+	// Explain is the fully qualified name of a type (e.g.
+	// "example.com/shape.Shape") for which the package declaring it records
+	// in its Result the steps deciding whether it is a union, as printed by
+	// gounion doctor.
+	Explain string
+
+	// UnknownMembers lists patterns of type names, in the syntax of
+	// path.Match (e.g. "Unknown*"), of the members that stand for values
+	// the program does not recognize, such as an UnknownEvent decoded
+	// from a newer producer. A union with such a member is open: the
+	// default case of its switches may handle the other members, even
+	// with StrictDefault, but not the unknown member, which must have a
+	// case of its own in every switch.
+	UnknownMembers []string
+
+	// IgnoreDate is the date, as YYYY-MM-DD, after which //gounion:ignore
+	// directives with an earlier until= date are expired. Empty means the
+	// current date, which makes results depend on the day they are
+	// computed.
+	IgnoreDate string
+
+	// Unions overrides the options above for specific unions, keyed by
+	// their fully qualified name (e.g. "example.com/shape.Shape").
+	Unions map[string]unionOptions
+
+	debugLog *debugLog // destination of the Debug log; nil means stderr
+}
+
+// unionOptions holds the options that can be overridden per union.
+// Nil fields inherit the global option.
+type unionOptions struct {
+	StrictDefault        *bool
+	CheckReportUnhandled *bool
+	CheckUnhandledMember *bool
+}
+
+// policy is the effective set of options for one union.
+type policy struct {
+	StrictDefault        bool
+	CheckReportUnhandled bool
+	CheckUnhandledMember bool
+	Terminators          settings.Funcs
+}
+
+// policyFor returns the options in effect for the given union interface.
+func (c *config) policyFor(obj *types.TypeName) policy {
+	p := policy{
+		StrictDefault:        c.StrictDefault,
+		CheckReportUnhandled: c.CheckReportUnhandled,
+		CheckUnhandledMember: c.CheckUnhandledMember,
+		Terminators:          c.Terminators,
+	}
+
+	override, ok := c.Unions[qualifiedName(obj)]
+	if !ok {
+		return p
+	}
+	if override.StrictDefault != nil {
+		p.StrictDefault = *override.StrictDefault
+	}
+	if override.CheckReportUnhandled != nil {
+		p.CheckReportUnhandled = *override.CheckReportUnhandled
+	}
+	if override.CheckUnhandledMember != nil {
+		p.CheckUnhandledMember = *override.CheckUnhandledMember
+	}
+	return p
+}
+
+// hasUnknownMember reports whether a member of a union matches one of the
+// UnknownMembers patterns.
+func (c *config) hasUnknownMember(fact *UnionInterface) bool {
+	return c.unknownMembers(fact) != nil
+}
+
+// unknownMembers returns, for each member of a union, whether it matches
+// one of the UnknownMembers patterns, or nil if none does.
+func (c *config) unknownMembers(fact *UnionInterface) []bool {
+	var unknown []bool
+	for i, member := range fact.Members {
+		name := strings.TrimPrefix(member, "*")
+		if i := strings.IndexByte(name, '['); i >= 0 {
+			name = name[:i]
+		}
+		for _, pattern := range c.UnknownMembers {
+			if ok, _ := path.Match(pattern, name); ok {
+				if unknown == nil {
+					unknown = make([]bool, len(fact.Members))
+				}
+				unknown[i] = true
+				break
+			}
+		}
+	}
+	return unknown
+}
+
+// panicDefaultBody is the default case body used when no DefaultBody is set.
+const panicDefaultBody = `panic("unhandled {{.Union}} member")`
+
+// defaultBodyData is the data the DefaultBody template is executed with.
+type defaultBodyData struct {
+	Union string // name of the union
+	Var   string // variable bound by the switch, or the switched expression
+}
+
+// defaultBodyTemplate parses the DefaultBody template.
+func (c *config) defaultBodyTemplate() (*template.Template, error) {
+	text := c.DefaultBody
+	if text == "" {
+		text = panicDefaultBody
+	}
+	tmpl, err := template.New("default-body").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default-body: %w", err)
+	}
+	return tmpl, nil
+}
+
+// qualifiedName returns the fully qualified name of a type, e.g. "example.com/shape.Shape".
+func qualifiedName(obj *types.TypeName) string {
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
+// registerFlags binds the options to fs, using the current values as defaults.
+func (c *config) registerFlags(fs *flag.FlagSet) {
+	c.Common.RegisterFlags(fs)
+	fs.BoolVar(&c.LazyFacts, "lazy-facts", c.LazyFacts,
+		"derive union membership on demand instead of exporting facts for every union")
+	fs.IntVar(&c.MaxFileLines, "max-file-lines", c.MaxFileLines,
+		"skip switch checking in files with more lines than this (0 means no limit)")
+	fs.BoolVar(&c.CheckReportUnhandled, "check-report-unhandled", c.CheckReportUnhandled,
+		"check switches whose default ends with gounionrt.ReportUnhandled instead of accepting them")
+	fs.BoolVar(&c.CheckUnhandledMember, "check-unhandled-member", c.CheckUnhandledMember,
+		"check switches whose default returns gounionrt.ErrUnhandledMember instead of accepting them")
+	fs.BoolVar(&c.RequireDefault, "require-default", c.RequireDefault,
+		"report exhaustive switches that lack a defensive default case")
+	fs.StringVar(&c.DefaultBody, "default-body", c.DefaultBody,
+		"text/template for the body of the default case added by the require-default fix, with .Union and .Var")
+	fs.BoolVar(&c.Summary, "summary", c.Summary,
+		"also report a per-package summary of non-exhaustive union switches")
+	fs.BoolVar(&c.LineDirectives, "line-directives", c.LineDirectives,
+		"add the original source positions of diagnostics in code with //line directives as related information")
+	fs.BoolVar(&c.PerMember, "per-member", c.PerMember,
+		"report one diagnostic, with a fix adding the case, per missing member")
+	fs.BoolVar(&c.GroupCases, "group-cases", c.GroupCases,
+		"make per-member fixes extend the switch's last grouped case instead of adding a case")
+	fs.StringVar(&c.MarkerName, "marker-name", c.MarkerName,
+		"text/template for the expected marker method name of unions, with .Interface, e.g. is{{.Interface}}")
+	fs.BoolVar(&c.ErrorsAs, "errors-as", c.ErrorsAs,
+		"check that errors.As if/else-if chains cover every member of error unions")
+	fs.BoolVar(&c.DefiniteResult, "definite-result", c.DefiniteResult,
+		"report cases of union type switches that neither return nor assign the result returned after the switch")
+	fs.IntVar(&c.MaxListedMembers, "max-listed-members", c.MaxListedMembers,
+		"members listed in a diagnostic before summarizing the rest as \"+N more\" (0 means 5, negative means all)")
+	fs.IntVar(&c.MaxMembers, "max-members", c.MaxMembers,
+		"report unions with more members than this (0 means no limit)")
+	fs.BoolVar(&c.SharedMembers, "shared-members", c.SharedMembers,
+		"report types that are members of several unions")
+	fs.BoolVar(&c.IdenticalUnions, "identical-unions", c.IdenticalUnions,
+		"report unions with the same members as another union of their package")
+	fs.BoolVar(&c.Discriminators, "discriminators", c.Discriminators,
+		"report members of unions with an empty or duplicate discriminator")
+	fs.StringVar(&c.DiscriminatorLock, "discriminator-lock", c.DiscriminatorLock,
+		"JSON file of locked member discriminators, as written by gounion-gen -lock; report changed, removed and reused ones (implies -discriminators)")
+	fs.BoolVar(&c.ExcludeEmbedded, "exclude-embedded", c.ExcludeEmbedded,
+		"exclude types having the marker method only through an embedded field from union members")
+	fs.BoolVar(&c.OnlyModuleUnions, "only-module-unions", c.OnlyModuleUnions,
+		"skip checks on unions declared outside the current module")
+	fs.BoolVar(&c.Structural, "structural", c.Structural,
+		"check switches on anonymous interfaces identical to a union")
+	fs.BoolVar(&c.MatchInstantiations, "match-instantiations", c.MatchInstantiations,
+		"match cases on generic members per instantiation instead of by generic type")
+	fs.BoolVar(&c.Debug, "debug", c.Debug,
+		"log why interfaces are or are not unions and why type switches are not checked")
+	fs.StringVar(&c.Explain, "explain", c.Explain,
+		"qualified name of a type, e.g. example.com/shape.Shape, to explain in the Result whether it is a union and why")
+	settings.ListVar(fs, &c.UnknownMembers, "unknown-members",
+		"comma-separated patterns of member type names, e.g. Unknown*, whose unions accept plain default cases for their other members even with -strict-default")
+	fs.StringVar(&c.IgnoreDate, "ignore-date", c.IgnoreDate,
+		"date, as YYYY-MM-DD, against which //gounion:ignore until= dates are compared (default: the current date)")
+	fs.Var((*unionOptionsFlag)(&c.Unions), "union",
+		"override options for one union, e.g. example.com/shape.Shape:strict-default=true (repeatable; empty clears the overrides)")
+}
+
+// unionOptionsFlag is a repeatable flag adding per-union overrides in the
+// form "<qualified name>:<option>=<value>,...". An empty value clears the
+// overrides.
+type unionOptionsFlag map[string]unionOptions
+
+// String implements flag.Value.
+func (f *unionOptionsFlag) String() string {
+	if f == nil {
+		return ""
+	}
+
+	names := make([]string, 0, len(*f))
+	for name := range *f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var entries []string
+	for _, name := range names {
+		opts := (*f)[name]
+		var settings []string
+		if opts.StrictDefault != nil {
+			settings = append(settings, "strict-default="+strconv.FormatBool(*opts.StrictDefault))
+		}
+		if opts.CheckReportUnhandled != nil {
+			settings = append(settings, "check-report-unhandled="+strconv.FormatBool(*opts.CheckReportUnhandled))
+		}
+		if opts.CheckUnhandledMember != nil {
+			settings = append(settings, "check-unhandled-member="+strconv.FormatBool(*opts.CheckUnhandledMember))
+		}
+		entries = append(entries, name+":"+strings.Join(settings, ","))
+	}
+	return strings.Join(entries, " ")
+}
+
+// Set implements flag.Value.
+func (f *unionOptionsFlag) Set(value string) error {
+	if value == "" {
+		*f = nil
+		return nil
+	}
+	name, settings, ok := strings.Cut(value, ":")
+	if !ok || name == "" {
+		return fmt.Errorf("invalid union override %q: want <qualified name>:<option>=<value>,...", value)
+	}
+
+	if *f == nil {
+		*f = make(map[string]unionOptions)
+	}
+	opts := (*f)[name]
+
+	for _, setting := range strings.Split(settings, ",") {
+		key, raw, _ := strings.Cut(setting, "=")
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s in union override %q: %w", key, value, err)
+		}
+		switch key {
+		case "strict-default":
+			opts.StrictDefault = &v
+		case "check-report-unhandled":
+			opts.CheckReportUnhandled = &v
+		case "check-unhandled-member":
+			opts.CheckUnhandledMember = &v
+		default:
+			return fmt.Errorf("unknown option %q in union override %q", key, value)
+		}
+	}
+
+	(*f)[name] = opts
+	return nil
+}
@@ -0,0 +1,280 @@
+package gounion
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/token"
+	"go/types"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// testFileTag is a pseudo build tag satisfied only by _test.go files, so
+// that members declared in test files are treated like members guarded by
+// a build constraint.
+const testFileTag = "_test"
+
+// maxImplicationTags bounds the number of distinct tags for which
+// implication between constraints is decided by enumeration.
+const maxImplicationTags = 12
+
+// knownOS and knownArch list the GOOS and GOARCH values recognized in file
+// name suffixes such as _linux.go or _windows_amd64.go.
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+		"hurd": true, "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true,
+		"netbsd": true, "openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+		"windows": true, "zos": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "arm": true, "arm64": true, "loong64": true,
+		"mips": true, "mipsle": true, "mips64": true, "mips64le": true, "ppc64": true,
+		"ppc64le": true, "riscv64": true, "s390x": true, "wasm": true,
+	}
+
+	// impliedOS maps the GOOS values setting the tag of another one too.
+	impliedOS = map[string]string{"android": "linux", "illumos": "solaris", "ios": "darwin"}
+	// unixOS lists the GOOS values satisfying the unix tag.
+	unixOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+		"hurd": true, "illumos": true, "ios": true, "linux": true, "netbsd": true,
+		"openbsd": true, "solaris": true,
+	}
+
+	// allOS and allArch list the GOOS and GOARCH values a configuration may
+	// have, "" standing for any value not known.
+	allOS   = append(slices.Sorted(maps.Keys(knownOS)), "")
+	allArch = append(slices.Sorted(maps.Keys(knownArch)), "")
+)
+
+// fileConstraintIndex computes and caches the build constraints of the
+// files of a package.
+type fileConstraintIndex struct {
+	pass  *analysis.Pass
+	files map[*token.File]*ast.File
+	cache map[*token.File]constraint.Expr
+}
+
+// newFileConstraintIndex creates an index over the files of the pass.
+func newFileConstraintIndex(pass *analysis.Pass) *fileConstraintIndex {
+	files := make(map[*token.File]*ast.File, len(pass.Files))
+	for _, f := range pass.Files {
+		files[pass.Fset.File(f.Pos())] = f
+	}
+	return &fileConstraintIndex{
+		pass:  pass,
+		files: files,
+		cache: make(map[*token.File]constraint.Expr),
+	}
+}
+
+// at returns the build constraint of the file containing pos: its
+// //go:build line, the GOOS/GOARCH implied by its name, and the test file
+// pseudo tag. It returns nil if the file is unconstrained.
+func (idx *fileConstraintIndex) at(pos token.Pos) constraint.Expr {
+	tf := idx.pass.Fset.File(pos)
+	if tf == nil {
+		return nil
+	}
+	if expr, ok := idx.cache[tf]; ok {
+		return expr
+	}
+
+	expr := filenameConstraint(tf.Name())
+	if f := idx.files[tf]; f != nil {
+		expr = and(expr, goBuildConstraint(f))
+	}
+	idx.cache[tf] = expr
+
+	return expr
+}
+
+// goBuildConstraint returns the //go:build constraint of a file, or nil.
+func goBuildConstraint(f *ast.File) constraint.Expr {
+	for _, group := range f.Comments {
+		if group.Pos() >= f.Package {
+			break
+		}
+		for _, c := range group.List {
+			if !constraint.IsGoBuild(c.Text) {
+				continue
+			}
+			if expr, err := constraint.Parse(c.Text); err == nil {
+				return expr
+			}
+		}
+	}
+	return nil
+}
+
+// filenameConstraint returns the constraint implied by a file name:
+// _test.go files and GOOS/GOARCH suffixes.
+func filenameConstraint(filename string) constraint.Expr {
+	name := strings.TrimSuffix(filepath.Base(filename), ".go")
+
+	var expr constraint.Expr
+	if strings.HasSuffix(name, "_test") {
+		name = strings.TrimSuffix(name, "_test")
+		expr = &constraint.TagExpr{Tag: testFileTag}
+	}
+
+	parts := strings.Split(name, "_")
+	if n := len(parts); n >= 3 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+		expr = and(expr, and(&constraint.TagExpr{Tag: parts[n-2]}, &constraint.TagExpr{Tag: parts[n-1]}))
+	} else if n >= 2 && (knownOS[parts[n-1]] || knownArch[parts[n-1]]) {
+		expr = and(expr, &constraint.TagExpr{Tag: parts[n-1]})
+	}
+
+	return expr
+}
+
+// and combines two possibly nil constraints.
+func and(x, y constraint.Expr) constraint.Expr {
+	switch {
+	case x == nil:
+		return y
+	case y == nil:
+		return x
+	}
+	return &constraint.AndExpr{X: x, Y: y}
+}
+
+// andConstraints combines two build constraint expressions as returned by
+// memberConstraint, either of which may be "".
+func andConstraints(x, y string) string {
+	switch {
+	case x == "" || x == y:
+		return y
+	case y == "":
+		return x
+	}
+	return "(" + x + ") && (" + y + ")"
+}
+
+// memberConstraints returns the build constraints of the given members of
+// pkg, parallel to members, or nil if none of them is constrained.
+func memberConstraints(idx *fileConstraintIndex, pkg *types.Package, members []string, markerMethod string) []string {
+	var constraints []string
+	for i, member := range members {
+		typeName, ok := pkg.Scope().Lookup(strings.TrimPrefix(member, "*")).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		if expr := memberConstraint(idx, typeName, markerMethod); expr != "" {
+			if constraints == nil {
+				constraints = make([]string, len(members))
+			}
+			constraints[i] = expr
+		}
+	}
+	return constraints
+}
+
+// memberConstraint returns the build constraint under which typeName is a
+// member: the constraints of the files declaring the type and its marker
+// method, combined. It returns "" if the member is unconstrained.
+func memberConstraint(idx *fileConstraintIndex, typeName *types.TypeName, markerMethod string) string {
+	expr := idx.at(typeName.Pos())
+	if obj, _, _ := types.LookupFieldOrMethod(typeName.Type(), true, typeName.Pkg(), markerMethod); obj != nil {
+		if tf := idx.pass.Fset.File(obj.Pos()); tf != idx.pass.Fset.File(typeName.Pos()) {
+			expr = and(expr, idx.at(obj.Pos()))
+		}
+	}
+	if expr == nil {
+		return ""
+	}
+	return expr.String()
+}
+
+// implies reports whether every build configuration satisfying x also
+// satisfies y. A nil constraint is always satisfied. Implication is decided
+// by enumerating the build configurations of the tags involved; for
+// constraints with too many tags, only identical constraints are considered
+// to imply each other.
+func implies(x, y constraint.Expr) bool {
+	if y == nil {
+		return true
+	}
+	if x != nil && x.String() == y.String() {
+		return true
+	}
+	return !satisfiable(and(x, &constraint.NotExpr{X: y}))
+}
+
+// satisfiable reports whether some build configuration satisfies expr. A
+// nil constraint is always satisfied. GOOS and GOARCH tags are not
+// independent: a configuration has a single GOOS, which also sets the tags
+// it implies (linux for android, darwin for ios, solaris for illumos, and
+// unix for Unix systems), and a single GOARCH. Constraints with too many
+// tags are considered satisfiable.
+func satisfiable(expr constraint.Expr) bool {
+	if expr == nil {
+		return true
+	}
+
+	tags := make(map[string]bool)
+	collectTags(expr, tags)
+	if len(tags) > maxImplicationTags {
+		return true
+	}
+
+	var names []string
+	goos, goarch := []string{""}, []string{""}
+	for tag := range tags {
+		switch {
+		case knownOS[tag] || tag == "unix":
+			goos = allOS
+		case knownArch[tag]:
+			goarch = allArch
+		default:
+			names = append(names, tag)
+		}
+	}
+
+	set := make(map[string]bool, len(names))
+	for _, os := range goos {
+		for _, arch := range goarch {
+			for bits := 0; bits < 1<<len(names); bits++ {
+				for i, name := range names {
+					set[name] = bits&(1<<i) != 0
+				}
+				ok := func(tag string) bool {
+					switch {
+					case knownOS[tag]:
+						return os != "" && (tag == os || impliedOS[os] == tag)
+					case tag == "unix":
+						return unixOS[os]
+					case knownArch[tag]:
+						return tag == arch
+					}
+					return set[tag]
+				}
+				if expr.Eval(ok) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// collectTags adds the tags referenced by expr to tags.
+func collectTags(expr constraint.Expr, tags map[string]bool) {
+	switch e := expr.(type) {
+	case *constraint.TagExpr:
+		tags[e.Tag] = true
+	case *constraint.NotExpr:
+		collectTags(e.X, tags)
+	case *constraint.AndExpr:
+		collectTags(e.X, tags)
+		collectTags(e.Y, tags)
+	case *constraint.OrExpr:
+		collectTags(e.X, tags)
+		collectTags(e.Y, tags)
+	}
+}
@@ -0,0 +1,50 @@
+package gounion
+
+import (
+	"go/build/constraint"
+	"testing"
+)
+
+func TestRequiredIn(t *testing.T) {
+	tests := []struct {
+		file, member string
+		want         presence
+	}{
+		{"", "linux", presentSometimes},
+		{"linux", "linux", presentAlways},
+		{"linux", "linux || darwin", presentAlways},
+		{"linux", "!windows", presentAlways},
+		{"linux", "unix", presentAlways},
+		{"android", "linux", presentAlways},
+		{"unix", "linux", presentSometimes},
+		{"windows", "linux", presentNever},
+		{"windows", "unix", presentNever},
+		{"linux", "android", presentSometimes},
+		{"amd64", "arm64", presentNever},
+		{"linux && amd64", "linux && !arm64", presentAlways},
+		{"gounion_a", "gounion_b", presentSometimes},
+		{"gounion_a", "!gounion_a", presentNever},
+	}
+	for _, tt := range tests {
+		var file constraint.Expr
+		if tt.file != "" {
+			file = mustParseConstraint(t, tt.file)
+		}
+		u := &unionInfo{
+			constraints: []constraint.Expr{mustParseConstraint(t, tt.member)},
+			required:    make(map[string][]presence),
+		}
+		if got := u.requiredIn(file)[0]; got != tt.want {
+			t.Errorf("presence of a %q member in a %q file = %d, want %d", tt.member, tt.file, got, tt.want)
+		}
+	}
+}
+
+func mustParseConstraint(t *testing.T, expr string) constraint.Expr {
+	t.Helper()
+	x, err := constraint.Parse("//go:build " + expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x
+}
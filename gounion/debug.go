@@ -0,0 +1,153 @@
+package gounion
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// debugLog writes the -debug log lines of concurrently analyzed packages.
+type debugLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// stderrLog is the default -debug log.
+var stderrLog = &debugLog{w: os.Stderr}
+
+// printf logs a line at pos.
+func (l *debugLog) printf(pass *analysis.Pass, pos token.Pos, format string, args ...any) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "gounion: debug: %s: ", pass.Fset.Position(pos))
+	fmt.Fprintf(&buf, format, args...)
+	buf.WriteByte('\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w.Write(buf.Bytes())
+}
+
+// debugf logs a line at pos in -debug mode.
+func (c *config) debugf(pass *analysis.Pass, pos token.Pos, format string, args ...any) {
+	if !c.Debug {
+		return
+	}
+	log := c.debugLog
+	if log == nil {
+		log = stderrLog
+	}
+	log.printf(pass, pos, format, args...)
+}
+
+// debugInterfaces logs, for each interface type declared in the package,
+// whether it is a union and why.
+func debugInterfaces(pass *analysis.Pass, inspect *inspector.Inspector, cfg *config) {
+	nodeFilter := []ast.Node{
+		(*ast.TypeSpec)(nil),
+	}
+
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		typeSpec := n.(*ast.TypeSpec)
+		if _, ok := typeSpec.Type.(*ast.InterfaceType); !ok {
+			return
+		}
+		typeName, ok := pass.TypesInfo.Defs[typeSpec.Name].(*types.TypeName)
+		if !ok {
+			return
+		}
+		iface, ok := typeName.Type().Underlying().(*types.Interface)
+		if !ok {
+			return
+		}
+
+		decision := unionDecision(iface)
+		if len(decision.markers) > 0 {
+			members := registry.Members(pass.Pkg, decision.markers, cfg.ExcludeEmbedded)
+			if len(members) == 0 {
+				decision.text += "; it has no members"
+			} else {
+				decision.text += "; members: " + strings.Join(members, ", ")
+			}
+		}
+		cfg.debugf(pass, typeSpec.Pos(), "interface %s %s", typeName.Name(), decision.text)
+	})
+}
+
+// decision explains whether an interface is a union.
+type decision struct {
+	markers []string // the marker methods, if the interface is a union
+	text    string
+}
+
+// unionDecision classifies iface as registry.UnionOf does,
+// explaining the outcome.
+func unionDecision(iface *types.Interface) decision {
+	if embedded := registry.EmbeddedUnions(iface); len(embedded) > 1 {
+		names := make([]string, len(embedded))
+		for i, obj := range embedded {
+			names[i] = obj.Name()
+		}
+		return decision{text: "is the intersection of unions " + strings.Join(names, ", ")}
+	}
+
+	var candidates, exported []string
+	for i := 0; i < iface.NumMethods(); i++ {
+		method := iface.Method(i)
+		sig := method.Type().(*types.Signature)
+		if sig.Params().Len() != 0 || sig.Results().Len() != 0 {
+			continue
+		}
+		if method.Exported() {
+			exported = append(exported, method.Name())
+		} else {
+			candidates = append(candidates, method.Name())
+		}
+	}
+
+	switch {
+	case len(candidates) == 0 && len(exported) > 0:
+		return decision{text: fmt.Sprintf("is not a union: marker method candidate %s is exported", strings.Join(exported, ", "))}
+	case len(candidates) == 0:
+		return decision{text: "is not a union: no unexported method without parameters and results"}
+	case len(candidates) > 1:
+		return decision{
+			markers: candidates,
+			text:    fmt.Sprintf("is a union with markers %s, which members must all implement", strings.Join(candidates, ", ")),
+		}
+	}
+	return decision{markers: candidates, text: "is a union with marker " + candidates[0]}
+}
+
+// switchSkipReason explains why a type switch on typ is not checked,
+// given that lookupSwitchUnion found no union for it.
+func switchSkipReason(pass *analysis.Pass, cfg *config, typ types.Type) string {
+	named := extractNamedInterface(typ)
+	if named == nil {
+		if cfg.Structural {
+			return "anonymous interface not identical to a union"
+		}
+		return "anonymous interface (see -structural)"
+	}
+
+	obj := named.Obj()
+	iface := named.Underlying().(*types.Interface)
+	switch decision := unionDecision(iface); {
+	case obj.Pkg() == nil:
+		return obj.Name() + " is predeclared"
+	case cfg.OnlyModuleUnions && !inCurrentModule(pass, obj.Pkg()):
+		return obj.Name() + " is declared outside the current module (-only-module-unions)"
+	case len(decision.markers) == 0:
+		return obj.Name() + " " + decision.text
+	}
+	return "no union fact for " + obj.Name() + " (was its package analyzed?)"
+}
@@ -0,0 +1,36 @@
+package gounion
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestDebug(t *testing.T) {
+	var buf bytes.Buffer
+	a := newAnalyzer(&config{Debug: true, debugLog: &debugLog{w: &buf}})
+	analysistest.Run(t, analysistest.TestData(), a, "debuglog")
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		// Strip the directory of the testdata file.
+		line = strings.Replace(line, filepath.Join(analysistest.TestData(), "src", "debuglog")+string(filepath.Separator), "", 1)
+		got = append(got, line)
+	}
+	want := []string{
+		"gounion: debug: debuglog.go:4:6: interface Shape is a union with marker isShape; members: *Circle",
+		"gounion: debug: debuglog.go:13:6: interface Exported is not a union: marker method candidate IsExported is exported",
+		"gounion: debug: debuglog.go:18:6: interface Ambiguous is a union with markers isA, isB, which members must all implement; it has no members",
+		"gounion: debug: debuglog.go:24:6: interface Empty is a union with marker isEmpty; it has no members",
+		"gounion: debug: debuglog.go:29:6: interface Stringer is not a union: no unexported method without parameters and results",
+		"gounion: debug: debuglog.go:34:2: type switch on Shape not checked: its default case handles the other members (see -strict-default)",
+		"gounion: debug: debuglog.go:39:2: type switch on Exported skipped: Exported is not a union: marker method candidate IsExported is exported",
+		"gounion: debug: debuglog.go:42:2: type switch on any skipped: anonymous interface (see -structural)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got log:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,266 @@
+package gounion
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// checkDefiniteResult reports the cases of a type switch on a union that
+// fall through to the return statement following the switch without a
+// result, as in
+//
+//	switch s := s.(type) {
+//	case *Circle:
+//		return math.Pi * s.R * s.R
+//	case *Square:
+//		math.Pow(s.W, 2) // the return is missing
+//	}
+//	return 0
+//
+// A case produces a result if it ends with a terminating statement
+// (return, panic, or a call to one of terminators), or assigns one of the
+// variables returned after the switch. Switches of which no case produces
+// a result only have side effects, and are not reported.
+func checkDefiniteResult(pass *analysis.Pass, stmt *ast.TypeSwitchStmt, unionName string, p policy) {
+	file := fileOf(pass, stmt.Pos())
+	if file == nil {
+		return
+	}
+	path, _ := astutil.PathEnclosingInterval(file, stmt.Pos(), stmt.End())
+	ret, ok := followingStmt(path).(*ast.ReturnStmt)
+	if !ok {
+		return
+	}
+	results := returnedVars(pass, path, ret)
+
+	var clauses []*ast.CaseClause
+	produces := false
+	for _, s := range stmt.Body.List {
+		cc := s.(*ast.CaseClause)
+		if terminates(pass, cc.Body, p) || assignsAny(pass, cc.Body, results) {
+			produces = true
+		} else {
+			clauses = append(clauses, cc)
+		}
+	}
+	if !produces {
+		return
+	}
+
+	for _, cc := range clauses {
+		label := "default case"
+		if cc.List != nil {
+			label = "case " + caseListString(cc)
+		}
+		if len(results) == 0 {
+			reportf(pass, cc.Case, categoryResult, "%s of type switch on %s does not return, falling through to the return after the switch", label, unionName)
+			continue
+		}
+		names := make([]string, len(results))
+		for i, v := range results {
+			names[i] = v.Name()
+		}
+		reportf(pass, cc.Case, categoryResult, "%s of type switch on %s neither returns nor assigns %s", label, unionName, strings.Join(names, " or "))
+	}
+}
+
+// followingStmt returns the statement following the innermost node of
+// path, a statement, in its block, or nil if it is the last one.
+func followingStmt(path []ast.Node) ast.Stmt {
+	if len(path) < 2 {
+		return nil
+	}
+	var list []ast.Stmt
+	switch parent := path[1].(type) {
+	case *ast.BlockStmt:
+		list = parent.List
+	case *ast.CaseClause:
+		list = parent.Body
+	case *ast.CommClause:
+		list = parent.Body
+	case *ast.LabeledStmt:
+		return followingStmt(path[1:])
+	}
+	for i, s := range list {
+		if s == path[0] && i+1 < len(list) {
+			return list[i+1]
+		}
+	}
+	return nil
+}
+
+// returnedVars returns the local variables returned by ret, in the
+// function enclosing path: those named by its results, or the named
+// results of the function for a bare return.
+func returnedVars(pass *analysis.Pass, path []ast.Node, ret *ast.ReturnStmt) []*types.Var {
+	var idents []*ast.Ident
+	if len(ret.Results) > 0 {
+		for _, result := range ret.Results {
+			if ident, ok := ast.Unparen(result).(*ast.Ident); ok {
+				idents = append(idents, ident)
+			}
+		}
+	} else {
+		var ftype *ast.FuncType
+		for _, n := range path {
+			if fn, ok := n.(*ast.FuncDecl); ok {
+				ftype = fn.Type
+				break
+			}
+			if fn, ok := n.(*ast.FuncLit); ok {
+				ftype = fn.Type
+				break
+			}
+		}
+		if ftype != nil && ftype.Results != nil {
+			for _, field := range ftype.Results.List {
+				idents = append(idents, field.Names...)
+			}
+		}
+	}
+
+	var vars []*types.Var
+	for _, ident := range idents {
+		v, ok := pass.TypesInfo.ObjectOf(ident).(*types.Var)
+		if ok && v.Name() != "_" && v.Parent() != pass.Pkg.Scope() {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+// terminates reports whether a list of statements ends with a terminating
+// statement, as defined by the Go specification, treating calls to the
+// policy's terminators and to gounionrt.MustHandle like panic, as well as
+// lists a registered TerminalDetector considers terminal. Loops and
+// switches containing a break statement are not terminating.
+func terminates(pass *analysis.Pass, stmts []ast.Stmt, p policy) bool {
+	if len(stmts) == 0 {
+		return false
+	}
+	if detectedTerminal(pass, stmts) {
+		return true
+	}
+	switch s := stmts[len(stmts)-1].(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BranchStmt:
+		return s.Tok == token.GOTO
+	case *ast.ExprStmt:
+		call, ok := ast.Unparen(s.X).(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		switch fn := typeutil.Callee(pass.TypesInfo, call).(type) {
+		case *types.Builtin:
+			return fn.Name() == "panic"
+		case *types.Func:
+			return p.Terminators.Contains(fn) || isRuntimeCall(pass, call, "MustHandle")
+		}
+		return false
+	case *ast.BlockStmt:
+		return terminates(pass, s.List, p)
+	case *ast.LabeledStmt:
+		return terminates(pass, []ast.Stmt{s.Stmt}, p)
+	case *ast.IfStmt:
+		return s.Else != nil && terminates(pass, s.Body.List, p) && terminates(pass, []ast.Stmt{s.Else}, p)
+	case *ast.ForStmt:
+		return s.Cond == nil && !hasBreak(s.Body)
+	case *ast.SwitchStmt:
+		return clausesTerminate(pass, s.Body, p)
+	case *ast.TypeSwitchStmt:
+		return clausesTerminate(pass, s.Body, p)
+	case *ast.SelectStmt:
+		for _, c := range s.Body.List {
+			if !terminates(pass, c.(*ast.CommClause).Body, p) {
+				return false
+			}
+		}
+		return !hasBreak(s.Body)
+	}
+	return false
+}
+
+// clausesTerminate reports whether a switch body has a default case and
+// all of its cases terminate, without breaking out of the switch.
+func clausesTerminate(pass *analysis.Pass, body *ast.BlockStmt, p policy) bool {
+	if defaultClause(body) == nil || hasBreak(body) {
+		return false
+	}
+	for _, c := range body.List {
+		cc := c.(*ast.CaseClause)
+		if !terminates(pass, cc.Body, p) && !endsWithFallthrough(cc) {
+			return false
+		}
+	}
+	return true
+}
+
+// endsWithFallthrough reports whether a case clause ends with a
+// fallthrough statement.
+func endsWithFallthrough(cc *ast.CaseClause) bool {
+	if len(cc.Body) == 0 {
+		return false
+	}
+	b, ok := cc.Body[len(cc.Body)-1].(*ast.BranchStmt)
+	return ok && b.Tok == token.FALLTHROUGH
+}
+
+// hasBreak reports whether n contains a break statement, outside of
+// function literals. Breaks out of nested statements are counted too,
+// which errs on the side of not terminating.
+func hasBreak(n ast.Node) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.BranchStmt:
+			if n.Tok == token.BREAK {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// assignsAny reports whether stmts assign one of vars, outside of
+// function literals.
+func assignsAny(pass *analysis.Pass, stmts []ast.Stmt, vars []*types.Var) bool {
+	if len(vars) == 0 {
+		return false
+	}
+	found := false
+	for _, s := range stmts {
+		ast.Inspect(s, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.AssignStmt:
+				if n.Tok == token.DEFINE {
+					break
+				}
+				for _, lhs := range n.Lhs {
+					ident, ok := ast.Unparen(lhs).(*ast.Ident)
+					if !ok {
+						continue
+					}
+					for _, v := range vars {
+						if pass.TypesInfo.Uses[ident] == v {
+							found = true
+						}
+					}
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
@@ -0,0 +1,50 @@
+package gounion
+
+import (
+	"go/types"
+	"os"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"golang.org/x/tools/go/analysis"
+)
+
+// discriminatorLock reads the DiscriminatorLock file, or returns nil if
+// none is configured.
+func (c *config) discriminatorLock() (registry.DiscriminatorLock, error) {
+	if c.DiscriminatorLock == "" {
+		return nil, nil
+	}
+	f, err := os.Open(c.DiscriminatorLock)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return registry.ReadDiscriminatorLock(f)
+}
+
+// checkDiscriminators reports the members of the unions declared in the
+// package whose discriminator, set by a registry.DiscriminatorTag or else
+// their name, is empty, is shared with another member, or, if lock is not
+// nil, changed since it was locked or was retired, any of which breaks
+// decoding encoded union values. Locked members no longer in a union are
+// reported at the union.
+func checkDiscriminators(pass *analysis.Pass, excludeEmbedded bool, lock registry.DiscriminatorLock) {
+	scope := pass.Pkg.Scope()
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || typeName.IsAlias() {
+			continue
+		}
+		fact := registry.UnionOf(typeName, excludeEmbedded)
+		if fact == nil {
+			continue
+		}
+		for _, err := range registry.CheckDiscriminators(typeName, fact.Members, lock) {
+			reportAbout(pass, analysis.Diagnostic{
+				Pos:      err.Member.Pos(),
+				Category: categoryDiscriminator,
+				Message:  err.Msg + " in union " + typeName.Name(),
+			}, typeName.Name(), pass.Pkg, nil)
+		}
+	}
+}
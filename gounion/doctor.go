@@ -0,0 +1,129 @@
+package gounion
+
+import (
+	"fmt"
+	"go/types"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"golang.org/x/tools/go/analysis"
+)
+
+// explainUnion returns the steps deciding whether typeName, declared in
+// the package of pass, is a union under cfg, one sentence each, as printed
+// by gounion doctor: its marker methods, or why it has none, its members,
+// the types rejected as members and why, and the options applying to it.
+func explainUnion(pass *analysis.Pass, cfg *config, typeName *types.TypeName) []string {
+	posn := pass.Fset.Position(typeName.Pos())
+	steps := []string{fmt.Sprintf("%s is declared at %s:%d:%d", typeName.Name(), filepath.Base(posn.Filename), posn.Line, posn.Column)}
+
+	if typeName.IsAlias() {
+		return append(steps, fmt.Sprintf("%s is an alias of %s: explain that type instead",
+			typeName.Name(), types.TypeString(typeName.Type(), types.RelativeTo(pass.Pkg))))
+	}
+	iface, ok := typeName.Type().Underlying().(*types.Interface)
+	if !ok {
+		return append(steps, fmt.Sprintf("%s is not a union: only interfaces are", typeName.Name()))
+	}
+	decision := unionDecision(iface)
+	if len(registry.EmbeddedUnions(iface)) > 1 {
+		return append(steps, typeName.Name()+" "+decision.text+": switches on it need a case for each of their common members")
+	}
+
+	for i := 0; i < iface.NumMethods(); i++ {
+		method := iface.Method(i)
+		sig := method.Type().(*types.Signature)
+		switch {
+		case sig.Params().Len() != 0 || sig.Results().Len() != 0:
+			steps = append(steps, fmt.Sprintf("method %s is not a marker: it has parameters or results", method.Name()))
+		case method.Exported():
+			steps = append(steps, fmt.Sprintf("method %s is not a marker: it is exported, so types of other packages can implement it", method.Name()))
+		default:
+			steps = append(steps, fmt.Sprintf("method %s is a marker: it is unexported and has no parameters or results", method.Name()))
+		}
+	}
+	if len(decision.markers) == 0 {
+		return append(steps, typeName.Name()+" "+decision.text)
+	}
+	if len(decision.markers) > 1 {
+		steps = append(steps, fmt.Sprintf("%s has several markers, %s: members must implement all of them",
+			typeName.Name(), strings.Join(decision.markers, ", ")))
+	}
+
+	members := registry.Members(pass.Pkg, decision.markers, cfg.ExcludeEmbedded)
+	steps = append(steps, explainCandidates(pass.Pkg, typeName, decision.markers, members)...)
+
+	fact := &UnionInterface{MarkerMethod: decision.markers[0], Members: members}
+	if cfg.hasUnknownMember(fact) {
+		steps = append(steps, fmt.Sprintf("a member matches -unknown-members %s: plain default cases may handle the other members even with -strict-default, but not the unknown ones",
+			strings.Join(cfg.UnknownMembers, ",")))
+	}
+	if override, ok := cfg.Unions[qualifiedName(typeName)]; ok {
+		steps = append(steps, "-union overrides its options: "+(&unionOptionsFlag{qualifiedName(typeName): override}).String())
+	}
+
+	if len(members) == 0 {
+		return append(steps, fmt.Sprintf("%s is a union without members: declare %s on the types of package %s that belong to it",
+			typeName.Name(), strings.Join(decision.markers, " and "), pass.Pkg.Name()))
+	}
+	return append(steps, fmt.Sprintf("%s is a union of %s: type switches on it are checked", typeName.Name(), strings.Join(members, ", ")))
+}
+
+// explainCandidates returns, for each type declared in pkg that has any of
+// the markers of union, whether it is one of its members and why.
+// Implementations in other packages are impossible, as the markers are
+// unexported.
+func explainCandidates(pkg *types.Package, union *types.TypeName, markers, members []string) []string {
+	var steps []string
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || typeName == union {
+			continue
+		}
+
+		var has, lacks []string
+		promoted := false
+		for _, marker := range markers {
+			if obj, index, _ := types.LookupFieldOrMethod(typeName.Type(), true, pkg, marker); isFunc(obj) {
+				has = append(has, marker)
+				promoted = promoted || len(index) > 1
+			} else {
+				lacks = append(lacks, marker)
+			}
+		}
+		if len(has) == 0 {
+			continue
+		}
+
+		switch member := slices.IndexFunc(members, func(m string) bool { return strings.TrimPrefix(m, "*") == name }); {
+		case typeName.IsAlias():
+			steps = append(steps, fmt.Sprintf("%s is not a member itself: it is an alias of %s",
+				name, types.TypeString(typeName.Type(), types.RelativeTo(pkg))))
+		case types.IsInterface(typeName.Type()):
+			steps = append(steps, fmt.Sprintf("%s is not a member: it is an interface", name))
+		case len(lacks) > 0:
+			steps = append(steps, fmt.Sprintf("%s is not a member: it lacks %s", name, strings.Join(lacks, ", ")))
+		case member < 0:
+			steps = append(steps, fmt.Sprintf("%s is not a member: it only has %s through an embedded field (-exclude-embedded)",
+				name, strings.Join(markers, ", ")))
+		case promoted:
+			steps = append(steps, fmt.Sprintf("%s is a member: it has %s through an embedded field (see -exclude-embedded)",
+				name, strings.Join(markers, ", ")))
+		case strings.HasPrefix(members[member], "*"):
+			steps = append(steps, fmt.Sprintf("%s is a member as %s: it has %s on its pointer receiver only",
+				name, members[member], strings.Join(markers, ", ")))
+		default:
+			steps = append(steps, fmt.Sprintf("%s is a member: it has %s", name, strings.Join(markers, ", ")))
+		}
+	}
+	return steps
+}
+
+// isFunc reports whether obj is a function or method.
+func isFunc(obj types.Object) bool {
+	_, ok := obj.(*types.Func)
+	return ok
+}
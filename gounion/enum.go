@@ -0,0 +1,102 @@
+package gounion
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Enum is an enum type declared in a package, together with its fact.
+//
+// Deprecated: Use registry.Enum.
+type Enum = registry.Enum
+
+// FindEnums returns the enums declared with //gounion:enum in files, the
+// syntax of pkg, sorted by name.
+//
+// Deprecated: Use registry.FindEnums.
+func FindEnums(pkg *types.Package, files []*ast.File) []Enum {
+	return registry.FindEnums(pkg, files)
+}
+
+// exportEnumFacts exports an EnumType fact for each enum declared in the
+// package, and reports //gounion:enum directives on other constants.
+func exportEnumFacts(pass *analysis.Pass) {
+	enums, invalid := registry.ParseEnums(pass.Pkg, pass.Files)
+	for _, directive := range invalid {
+		reportf(pass, directive.Pos(), categoryEnum, "%s directive on constants without a common named type", registry.EnumDirective)
+	}
+	for _, enum := range enums {
+		pass.ExportObjectFact(enum.Obj, enum.Fact)
+	}
+}
+
+// checkEnumSwitches checks that expression switches on enum types have a
+// case for every member constant, compared by value. As for type switches,
+// a default case disables the check unless it is a safety guard or
+// StrictDefault is in effect, and
+// with PerMember, each missing constant is reported with a fix adding it.
+func checkEnumSwitches(pass *analysis.Pass, inspect *inspector.Inspector, cfg *config) {
+	nodeFilter := []ast.Node{
+		(*ast.SwitchStmt)(nil),
+	}
+
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switchStmt := n.(*ast.SwitchStmt)
+		if switchStmt.Tag == nil {
+			return
+		}
+		named, ok := types.Unalias(pass.TypesInfo.TypeOf(switchStmt.Tag)).(*types.Named)
+		if !ok {
+			return
+		}
+		obj := named.Obj()
+		fact := new(EnumType)
+		if !pass.ImportObjectFact(obj, fact) {
+			return
+		}
+
+		if exceedsMaxFileLines(pass, switchStmt.Pos(), cfg.MaxFileLines) {
+			cfg.debugf(pass, switchStmt.Pos(), "switch skipped: file has more than %d lines (-max-file-lines)", cfg.MaxFileLines)
+			return
+		}
+		if defaultClause(switchStmt.Body) != nil && !defaultCaseRequiresCheck(pass, switchStmt.Body, cfg.policyFor(obj)) {
+			cfg.debugf(pass, switchStmt.Pos(), "switch on %s not checked: its default case handles the other members (see -strict-default)", obj.Name())
+			return
+		}
+
+		handled := make(map[string]bool)
+		for _, stmt := range switchStmt.Body.List {
+			for _, expr := range stmt.(*ast.CaseClause).List {
+				if tv, ok := pass.TypesInfo.Types[expr]; ok && tv.Value != nil {
+					handled[tv.Value.ExactString()] = true
+				}
+			}
+		}
+
+		var missing []string
+		for _, name := range fact.Constants {
+			c, ok := obj.Pkg().Scope().Lookup(name).(*types.Const)
+			if !ok || handled[c.Val().ExactString()] {
+				continue
+			}
+			// Skip aliases of a constant already reported
+			handled[c.Val().ExactString()] = true
+			missing = append(missing, obj.Pkg().Name()+"."+name)
+		}
+		if len(missing) == 0 {
+			return
+		}
+		if cfg.PerMember {
+			reportMissingCases(pass, switchStmt, switchStmt.Body, categoryEnum, "switch on "+obj.Name(), obj.Name(), obj.Pkg(), missing, cfg.GroupCases)
+			return
+		}
+		reportMembers(pass, cfg, switchStmt.Pos(), categoryEnum, obj.Name(), obj.Pkg(),
+			fmt.Sprintf("missing cases in switch on %s", obj.Name()),
+			missing)
+	})
+}
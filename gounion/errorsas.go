@@ -0,0 +1,185 @@
+package gounion
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// importsErrors reports whether pkg directly imports the errors package.
+func importsErrors(pkg *types.Package) bool {
+	for _, imp := range pkg.Imports() {
+		if imp.Path() == "errors" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkErrorsAsChains checks that if/else-if chains dispatching an error
+// with errors.As cover every member of the error union their targets
+// belong to. A chain needs at least two errors.As branches on the same
+// error, and no other conditions; a final else is treated like a default
+// case.
+func checkErrorsAsChains(pass *analysis.Pass, inspect *inspector.Inspector, cache *unionCache) {
+	nodeFilter := []ast.Node{
+		(*ast.IfStmt)(nil),
+	}
+
+	elseIfs := make(map[*ast.IfStmt]bool)
+
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		ifStmt := n.(*ast.IfStmt)
+		if elseIfs[ifStmt] {
+			return
+		}
+
+		var (
+			errExpr string
+			targets []types.Type
+			hasElse bool
+		)
+		for cur := ifStmt; cur != nil; {
+			target, err, ok := errorsAsCall(pass, cur.Cond)
+			if !ok || (errExpr != "" && err != errExpr) {
+				return
+			}
+			errExpr = err
+			targets = append(targets, target)
+
+			switch e := cur.Else.(type) {
+			case *ast.IfStmt:
+				elseIfs[e] = true
+				cur = e
+			case *ast.BlockStmt:
+				hasElse = true
+				cur = nil
+			default:
+				cur = nil
+			}
+		}
+		if len(targets) < 2 {
+			return
+		}
+
+		obj, union := errorUnionOf(cache, targets)
+		if union == nil || (hasElse && !union.policy.StrictDefault) {
+			return
+		}
+
+		var handled []memberKey
+		for _, target := range targets {
+			if key, ok := cache.caseKey(target); ok {
+				handled = append(handled, key)
+			}
+		}
+		missing, _ := findMissingTypes(union, handled, cache.requiredAt(union, ifStmt.Pos()))
+		if len(missing) > 0 {
+			reportMembers(pass, cache.cfg, ifStmt.Pos(), categoryErrorsAs, obj.Name(), union.pkg,
+				fmt.Sprintf("missing errors.As branches on %s for %s", errExpr, obj.Name()),
+				missing)
+		}
+	})
+}
+
+// errorsAsCall reports whether cond is a call errors.As(err, target),
+// returning the type pointed to by target and the err expression.
+func errorsAsCall(pass *analysis.Pass, cond ast.Expr) (types.Type, string, bool) {
+	call, ok := ast.Unparen(cond).(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 {
+		return nil, "", false
+	}
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "errors" || fn.Name() != "As" {
+		return nil, "", false
+	}
+	ptr, ok := types.Unalias(pass.TypesInfo.TypeOf(call.Args[1])).(*types.Pointer)
+	if !ok {
+		return nil, "", false
+	}
+	return ptr.Elem(), types.ExprString(call.Args[0]), true
+}
+
+// errorUnionOf returns the union, among those declared in the package of
+// the first target, that has every target as a member and only error
+// types as members. If several qualify, the first by name is used.
+func errorUnionOf(cache *unionCache, targets []types.Type) (*types.TypeName, *unionInfo) {
+	first, ok := cache.caseKey(targets[0])
+	if !ok {
+		return nil, nil
+	}
+	named := namedOf(targets[0])
+	if named == nil || named.Obj().Pkg() == nil {
+		return nil, nil
+	}
+	pkg := named.Obj().Pkg()
+
+	scope := pkg.Scope()
+	names := scope.Names()
+	sort.Strings(names)
+candidates:
+	for _, name := range names {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() {
+			continue
+		}
+		if _, ok := obj.Type().Underlying().(*types.Interface); !ok {
+			continue
+		}
+		union := cache.lookup(obj)
+		if union == nil {
+			continue
+		}
+		if _, ok := union.index[first]; !ok {
+			continue
+		}
+		for _, target := range targets[1:] {
+			key, ok := cache.caseKey(target)
+			if _, member := union.index[key]; !ok || !member {
+				continue candidates
+			}
+		}
+		if !membersAreErrors(union) {
+			continue
+		}
+		return obj, union
+	}
+	return nil, nil
+}
+
+// namedOf returns the named type of typ or of the type typ points to, or nil.
+func namedOf(typ types.Type) *types.Named {
+	typ = types.Unalias(typ)
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = types.Unalias(ptr.Elem())
+	}
+	named, _ := typ.(*types.Named)
+	return named
+}
+
+// membersAreErrors reports whether every member of union implements error.
+func membersAreErrors(union *unionInfo) bool {
+	if union.pkg == nil || len(union.fact.Members) == 0 {
+		return false
+	}
+	for _, member := range union.fact.Members {
+		key := parseMember(union.pkg, member)
+		obj, ok := union.pkg.Scope().Lookup(key.name).(*types.TypeName)
+		if !ok {
+			return false
+		}
+		var typ types.Type = obj.Type()
+		if key.pointer {
+			typ = types.NewPointer(typ)
+		}
+		if !types.Implements(typ, errorInterface()) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,59 +1,415 @@
 package gounion
 
 import (
+	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"github.com/YuitoSato/gounion/internal/settings"
 )
 
 // checkTypeSwitches checks for exhaustiveness in type switch statements
-// on union interfaces.
-func checkTypeSwitches(pass *analysis.Pass, inspect *inspector.Inspector) {
+// on union interfaces. defaultBody is the template for the default case
+// added by the RequireDefault fix. It returns the switches on unions.
+func checkTypeSwitches(pass *analysis.Pass, inspect *inspector.Inspector, cfg *config, cache *unionCache, defaultBody *template.Template) []CheckedSwitch {
 	nodeFilter := []ast.Node{
 		(*ast.TypeSwitchStmt)(nil),
 	}
 
+	var (
+		switches []CheckedSwitch
+		locals   *localInits // built on the first switch on a non-union interface
+	)
+
 	inspect.Preorder(nodeFilter, func(n ast.Node) {
 		switchStmt := n.(*ast.TypeSwitchStmt)
 
+		if exceedsMaxFileLines(pass, switchStmt.Pos(), cfg.MaxFileLines) {
+			cfg.debugf(pass, switchStmt.Pos(), "type switch skipped: file has more than %d lines (-max-file-lines)", cfg.MaxFileLines)
+			return
+		}
+
 		// Get the switch expression type
 		switchType := getSwitchType(pass, switchStmt)
 		if switchType == nil {
+			cfg.debugf(pass, switchStmt.Pos(), "type switch skipped: no type information for the switched expression")
 			return
 		}
 
-		// Check if it's a union interface
-		namedType := extractNamedInterface(switchType)
-		if namedType == nil {
-			return
+		// Check if it's a union interface, or a local variable that widened one
+		union, unionName := lookupSwitchUnion(switchType, cfg, cache)
+		if union == nil && types.IsInterface(switchType) {
+			if locals == nil {
+				locals = newLocalInits(pass, inspect)
+			}
+			if origin := locals.originType(extractTypeAssertExpr(switchStmt.Assign).X); origin != nil {
+				if union, unionName = lookupSwitchUnion(origin, cfg, cache); union != nil {
+					cfg.debugf(pass, switchStmt.Pos(), "type switch on %s checked as a switch on %s, the type of the expression it was initialized from",
+						types.TypeString(switchType, types.RelativeTo(pass.Pkg)), unionName)
+				}
+			}
 		}
-
-		var unionFact UnionInterface
-		if !pass.ImportObjectFact(namedType.Obj(), &unionFact) {
+		if union == nil {
+			if cfg.Debug {
+				cfg.debugf(pass, switchStmt.Pos(), "type switch on %s skipped: %s",
+					types.TypeString(switchType, types.RelativeTo(pass.Pkg)), switchSkipReason(pass, cfg, switchType))
+			}
 			return // Not a union interface
 		}
+		// Get handled types from case clauses
+		handledTypes := collectCaseTypes(pass, switchStmt, cache)
+		checked := CheckedSwitch{
+			Pos:     switchStmt.Pos(),
+			Union:   unionName,
+			Func:    funcName(fileOf(pass, switchStmt.Pos()), switchStmt.Pos()),
+			Handled: handledMembers(union, handledTypes),
+			Default: hasDefaultCase(switchStmt),
+		}
+		if union.pkg != nil {
+			checked.UnionPkg = union.pkg.Path()
+		}
+		switches = append(switches, checked)
 
-		// Check for default case - if present and not panic-only/error-returning, skip exhaustiveness check
-		if hasDefaultCase(switchStmt) && !defaultCaseOnlyPanics(switchStmt) && !defaultCaseOnlyReturnsError(pass, switchStmt) {
-			return
+		if cfg.DefiniteResult {
+			checkDefiniteResult(pass, switchStmt, unionName, union.policy)
 		}
 
-		// Get handled types from case clauses
-		handledTypes := collectCaseTypes(pass, switchStmt)
+		// Find missing types, among the members declared under this file's build constraints
+		missing, unavailable := findMissingTypes(union, handledTypes, cache.requiredAt(union, switchStmt.Pos()))
+		switches[len(switches)-1].Missing = missing
+		switches[len(switches)-1].MissingPos = memberPositions(union.pkg, missing)
+
+		// Check for default case - if present and not a safety guard, skip exhaustiveness check,
+		// except for the unknown members of open unions, which need cases of their own
+		if hasDefaultCase(switchStmt) && !defaultCaseRequiresCheck(pass, switchStmt.Body, union.policy) {
+			unknown := unknownMissing(union, missing)
+			if len(unknown) == 0 {
+				switches[len(switches)-1].DefaultAccepted = true
+				if defaultCaseReportsUnhandled(pass, switchStmt.Body) {
+					cfg.debugf(pass, switchStmt.Pos(), "type switch on %s not checked: its default case ends with gounionrt.ReportUnhandled (see -check-report-unhandled)", unionName)
+				} else if defaultCaseReturnsUnhandledMember(pass, switchStmt.Body) {
+					cfg.debugf(pass, switchStmt.Pos(), "type switch on %s not checked: its default case returns gounionrt.ErrUnhandledMember (see -check-unhandled-member)", unionName)
+				} else {
+					cfg.debugf(pass, switchStmt.Pos(), "type switch on %s not checked: its default case handles the other members (see -strict-default)", unionName)
+				}
+				return
+			}
+			// The default case handles the other members, not the unknown ones
+			missing, unavailable = unknown, nil
+			switches[len(switches)-1].Missing = missing
+			switches[len(switches)-1].MissingPos = memberPositions(union.pkg, missing)
+		}
 
-		// Find missing types
-		missing := findMissingTypes(unionFact.Members, handledTypes, namedType.Obj().Pkg())
+		if len(missing) == 0 {
+			switch {
+			case hasDefaultCase(switchStmt):
+			case len(unavailable) > 0:
+				reportMembers(pass, cfg, switchStmt.Pos(), categorySwitch, unionName, union.pkg,
+					fmt.Sprintf("type switch on %s has no default case for members unavailable under this file's build constraints", unionName),
+					unavailable)
+			case cfg.RequireDefault:
+				reportMissingDefault(pass, switchStmt, unionName, union.pkg, defaultBody)
+			}
+			return
+		}
 
-		if len(missing) > 0 {
-			pass.Reportf(switchStmt.Pos(),
-				"missing cases in type switch on %s: %s",
-				namedType.Obj().Name(),
-				strings.Join(missing, ", "))
+		if cfg.PerMember {
+			reportMissingCases(pass, switchStmt, switchStmt.Body, categorySwitch, "type switch on "+unionName, unionName, union.pkg, missing, cfg.GroupCases)
+			return
 		}
+		var fixes []analysis.SuggestedFix
+		if fix, ok := fillCasesFix(pass, switchStmt, unionName, union.pkg, missing); ok {
+			fixes = append(fixes, fix)
+		}
+		reportMembers(pass, cfg, switchStmt.Pos(), categorySwitch, unionName, union.pkg,
+			fmt.Sprintf("missing cases in type switch on %s", unionName),
+			missing, fixes...)
 	})
+
+	return switches
+}
+
+// lookupSwitchUnion returns the union info for the static type of a switch
+// tag and the union's name for diagnostics, or nil. Besides named unions, it
+// recognizes anonymous interfaces embedding several unions (named after
+// them, e.g. "Shape & Serializable") and, with -structural, anonymous
+// interfaces identical to a union.
+func lookupSwitchUnion(typ types.Type, cfg *config, cache *unionCache) (*unionInfo, string) {
+	if namedType := extractNamedInterface(typ); namedType != nil {
+		return cache.lookup(namedType.Obj()), namedType.Obj().Name()
+	}
+
+	iface, ok := types.Unalias(typ).(*types.Interface)
+	if !ok {
+		return nil, ""
+	}
+	if embedded := registry.EmbeddedUnions(iface); len(embedded) > 1 {
+		names := make([]string, len(embedded))
+		for i, obj := range embedded {
+			names[i] = obj.Name()
+		}
+		return cache.intersection(iface, cfg.policyFor(embedded[0])), strings.Join(names, " & ")
+	}
+	if cfg.Structural {
+		if obj := cache.matchStructural(typ); obj != nil {
+			return cache.lookup(obj), obj.Name()
+		}
+	}
+	return nil, ""
+}
+
+// reportMissingDefault reports an exhaustive switch on the union declared
+// in pkg without a default case, with a fix inserting a defensive default
+// whose body is produced by the defaultBody template. The fix is omitted if
+// the template fails.
+func reportMissingDefault(pass *analysis.Pass, stmt *ast.TypeSwitchStmt, unionName string, pkg *types.Package, defaultBody *template.Template) {
+	diag := analysis.Diagnostic{
+		Pos:      stmt.Pos(),
+		Category: categorySwitch,
+		Message:  fmt.Sprintf("exhaustive type switch on %s has no default case", unionName),
+	}
+
+	var body strings.Builder
+	data := defaultBodyData{Union: unionName, Var: switchVar(stmt)}
+	if err := defaultBody.Execute(&body, data); err == nil {
+		indent := indentOf(pass, stmt.Pos())
+		lines := strings.Split(strings.TrimSpace(body.String()), "\n")
+		newText := "default:\n" + indent + "\t" + strings.Join(lines, "\n"+indent+"\t") + "\n" + indent
+
+		diag.SuggestedFixes = []analysis.SuggestedFix{{
+			Message: "Add a defensive default case",
+			TextEdits: []analysis.TextEdit{{
+				Pos:     stmt.Body.Rbrace,
+				End:     stmt.Body.Rbrace,
+				NewText: []byte(newText),
+			}},
+		}}
+	}
+
+	reportAbout(pass, diag, unionName, pkg, nil)
+}
+
+// switchVar returns the name of the variable bound by a type switch, or
+// the source of the switched expression if there is none.
+func switchVar(stmt *ast.TypeSwitchStmt) string {
+	if assign, ok := stmt.Assign.(*ast.AssignStmt); ok && len(assign.Lhs) == 1 {
+		if ident, ok := assign.Lhs[0].(*ast.Ident); ok {
+			return ident.Name
+		}
+	}
+	if typeAssert := extractTypeAssertExpr(stmt.Assign); typeAssert != nil {
+		return types.ExprString(typeAssert.X)
+	}
+	return ""
+}
+
+// reportMissingCases reports each missing member of the switch stmt with
+// the given body separately, as a diagnostic of the given category on the
+// switch described by desc (e.g. "type switch on Shape"), with a fix
+// inserting an empty case for it before the default case, or at the end of
+// the switch. With groupCases, the fix instead adds the member to the last
+// case listing several types or values, if there is one. The members, of
+// the union or enum named union, are declared in pkg.
+func reportMissingCases(pass *analysis.Pass, stmt ast.Stmt, body *ast.BlockStmt, category, desc, union string, pkg *types.Package, missing []string, groupCases bool) {
+	pos := body.Rbrace
+	indent := indentOf(pass, stmt.Pos())
+	if def := defaultClause(body); def != nil {
+		pos = def.Pos()
+	}
+	var group *ast.CaseClause
+	if groupCases {
+		group = lastGroupedCase(body)
+	}
+	file := fileOf(pass, stmt.Pos())
+
+	for _, member := range missing {
+		diag := analysis.Diagnostic{
+			Pos:      stmt.Pos(),
+			Category: category,
+			Message:  fmt.Sprintf("missing case in %s: %s", desc, member),
+		}
+		if expr, ok := memberTypeExpr(pass, file, pkg, member); ok {
+			fix := analysis.SuggestedFix{
+				Message: "Add a case for " + member,
+				TextEdits: []analysis.TextEdit{{
+					Pos:     pos,
+					End:     pos,
+					NewText: []byte("case " + expr + ":\n" + indent),
+				}},
+			}
+			if group != nil {
+				end := group.List[len(group.List)-1].End()
+				fix = analysis.SuggestedFix{
+					Message: fmt.Sprintf("Add %s to the case of %s", member, caseListString(group)),
+					TextEdits: []analysis.TextEdit{{
+						Pos:     end,
+						End:     end,
+						NewText: []byte(", " + expr),
+					}},
+				}
+			}
+			diag.SuggestedFixes = []analysis.SuggestedFix{fix}
+		}
+		reportAbout(pass, diag, union, pkg, []string{member})
+	}
+}
+
+// lastGroupedCase returns the last case clause of a switch body listing
+// several types or values, or nil.
+func lastGroupedCase(body *ast.BlockStmt) *ast.CaseClause {
+	var group *ast.CaseClause
+	for _, clause := range body.List {
+		if cc, ok := clause.(*ast.CaseClause); ok && len(cc.List) > 1 {
+			group = cc
+		}
+	}
+	return group
+}
+
+// caseListString returns the types listed by a case clause, as written.
+func caseListString(cc *ast.CaseClause) string {
+	exprs := make([]string, len(cc.List))
+	for i, expr := range cc.List {
+		exprs[i] = types.ExprString(expr)
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// memberTypeExpr returns the type expression denoting a member, given by
+// its qualified name as returned by findMissingTypes, in file. For enums,
+// it returns the expression denoting a member constant. It reports
+// false if the union's package is not imported by name in file.
+func memberTypeExpr(pass *analysis.Pass, file *ast.File, unionPkg *types.Package, qualified string) (string, bool) {
+	member := strings.TrimPrefix(qualified, unionPkg.Name()+".")
+	star := ""
+	if name, ok := strings.CutPrefix(member, "*"); ok {
+		star, member = "*", name
+	}
+
+	if unionPkg == pass.Pkg {
+		return star + member, true
+	}
+	if file == nil {
+		return "", false
+	}
+	for _, imp := range file.Imports {
+		if path, err := strconv.Unquote(imp.Path.Value); err != nil || path != unionPkg.Path() {
+			continue
+		}
+		switch {
+		case imp.Name == nil:
+			return star + unionPkg.Name() + "." + member, true
+		case imp.Name.Name == ".":
+			return star + member, true
+		case imp.Name.Name != "_":
+			return star + imp.Name.Name + "." + member, true
+		}
+	}
+	return "", false
+}
+
+// fileOf returns the file of the pass containing pos, or nil.
+func fileOf(pass *analysis.Pass, pos token.Pos) *ast.File {
+	for _, f := range pass.Files {
+		if f.FileStart <= pos && pos <= f.FileEnd {
+			return f
+		}
+	}
+	return nil
+}
+
+// funcName returns the name of the function declared in file around pos,
+// with its receiver type for methods, e.g. "(*Renderer).Draw", or "" if pos
+// is outside of function declarations.
+func funcName(file *ast.File, pos token.Pos) string {
+	if file == nil {
+		return ""
+	}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || pos < fn.Pos() || pos >= fn.End() {
+			continue
+		}
+		if fn.Recv == nil || len(fn.Recv.List) == 0 {
+			return fn.Name.Name
+		}
+		recv := types.ExprString(fn.Recv.List[0].Type)
+		if strings.HasPrefix(recv, "*") {
+			recv = "(" + recv + ")"
+		}
+		return recv + "." + fn.Name.Name
+	}
+	return ""
+}
+
+// indentOf returns the indentation of the statement at pos, assuming
+// gofmt-formatted source indented with tabs.
+func indentOf(pass *analysis.Pass, pos token.Pos) string {
+	col := pass.Fset.Position(pos).Column
+	if col <= 1 {
+		return ""
+	}
+	return strings.Repeat("\t", col-1)
+}
+
+// reportSummary reports one diagnostic at the package clause summarizing
+// the union switches of the package.
+func reportSummary(pass *analysis.Pass, switches []CheckedSwitch) {
+	if len(switches) == 0 || len(pass.Files) == 0 {
+		return
+	}
+
+	// Unions of different packages may have the same name.
+	type union struct{ pkg, name string }
+	nonExhaustive := 0
+	affected := make(map[union]bool)
+	named := make(map[string]int) // number of affected unions by name
+	for _, s := range switches {
+		if len(s.Missing) > 0 && !s.DefaultAccepted {
+			nonExhaustive++
+			if u := (union{s.UnionPkg, s.Union}); !affected[u] {
+				affected[u] = true
+				named[s.Union]++
+			}
+		}
+	}
+
+	msg := fmt.Sprintf("%d of %d union switches non-exhaustive", nonExhaustive, len(switches))
+	if len(affected) > 0 {
+		names := make([]string, 0, len(affected))
+		for u := range affected {
+			if named[u.name] > 1 {
+				names = append(names, u.pkg+"."+u.name)
+			} else {
+				names = append(names, u.name)
+			}
+		}
+		sort.Strings(names)
+		msg += "; unions affected: " + joinNames(names)
+	}
+
+	reportf(pass, pass.Files[0].Package, categorySummary, "%s", msg)
+}
+
+// exceedsMaxFileLines reports whether the file containing pos has more
+// lines than maxLines. A non-positive maxLines means no limit.
+func exceedsMaxFileLines(pass *analysis.Pass, pos token.Pos, maxLines int) bool {
+	if maxLines <= 0 {
+		return false
+	}
+	file := pass.Fset.File(pos)
+	return file != nil && file.LineCount() > maxLines
 }
 
 // extractTypeAssertExpr extracts the TypeAssertExpr from a type switch's Assign statement.
@@ -94,7 +450,7 @@ func getSwitchType(pass *analysis.Pass, stmt *ast.TypeSwitchStmt) types.Type {
 
 // extractNamedInterface extracts the named interface type from a type.
 func extractNamedInterface(typ types.Type) *types.Named {
-	named, ok := typ.(*types.Named)
+	named, ok := types.Unalias(typ).(*types.Named)
 	if !ok {
 		return nil
 	}
@@ -110,7 +466,12 @@ func extractNamedInterface(typ types.Type) *types.Named {
 // getDefaultCaseClause returns the default case clause from a type switch statement,
 // or nil if there is no default case.
 func getDefaultCaseClause(stmt *ast.TypeSwitchStmt) *ast.CaseClause {
-	for _, clause := range stmt.Body.List {
+	return defaultClause(stmt.Body)
+}
+
+// defaultClause returns the default case clause of a switch body, or nil.
+func defaultClause(body *ast.BlockStmt) *ast.CaseClause {
+	for _, clause := range body.List {
 		caseClause, ok := clause.(*ast.CaseClause)
 		if !ok {
 			continue
@@ -127,19 +488,57 @@ func hasDefaultCase(stmt *ast.TypeSwitchStmt) bool {
 	return getDefaultCaseClause(stmt) != nil
 }
 
-// getDefaultCaseLastStmt returns the last statement in the default case body.
-// Returns nil if the default case has no statements.
-func getDefaultCaseLastStmt(stmt *ast.TypeSwitchStmt) ast.Stmt {
-	cc := getDefaultCaseClause(stmt)
+// getDefaultCaseLastStmt returns the last statement in the default case of
+// a switch body. Returns nil if the default case has no statements.
+func getDefaultCaseLastStmt(body *ast.BlockStmt) ast.Stmt {
+	cc := defaultClause(body)
 	if cc == nil || len(cc.Body) == 0 {
 		return nil
 	}
 	return cc.Body[len(cc.Body)-1]
 }
 
+// defaultCaseRequiresCheck reports whether a switch with a default case must
+// still be checked for exhaustiveness under the given policy.
+//
+// A default ending with a safety guard (panic, error return, or
+// gounionrt.MustHandle) is not intentional handling of unknown types, so
+// the switch is checked. A default ending in gounionrt.ReportUnhandled, or
+// returning gounionrt.ErrUnhandledMember, is an acknowledged escape hatch,
+// and is only checked if configured so. Any other default is only checked
+// in strict mode.
+func defaultCaseRequiresCheck(pass *analysis.Pass, body *ast.BlockStmt, p policy) bool {
+	if defaultCaseReportsUnhandled(pass, body) {
+		return p.CheckReportUnhandled
+	}
+	if defaultCaseReturnsUnhandledMember(pass, body) {
+		return p.CheckUnhandledMember
+	}
+	return p.StrictDefault || defaultCaseIsGuard(pass, body, p)
+}
+
+// defaultCaseIsGuard reports whether the default case ends with a safety
+// guard (panic, a call to one of the policy's terminators, error return,
+// gounionrt.MustHandle, or a body a registered TerminalDetector considers
+// terminal) rather than intentionally handling unknown types.
+func defaultCaseIsGuard(pass *analysis.Pass, body *ast.BlockStmt, p policy) bool {
+	return defaultCaseOnlyPanics(body) ||
+		defaultCaseCallsTerminator(pass, body, p.Terminators) ||
+		defaultCaseOnlyReturnsError(pass, body) ||
+		defaultCaseCallsRuntime(pass, body, "MustHandle") ||
+		defaultCaseDetectedTerminal(pass, body)
+}
+
+// defaultCaseDetectedTerminal checks if a registered TerminalDetector
+// considers the default case body terminal.
+func defaultCaseDetectedTerminal(pass *analysis.Pass, body *ast.BlockStmt) bool {
+	cc := defaultClause(body)
+	return cc != nil && detectedTerminal(pass, cc.Body)
+}
+
 // defaultCaseOnlyPanics checks if the default case body consists only of a panic call.
-func defaultCaseOnlyPanics(stmt *ast.TypeSwitchStmt) bool {
-	s := getDefaultCaseLastStmt(stmt)
+func defaultCaseOnlyPanics(body *ast.BlockStmt) bool {
+	s := getDefaultCaseLastStmt(body)
 	if s == nil {
 		return false
 	}
@@ -158,10 +557,25 @@ func defaultCaseOnlyPanics(stmt *ast.TypeSwitchStmt) bool {
 	return ident.Name == "panic"
 }
 
+// defaultCaseCallsTerminator checks if the default case ends with a call
+// to one of terminators, functions that never return such as log.Fatal.
+func defaultCaseCallsTerminator(pass *analysis.Pass, body *ast.BlockStmt, terminators settings.Funcs) bool {
+	exprStmt, ok := getDefaultCaseLastStmt(body).(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+	call, ok := ast.Unparen(exprStmt.X).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	return ok && terminators.Contains(fn)
+}
+
 // defaultCaseOnlyReturnsError checks if the default case body consists only of
 // a return statement that returns an error value (non-nil).
-func defaultCaseOnlyReturnsError(pass *analysis.Pass, stmt *ast.TypeSwitchStmt) bool {
-	s := getDefaultCaseLastStmt(stmt)
+func defaultCaseOnlyReturnsError(pass *analysis.Pass, body *ast.BlockStmt) bool {
+	s := getDefaultCaseLastStmt(body)
 	if s == nil {
 		return false
 	}
@@ -189,6 +603,88 @@ func defaultCaseOnlyReturnsError(pass *analysis.Pass, stmt *ast.TypeSwitchStmt)
 	return false
 }
 
+// defaultCaseCallsRuntime checks if the default case ends with a call to the
+// gounionrt function with the given name, either as a statement or in a
+// return statement.
+func defaultCaseCallsRuntime(pass *analysis.Pass, body *ast.BlockStmt, name string) bool {
+	switch s := getDefaultCaseLastStmt(body).(type) {
+	case *ast.ExprStmt:
+		return isRuntimeCall(pass, s.X, name)
+	case *ast.ReturnStmt:
+		for _, result := range s.Results {
+			if isRuntimeCall(pass, result, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// defaultCaseReportsUnhandled checks if the default case ends with a call to
+// gounionrt.ReportUnhandled, optionally followed by a return statement
+// (ReportUnhandled does not terminate the branch).
+func defaultCaseReportsUnhandled(pass *analysis.Pass, body *ast.BlockStmt) bool {
+	cc := defaultClause(body)
+	if cc == nil {
+		return false
+	}
+	stmts := cc.Body
+	if len(stmts) > 0 {
+		if _, ok := stmts[len(stmts)-1].(*ast.ReturnStmt); ok {
+			stmts = stmts[:len(stmts)-1]
+		}
+	}
+	if len(stmts) == 0 {
+		return false
+	}
+	exprStmt, ok := stmts[len(stmts)-1].(*ast.ExprStmt)
+	return ok && isRuntimeCall(pass, exprStmt.X, "ReportUnhandled")
+}
+
+// defaultCaseReturnsUnhandledMember checks if the default case ends with a
+// return statement whose results include gounionrt.ErrUnhandledMember,
+// either itself or wrapped by a call taking it as an argument, such as
+// fmt.Errorf("...: %w", gounionrt.ErrUnhandledMember) or errors.Join.
+func defaultCaseReturnsUnhandledMember(pass *analysis.Pass, body *ast.BlockStmt) bool {
+	ret, ok := getDefaultCaseLastStmt(body).(*ast.ReturnStmt)
+	if !ok {
+		return false
+	}
+	for _, result := range ret.Results {
+		if isRuntimeVar(pass, result, "ErrUnhandledMember") {
+			return true
+		}
+		if call, ok := ast.Unparen(result).(*ast.CallExpr); ok {
+			for _, arg := range call.Args {
+				if isRuntimeVar(pass, arg, "ErrUnhandledMember") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isRuntimeVar reports whether expr refers to the gounionrt variable with the given name.
+func isRuntimeVar(pass *analysis.Pass, expr ast.Expr, name string) bool {
+	sel, ok := ast.Unparen(expr).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	v, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Var)
+	return ok && v.Pkg() != nil && v.Pkg().Path() == runtimePkgPath && v.Name() == name
+}
+
+// isRuntimeCall reports whether expr is a call to the gounionrt function with the given name.
+func isRuntimeCall(pass *analysis.Pass, expr ast.Expr, name string) bool {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == runtimePkgPath && fn.Name() == name
+}
+
 // errorInterface returns the error interface type.
 func errorInterface() *types.Interface {
 	errType := types.Universe.Lookup("error").Type()
@@ -199,9 +695,10 @@ func errorInterface() *types.Interface {
 	return iface
 }
 
-// collectCaseTypes collects all types mentioned in case clauses.
-func collectCaseTypes(pass *analysis.Pass, stmt *ast.TypeSwitchStmt) []string {
-	var handled []string
+// collectCaseTypes collects the keys of the named types mentioned in case
+// clauses.
+func collectCaseTypes(pass *analysis.Pass, stmt *ast.TypeSwitchStmt, cache *unionCache) []memberKey {
+	var handled []memberKey
 
 	for _, clause := range stmt.Body.List {
 		caseClause, ok := clause.(*ast.CaseClause)
@@ -215,46 +712,86 @@ func collectCaseTypes(pass *analysis.Pass, stmt *ast.TypeSwitchStmt) []string {
 				continue
 			}
 
-			// Format the type as a string for comparison
-			typeStr := formatTypeForComparison(tv.Type)
-			handled = append(handled, typeStr)
+			if key, ok := cache.caseKey(tv.Type); ok {
+				handled = append(handled, key)
+			}
 		}
 	}
 
 	return handled
 }
 
-// formatTypeForComparison formats a type for comparison with union members.
-func formatTypeForComparison(typ types.Type) string {
-	switch t := typ.(type) {
-	case *types.Pointer:
-		if named, ok := t.Elem().(*types.Named); ok {
-			return "*" + named.Obj().Name()
+// memberPositions returns the declaration of each of the given members, by
+// name qualified with pkg as returned by findMissingTypes.
+func memberPositions(pkg *types.Package, members []string) []token.Pos {
+	positions := make([]token.Pos, len(members))
+	if pkg == nil {
+		return positions
+	}
+	for i, member := range members {
+		name := strings.TrimPrefix(strings.TrimPrefix(member, pkg.Name()+"."), "*")
+		if obj := pkg.Scope().Lookup(name); obj != nil {
+			positions[i] = obj.Pos()
 		}
-	case *types.Named:
-		return t.Obj().Name()
 	}
-	return types.TypeString(typ, nil)
+	return positions
 }
 
-// findMissingTypes finds union members that are not in the handled list.
-func findMissingTypes(members []string, handled []string, unionPkg *types.Package) []string {
-	handledSet := make(map[string]bool)
+// handledMembers returns the members of union among handled, qualified
+// with the union's package name, in the order of the union's members.
+func handledMembers(union *unionInfo, handled []memberKey) []string {
+	covered := make([]bool, len(union.fact.Members))
 	for _, h := range handled {
-		handledSet[h] = true
+		if i, ok := union.index[h]; ok {
+			covered[i] = true
+		}
 	}
+	var members []string
+	for i, ok := range covered {
+		if ok {
+			members = append(members, union.qualified[i])
+		}
+	}
+	return members
+}
 
-	var missing []string
-	for _, member := range members {
-		if !handledSet[member] {
-			// Format with package name for external references
-			if unionPkg != nil {
-				missing = append(missing, unionPkg.Name()+"."+member)
-			} else {
-				missing = append(missing, member)
-			}
+// unknownMissing returns the members of missing, as returned by
+// findMissingTypes, that match -unknown-members.
+func unknownMissing(union *unionInfo, missing []string) []string {
+	if union.unknown == nil {
+		return nil
+	}
+	var unknown []string
+	for i, ok := range union.unknown {
+		if ok && slices.Contains(missing, union.qualified[i]) {
+			unknown = append(unknown, union.qualified[i])
+		}
+	}
+	return unknown
+}
+
+// findMissingTypes finds union members that are not in the handled list,
+// split into required members and members unavailable under the build
+// constraints of the check site (see unionInfo.requiredIn; nil required means
+// all members are required). Members never present at the check site are
+// neither. The returned names are qualified with the union's package name.
+func findMissingTypes(union *unionInfo, handled []memberKey, required []presence) (missing, unavailable []string) {
+	covered := make([]bool, len(union.fact.Members))
+	for _, h := range handled {
+		if i, ok := union.index[h]; ok {
+			covered[i] = true
+		}
+	}
+
+	for i, ok := range covered {
+		switch {
+		case ok:
+		case required == nil || required[i] == presentAlways:
+			missing = append(missing, union.qualified[i])
+		case required[i] == presentSometimes:
+			unavailable = append(unavailable, union.qualified[i])
 		}
 	}
 
-	return missing
+	return missing, unavailable
 }
@@ -1,11 +1,13 @@
 package gounion
 
-// UnionInterface is a Fact indicating that an interface is a union type
-// with a private marker method and a set of implementing types.
-type UnionInterface struct {
-	MarkerMethod string   // e.g., "isNode"
-	Members      []string // e.g., ["*BadExpr", "*Ident", "*BasicLit"]
-}
+import "github.com/YuitoSato/gounion/gounion/registry"
 
-// AFact implements the analysis.Fact interface.
-func (*UnionInterface) AFact() {}
+// UnionInterface is the Fact of union interfaces.
+//
+// Deprecated: Use registry.UnionInterface.
+type UnionInterface = registry.UnionInterface
+
+// EnumType is the Fact of enum types.
+//
+// Deprecated: Use registry.EnumType.
+type EnumType = registry.EnumType
@@ -0,0 +1,130 @@
+package gounion
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// fillCasesFix returns the fix populating a skeleton type switch, with no
+// case clauses other than a default, with a case per missing member of the
+// union declared in pkg, before the default case. The switch binds a
+// variable, named after the switched identifier or else the union, so
+// that each case can use the typed member; the cases assign it to _ until
+// they do, as Go rejects a bound variable that no case uses. It returns
+// false if stmt has other cases or a member cannot be named in its file.
+func fillCasesFix(pass *analysis.Pass, stmt *ast.TypeSwitchStmt, unionName string, pkg *types.Package, missing []string) (analysis.SuggestedFix, bool) {
+	for _, s := range stmt.Body.List {
+		if s.(*ast.CaseClause).List != nil {
+			return analysis.SuggestedFix{}, false
+		}
+	}
+	file := fileOf(pass, stmt.Pos())
+	exprs := make([]string, len(missing))
+	for i, member := range missing {
+		expr, ok := memberTypeExpr(pass, file, pkg, member)
+		if !ok {
+			return analysis.SuggestedFix{}, false
+		}
+		exprs[i] = expr
+	}
+
+	var edits []analysis.TextEdit
+	name, bound := boundVar(stmt)
+	if !bound {
+		name = bindingName(pass, stmt, unionName)
+		edits = append(edits, analysis.TextEdit{
+			Pos:     stmt.Assign.Pos(),
+			End:     stmt.Assign.Pos(),
+			NewText: []byte(name + " := "),
+		})
+	}
+	use := !bound || !boundVarUsed(pass, stmt)
+
+	indent := indentOf(pass, stmt.Pos())
+	var b strings.Builder
+	pos := stmt.Body.Rbrace
+	if def := defaultClause(stmt.Body); def != nil {
+		pos = def.Pos()
+	} else if pass.Fset.Position(stmt.Body.Lbrace).Line == pass.Fset.Position(pos).Line {
+		b.WriteString("\n" + indent)
+	}
+	for _, expr := range exprs {
+		b.WriteString("case " + expr + ":\n" + indent)
+		if use {
+			b.WriteString("\t_ = " + name + "\n" + indent)
+		}
+	}
+	edits = append(edits, analysis.TextEdit{Pos: pos, End: pos, NewText: []byte(b.String())})
+
+	return analysis.SuggestedFix{
+		Message:   "Add a case for each member of " + unionName,
+		TextEdits: edits,
+	}, true
+}
+
+// boundVar returns the name of the variable bound by a type switch, if any.
+func boundVar(stmt *ast.TypeSwitchStmt) (string, bool) {
+	assign, ok := stmt.Assign.(*ast.AssignStmt)
+	if !ok || len(assign.Lhs) != 1 {
+		return "", false
+	}
+	ident, ok := assign.Lhs[0].(*ast.Ident)
+	return ident.Name, ok
+}
+
+// boundVarUsed reports whether a case of a type switch uses the variable
+// it binds.
+func boundVarUsed(pass *analysis.Pass, stmt *ast.TypeSwitchStmt) bool {
+	for _, s := range stmt.Body.List {
+		obj := pass.TypesInfo.Implicits[s]
+		if obj == nil {
+			continue
+		}
+		used := false
+		ast.Inspect(s, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok && pass.TypesInfo.Uses[ident] == obj {
+				used = true
+			}
+			return !used
+		})
+		if used {
+			return true
+		}
+	}
+	return false
+}
+
+// bindingName returns the name of the variable a type switch on a union
+// binds when it has none: the switched identifier, as in s := s.(type), or
+// else the union name with a lower case initial, as in shape :=
+// r.Shape().(type). A keyword gets a trailing underscore, as in type_, and
+// a name already in scope at the switch, such as an imported package
+// shape, which the variable would shadow in every case, a number.
+func bindingName(pass *analysis.Pass, stmt *ast.TypeSwitchStmt, unionName string) string {
+	if typeAssert := extractTypeAssertExpr(stmt.Assign); typeAssert != nil {
+		if ident, ok := ast.Unparen(typeAssert.X).(*ast.Ident); ok {
+			return ident.Name
+		}
+	}
+	r, size := utf8.DecodeRuneInString(unionName)
+	base := string(unicode.ToLower(r)) + unionName[size:]
+	if token.IsKeyword(base) {
+		base += "_"
+	}
+	scope := pass.Pkg.Scope().Innermost(stmt.Pos())
+	name := base
+	for i := 1; scope != nil; i++ {
+		if _, obj := scope.LookupParent(name, stmt.Pos()); obj == nil {
+			break
+		}
+		name = base + strconv.Itoa(i)
+	}
+	return name
+}
@@ -0,0 +1,88 @@
+package gounion
+
+import (
+	"go/token"
+	"go/types"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Finding is a diagnostic reported by the analyzer, with the union and
+// members it is about, as listed in Result.Findings. Tools embedding the
+// analyzer, such as review bots or migration scripts, use findings rather
+// than parse the messages of diagnostics.
+type Finding struct {
+	Pos      token.Pos
+	Category string
+	Message  string
+	Union    string   // name of the union or enum the finding is about, as in Message; empty if none
+	UnionPkg string   // path of the package declaring the union
+	Missing  []string // members missing, qualified as in Message, e.g. from the cases of a switch
+	Fixes    []analysis.SuggestedFix
+}
+
+// findingSubject is what the diagnostic with a message is about.
+type findingSubject struct {
+	message  string
+	union    string
+	unionPkg string
+	missing  []string
+}
+
+// findingRecorder records the diagnostics reported by a pass as findings.
+type findingRecorder struct {
+	findings []Finding
+	subjects map[token.Pos][]findingSubject
+}
+
+// subject returns what the diagnostic d is about.
+func (r *findingRecorder) subject(d analysis.Diagnostic) findingSubject {
+	for _, s := range r.subjects[d.Pos] {
+		if d.Message == s.message {
+			return s
+		}
+	}
+	return findingSubject{}
+}
+
+// findingRecorders holds the recorder of each running pass.
+var findingRecorders sync.Map // *analysis.Pass -> *findingRecorder
+
+// recordFindings wraps pass.Report to record every diagnostic reported
+// through it as a finding, until the recorder is removed from
+// findingRecorders.
+func recordFindings(pass *analysis.Pass) *findingRecorder {
+	rec := &findingRecorder{subjects: make(map[token.Pos][]findingSubject)}
+	findingRecorders.Store(pass, rec)
+
+	report := pass.Report
+	pass.Report = func(d analysis.Diagnostic) {
+		s := rec.subject(d)
+		rec.findings = append(rec.findings, Finding{
+			Pos:      d.Pos,
+			Category: d.Category,
+			Message:  d.Message,
+			Union:    s.union,
+			UnionPkg: s.unionPkg,
+			Missing:  s.missing,
+			Fixes:    d.SuggestedFixes,
+		})
+		report(d)
+	}
+	return rec
+}
+
+// reportAbout reports d as a diagnostic about the union named union,
+// declared in pkg, and its missing members, if any.
+func reportAbout(pass *analysis.Pass, d analysis.Diagnostic, union string, pkg *types.Package, missing []string) {
+	if rec, ok := findingRecorders.Load(pass); ok {
+		s := findingSubject{message: d.Message, union: union, missing: missing}
+		if pkg != nil {
+			s.unionPkg = pkg.Path()
+		}
+		r := rec.(*findingRecorder)
+		r.subjects[d.Pos] = append(r.subjects[d.Pos], s)
+	}
+	pass.Report(d)
+}
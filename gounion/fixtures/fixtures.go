@@ -0,0 +1,62 @@
+// Package fixtures provides realistic packages declaring and switching on
+// unions — generic members, aliases of unions and members, and members
+// wrapped by types embedding them — annotated with the diagnostics gounion
+// reports on them in the format of the analysistest package, and with the
+// sources expected after applying its suggested fixes as .golden files.
+//
+// Plugin consumers wrapping the analyzer, and contributors changing it,
+// can check their build against the fixtures:
+//
+//	for name, value := range fixtures.Flags {
+//		gounion.Analyzer.Flags.Set(name, value)
+//	}
+//	analysistest.RunWithSuggestedFixes(t, fixtures.Dir(t), gounion.Analyzer, fixtures.Packages...)
+package fixtures
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//go:embed testdata/src
+var files embed.FS
+
+// Packages are the import paths of the fixture packages.
+var Packages = []string{"aliases", "embedded", "generics"}
+
+// Flags are the analyzer flags the fixtures are annotated for: one
+// diagnostic, with a fix adding its case, per missing member, and members
+// only wrapping another excluded.
+var Flags = map[string]string{
+	"per-member":       "true",
+	"exclude-embedded": "true",
+}
+
+// Dir writes the fixtures to a temporary directory of t and returns it, for
+// use as the testdata directory of analysistest.Run and
+// RunWithSuggestedFixes: the package with import path p is in Dir/src/p.
+func Dir(t testing.TB) string {
+	t.Helper()
+	dir := t.TempDir()
+	err := fs.WalkDir(files, "testdata", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name[len("testdata"):]))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		data, err := files.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+	if err != nil {
+		t.Fatalf("writing fixtures: %v", err)
+	}
+	return dir
+}
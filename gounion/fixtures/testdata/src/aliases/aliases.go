@@ -0,0 +1,29 @@
+package aliases
+
+// Shape is a union whose members are also named by aliases.
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Rectangle \*Triangle\] \[\]\}`
+	isShape()
+}
+
+type Circle struct{ Radius float64 }
+type Rectangle struct{ Width, Height float64 }
+type Triangle struct{ Base, Height float64 }
+
+func (*Circle) isShape()    {}
+func (*Rectangle) isShape() {}
+func (*Triangle) isShape()  {}
+
+// Figure is an alias of the union, and Round of a member.
+type (
+	Figure = Shape
+	Round  = Circle
+)
+
+// area - NG: a case on the alias Round covers Circle
+func area(f Figure) float64 {
+	switch f := f.(type) { // want `missing case in type switch on Shape: aliases\.\*Rectangle` `missing case in type switch on Shape: aliases\.\*Triangle`
+	case *Round:
+		return 3.14 * f.Radius * f.Radius
+	}
+	return 0
+}
@@ -0,0 +1,39 @@
+package embedded
+
+// Event is a union whose members are wrapped by other types.
+type Event interface { // want Event:`&\{isEvent \[\*Click \*Replayed Key\] \[\]\}`
+	isEvent()
+}
+
+type Click struct{ X, Y int }
+
+func (*Click) isEvent() {}
+
+type Key struct{ Code rune }
+
+func (Key) isEvent() {}
+
+// Logged only has the marker method through its embedded member, so it is
+// excluded with -exclude-embedded.
+type Logged struct {
+	*Click
+	Message string
+}
+
+// Replayed declares the marker method itself, so it remains a member even
+// though it embeds one.
+type Replayed struct {
+	*Click
+}
+
+func (*Replayed) isEvent() {}
+
+// handle - NG: missing Replayed and Key
+func handle(e Event) string {
+	switch e.(type) { // want `missing case in type switch on Event: embedded\.\*Replayed` `missing case in type switch on Event: embedded\.Key`
+	case *Click:
+		return "click"
+	default:
+		panic("unreachable")
+	}
+}
@@ -0,0 +1,36 @@
+package generics
+
+// Option is a union with a generic member.
+type Option interface { // want Option:`&\{isOption \[\*None \*Some\] \[\]\}`
+	isOption()
+}
+
+type Some[T any] struct {
+	Value T
+}
+
+func (*Some[T]) isOption() {}
+
+type None struct{}
+
+func (*None) isOption() {}
+
+// Get - OK: a case on an instantiation covers the generic member
+func Get(o Option) int {
+	switch o := o.(type) {
+	case *Some[int]:
+		return o.Value
+	case *None:
+		return 0
+	}
+	return 0
+}
+
+// IsSome - NG: missing None
+func IsSome(o Option) bool {
+	switch o.(type) { // want `missing case in type switch on Option: generics\.\*None`
+	case *Some[string]:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package gounion
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// frozenDirective, in the doc comment of a union, lists the members the
+// union is frozen to, e.g. "//gounion:frozen Circle, Rectangle". Any other
+// type implementing the union is reported, until it is added to the list.
+const frozenDirective = "//gounion:frozen"
+
+// checkFrozenUnions reports members of unions declared in the package
+// that are missing from the union's //gounion:frozen directive, and unions
+// with a bare directive, with a fix listing the current members.
+func checkFrozenUnions(pass *analysis.Pass, inspect *inspector.Inspector, excludeEmbedded bool) {
+	if directiveLines(pass, frozenDirective) == nil {
+		return
+	}
+
+	nodeFilter := []ast.Node{
+		(*ast.GenDecl)(nil),
+	}
+
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		genDecl := n.(*ast.GenDecl)
+		for _, spec := range genDecl.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			doc := typeSpec.Doc
+			if doc == nil && len(genDecl.Specs) == 1 {
+				doc = genDecl.Doc
+			}
+			directive := findDirective(doc, frozenDirective)
+			if directive == nil {
+				continue
+			}
+
+			typeName, ok := pass.TypesInfo.Defs[typeSpec.Name].(*types.TypeName)
+			if !ok {
+				continue
+			}
+			var markers []string
+			iface, ok := typeName.Type().Underlying().(*types.Interface)
+			if ok && len(registry.EmbeddedUnions(iface)) <= 1 {
+				markers = registry.MarkerMethods(iface)
+			}
+			if len(markers) == 0 {
+				reportf(pass, typeSpec.Name.Pos(), categoryFrozen, "%s directive on %s, which is not a union", frozenDirective, typeName.Name())
+				continue
+			}
+			members := registry.Members(pass.Pkg, markers, excludeEmbedded)
+
+			frozen := parseFrozenMembers(directive.Text)
+			if len(frozen) == 0 {
+				names := make([]string, len(members))
+				for i, member := range members {
+					names[i] = strings.TrimPrefix(member, "*")
+				}
+				end := directive.Pos() + token.Pos(len(frozenDirective))
+				pass.Report(analysis.Diagnostic{
+					Pos:      typeSpec.Name.Pos(),
+					Category: categoryFrozen,
+					Message:  frozenDirective + " directive on " + typeName.Name() + " lists no members",
+					SuggestedFixes: []analysis.SuggestedFix{{
+						Message: "List the current members",
+						TextEdits: []analysis.TextEdit{{
+							Pos:     end,
+							End:     end,
+							NewText: []byte(" " + strings.Join(names, ", ")),
+						}},
+					}},
+				})
+				continue
+			}
+
+			for _, member := range members {
+				name := strings.TrimPrefix(member, "*")
+				if frozen[name] {
+					continue
+				}
+				pos := directive.Pos()
+				if obj := pass.Pkg.Scope().Lookup(name); obj != nil {
+					pos = obj.Pos()
+				}
+				reportf(pass, pos, categoryFrozen, "%s implements frozen union %s; add it to the %s directive to unfreeze it", name, typeName.Name(), frozenDirective)
+			}
+		}
+	})
+}
+
+// findDirective returns the comment of doc holding the given directive,
+// or nil.
+func findDirective(doc *ast.CommentGroup, directive string) *ast.Comment {
+	if doc == nil {
+		return nil
+	}
+	for _, c := range doc.List {
+		if c.Text == directive || strings.HasPrefix(c.Text, directive+" ") {
+			return c
+		}
+	}
+	return nil
+}
+
+// parseFrozenMembers returns the member names listed by a //gounion:frozen
+// directive, separated by commas or spaces. A trailing // comment is
+// ignored.
+func parseFrozenMembers(text string) map[string]bool {
+	list := strings.TrimPrefix(text, frozenDirective)
+	list, _, _ = strings.Cut(list, "//")
+	names := make(map[string]bool)
+	for _, name := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		names[strings.TrimPrefix(name, "*")] = true
+	}
+	return names
+}
@@ -0,0 +1,40 @@
+package gounion
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// genHeader starts the files written by gounion-gen, as the Header of
+// internal/gen.
+const genHeader = "// Code generated by gounion-gen."
+
+// generatedUses returns a use, at its package clause, for each union
+// referred to by a file of the package generated by gounion-gen, in order
+// of first reference. The file must be regenerated when a member is added.
+func generatedUses(pass *analysis.Pass, cache *unionCache) []UnionUse {
+	var uses []UnionUse
+	for _, f := range pass.Files {
+		if len(f.Comments) == 0 || !strings.HasPrefix(f.Comments[0].List[0].Text, genHeader) {
+			continue
+		}
+		seen := make(map[*types.TypeName]bool)
+		ast.Inspect(f, func(n ast.Node) bool {
+			id, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			obj, ok := pass.TypesInfo.Uses[id].(*types.TypeName)
+			if !ok || obj.Pkg() == nil || seen[obj] || cache.lookup(obj) == nil {
+				return true
+			}
+			seen[obj] = true
+			uses = append(uses, newUnionUse(pass, f.Package, "generated", obj))
+			return true
+		})
+	}
+	return uses
+}
@@ -0,0 +1,58 @@
+package gounion
+
+import (
+	"go/types"
+	"strings"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkIdenticalUnions reports unions declared in the package that have
+// exactly the same members as a union declared before them, with the
+// declaration of that union as related information. Such unions are
+// usually duplicated abstractions, to merge or to alias. Unions are only
+// compared within their package, as members implement the unexported
+// marker methods of their own package.
+func checkIdenticalUnions(pass *analysis.Pass, excludeEmbedded bool) {
+	scope := pass.Pkg.Scope()
+
+	type union struct {
+		obj     *types.TypeName
+		members string
+	}
+	var unions []union
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || typeName.IsAlias() {
+			continue
+		}
+		fact := registry.UnionOf(typeName, excludeEmbedded)
+		if fact == nil || len(fact.Members) == 0 {
+			continue
+		}
+		unions = append(unions, union{obj: typeName, members: strings.Join(fact.Members, ", ")})
+	}
+
+	first := make(map[string]*types.TypeName) // members -> first union declared with them
+	for _, u := range unions {
+		if v, ok := first[u.members]; !ok || u.obj.Pos() < v.Pos() {
+			first[u.members] = u.obj
+		}
+	}
+	for _, u := range unions {
+		v := first[u.members]
+		if v == u.obj {
+			continue
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:      u.obj.Pos(),
+			Category: categoryIdenticalUnions,
+			Message:  "union " + u.obj.Name() + " has the same members as " + v.Name() + " (" + u.members + "); consider merging them or declaring one as an alias of the other",
+			Related: []analysis.RelatedInformation{{
+				Pos:     v.Pos(),
+				Message: "union " + v.Name(),
+			}},
+		})
+	}
+}
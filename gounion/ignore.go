@@ -0,0 +1,276 @@
+package gounion
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// fileIgnoreDirective, before the package clause of a file, suppresses the
+// diagnostics reported in the file, e.g. "//gounion:file-ignore" for all of
+// them or "//gounion:file-ignore switch,literal" for those categories only.
+// Text after a further // is a comment, such as the reason.
+const fileIgnoreDirective = "//gounion:file-ignore"
+
+// fileIgnoreSet holds the categories suppressed per file; a nil set of
+// categories suppresses all of them.
+type fileIgnoreSet map[*token.File]map[string]bool
+
+// suppresses reports whether diagnostics of the given category are
+// suppressed in file.
+func (s fileIgnoreSet) suppresses(file *token.File, category string) bool {
+	ignored, ok := s[file]
+	return ok && (ignored == nil || ignored[category])
+}
+
+// fileIgnores returns the //gounion:file-ignore directives of the package,
+// or nil if there are none. Misplaced directives and unknown categories are
+// reported.
+func fileIgnores(pass *analysis.Pass) fileIgnoreSet {
+	var set fileIgnoreSet
+	for _, f := range pass.Files {
+		for _, group := range f.Comments {
+			c := findDirective(group, fileIgnoreDirective)
+			if c == nil {
+				continue
+			}
+			if c.Pos() > f.Package {
+				reportf(pass, c.Pos(), categoryIgnore, "%s directive must precede the package clause", fileIgnoreDirective)
+				continue
+			}
+
+			list := strings.TrimPrefix(c.Text, fileIgnoreDirective)
+			list, _, _ = strings.Cut(list, "//")
+			var ignored map[string]bool
+			if names := strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }); len(names) > 0 {
+				ignored = make(map[string]bool)
+				for _, category := range names {
+					if !slices.Contains(categories, category) {
+						reportf(pass, c.Pos(), categoryIgnore, "unknown category %q in %s directive (want one of %s)",
+							category, fileIgnoreDirective, strings.Join(categories, ", "))
+						continue
+					}
+					ignored[category] = true
+				}
+			}
+
+			if set == nil {
+				set = make(fileIgnoreSet)
+			}
+			tf := pass.Fset.File(f.Pos())
+			if prev, ok := set[tf]; ok {
+				// Several directives add up; any bare one suppresses all.
+				if prev == nil || ignored == nil {
+					ignored = nil
+				} else {
+					for category := range prev {
+						ignored[category] = true
+					}
+				}
+			}
+			set[tf] = ignored
+		}
+	}
+	return set
+}
+
+// Staticcheck's suppression directives, honored when one of their checks
+// matches "gounion", so that code following staticcheck conventions needs
+// no other directive: "//lint:ignore gounion reason" suppresses the
+// diagnostics on its line and the next one, and "//lint:file-ignore gounion
+// reason" those in its file. As in staticcheck, the checks are a
+// comma-separated list of glob patterns (e.g. "SA4006,goun*"), and
+// directives without a reason are malformed and not honored.
+const (
+	lintIgnoreDirective     = "//lint:ignore"
+	lintFileIgnoreDirective = "//lint:file-ignore"
+)
+
+// lineIgnoreSet holds the suppressed lines per file.
+type lineIgnoreSet map[*token.File]map[int]bool
+
+// suppresses reports whether diagnostics on the line of pos are
+// suppressed.
+func (s lineIgnoreSet) suppresses(fset *token.FileSet, pos token.Pos) bool {
+	file := fset.File(pos)
+	return file != nil && s[file][file.Line(pos)]
+}
+
+// lintIgnores adds the files suppressed by //lint:file-ignore directives to
+// files and returns it, with the lines suppressed by //lint:ignore
+// directives, or nil if there are none: the line of the directive and, if
+// it is on a line of its own, the next one.
+func lintIgnores(pass *analysis.Pass, files fileIgnoreSet) (fileIgnoreSet, lineIgnoreSet) {
+	var lines lineIgnoreSet
+	for _, f := range pass.Files {
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				if !strings.HasPrefix(c.Text, "//lint:") {
+					continue
+				}
+				fields := strings.Fields(c.Text)
+				if len(fields) < 3 || fields[0] != lintIgnoreDirective && fields[0] != lintFileIgnoreDirective || !matchesCheck(fields[1]) {
+					continue
+				}
+
+				tf := pass.Fset.File(c.Pos())
+				if fields[0] == lintFileIgnoreDirective {
+					if files == nil {
+						files = make(fileIgnoreSet)
+					}
+					files[tf] = nil
+					continue
+				}
+				if lines == nil {
+					lines = make(lineIgnoreSet)
+				}
+				if lines[tf] == nil {
+					lines[tf] = make(map[int]bool)
+				}
+				// As in staticcheck, a directive after code on its line only
+				// applies to that line.
+				line := tf.Line(c.Pos())
+				lines[tf][line] = true
+				if !followsCode(tf, f, c.Pos()) {
+					lines[tf][line+1] = true
+				}
+			}
+		}
+	}
+	return files, lines
+}
+
+// followsCode reports whether the comment at pos of f, in tf, follows code
+// on its line.
+func followsCode(tf *token.File, f *ast.File, pos token.Pos) bool {
+	line := tf.Line(pos)
+	found := false
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n.(type) {
+		case nil, *ast.CommentGroup:
+			return false
+		}
+		if found || n.Pos() >= pos || tf.Line(n.End()) < line {
+			return false
+		}
+		if tf.Line(n.Pos()) == line || n.End() <= pos && tf.Line(n.End()) == line {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// matchesCheck reports whether one of the comma-separated check patterns
+// of a //lint: directive matches gounion.
+func matchesCheck(checks string) bool {
+	for _, pattern := range strings.Split(checks, ",") {
+		if ok, _ := path.Match(pattern, "gounion"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreDirective suppresses the diagnostics on its line and the next one.
+// It may be given an expiration date and a reason, running to the end of
+// the line, as in "//gounion:ignore until=2025-12-31 reason=migrating to
+// Shape": once the date has passed, the directive is reported as expired
+// and no longer suppresses anything, so that temporary exceptions do not
+// become permanent. Dates are compared to the current date, or to the
+// IgnoreDate option, so that results can be reproduced. Text after a
+// further " //" is a comment.
+const ignoreDirective = "//gounion:ignore"
+
+// ignoreDateLayout is the layout of the until= date of an ignoreDirective.
+const ignoreDateLayout = "2006-01-02"
+
+// ignoreDate returns the date, in ignoreDateLayout, against which the
+// until= dates of //gounion:ignore directives are compared: the
+// IgnoreDate option, or else the current date.
+func (c *config) ignoreDate() (string, error) {
+	if c.IgnoreDate == "" {
+		return time.Now().Format(ignoreDateLayout), nil
+	}
+	date, err := time.Parse(ignoreDateLayout, c.IgnoreDate)
+	if err != nil {
+		return "", fmt.Errorf("invalid ignore-date %q (want YYYY-MM-DD)", c.IgnoreDate)
+	}
+	return date.Format(ignoreDateLayout), nil
+}
+
+// gounionIgnores adds the lines suppressed by the //gounion:ignore
+// directives of the package unexpired on today to lines and returns it.
+// Expired and malformed directives are reported.
+func gounionIgnores(pass *analysis.Pass, lines lineIgnoreSet, today string) lineIgnoreSet {
+	for _, f := range pass.Files {
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				if c.Text != ignoreDirective && !strings.HasPrefix(c.Text, ignoreDirective+" ") {
+					continue
+				}
+				until, reason, ok := parseIgnoreDirective(pass, c.Pos(), strings.TrimPrefix(c.Text, ignoreDirective))
+				if !ok {
+					continue
+				}
+				if until != "" && until < today {
+					msg := ignoreDirective + " directive expired on " + until
+					if reason != "" {
+						msg += ": " + reason
+					}
+					pass.Report(analysis.Diagnostic{Pos: c.Pos(), Category: categoryIgnore, Message: msg})
+					continue
+				}
+
+				tf := pass.Fset.File(c.Pos())
+				if lines == nil {
+					lines = make(lineIgnoreSet)
+				}
+				if lines[tf] == nil {
+					lines[tf] = make(map[int]bool)
+				}
+				line := tf.Line(c.Pos())
+				lines[tf][line] = true
+				lines[tf][line+1] = true
+			}
+		}
+	}
+	return lines
+}
+
+// parseIgnoreDirective parses the options of a //gounion:ignore directive
+// at pos, returning its until= date, in ignoreDateLayout, and its reason.
+// It reports unknown options and invalid dates, and returns false for
+// them.
+func parseIgnoreDirective(pass *analysis.Pass, pos token.Pos, options string) (until, reason string, ok bool) {
+	options, _, _ = strings.Cut(options, " //")
+	options = strings.TrimSpace(options)
+	for options != "" {
+		var option string
+		option, options, _ = strings.Cut(options, " ")
+		options = strings.TrimSpace(options)
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "until":
+			date, err := time.Parse(ignoreDateLayout, value)
+			if err != nil {
+				reportf(pass, pos, categoryIgnore, "invalid date %q in %s directive (want YYYY-MM-DD)", value, ignoreDirective)
+				return "", "", false
+			}
+			until = date.Format(ignoreDateLayout)
+		case "reason":
+			reason = strings.TrimSpace(value + " " + options)
+			options = ""
+		default:
+			reportf(pass, pos, categoryIgnore, "unknown option %q in %s directive (want until= or reason=)", option, ignoreDirective)
+			return "", "", false
+		}
+	}
+	return until, reason, true
+}
@@ -0,0 +1,142 @@
+package gounion
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// allMembersDirective marks a composite literal of union values (e.g. test
+// fixtures or a registry) that must contain every member of the union.
+const allMembersDirective = "//gounion:all-members"
+
+// directiveLines returns, per file, the lines holding the given directive.
+func directiveLines(pass *analysis.Pass, directive string) map[*token.File]map[int]bool {
+	var lines map[*token.File]map[int]bool
+	for _, f := range pass.Files {
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				if c.Text != directive && !strings.HasPrefix(c.Text, directive+" ") {
+					continue
+				}
+				if lines == nil {
+					lines = make(map[*token.File]map[int]bool)
+				}
+				tf := pass.Fset.File(c.Pos())
+				if lines[tf] == nil {
+					lines[tf] = make(map[int]bool)
+				}
+				lines[tf][tf.Line(c.Pos())] = true
+			}
+		}
+	}
+	return lines
+}
+
+// checkAllMembersLiterals checks that composite literals marked with
+// //gounion:all-members, on the line of the literal or the line before it,
+// contain a value of every member of their union element type. It returns
+// the marked literals of unions.
+func checkAllMembersLiterals(pass *analysis.Pass, inspect *inspector.Inspector, cache *unionCache) []UnionUse {
+	lines := directiveLines(pass, allMembersDirective)
+	if lines == nil {
+		return nil
+	}
+
+	nodeFilter := []ast.Node{
+		(*ast.CompositeLit)(nil),
+	}
+
+	var uses []UnionUse
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		lit := n.(*ast.CompositeLit)
+
+		tf := pass.Fset.File(lit.Pos())
+		line := tf.Line(lit.Pos())
+		if !lines[tf][line] && !lines[tf][line-1] {
+			return
+		}
+		// Only the outermost literal starting on the line is marked.
+		delete(lines[tf], line)
+		delete(lines[tf], line-1)
+
+		// Maps keyed by a union, such as state transition tables, have
+		// their keys checked instead of their values.
+		typ := pass.TypesInfo.TypeOf(lit)
+		namedType, keys := literalKeyUnion(typ, cache), true
+		if namedType == nil {
+			namedType, keys = extractNamedInterface(literalElemType(typ)), false
+		}
+		var union *unionInfo
+		if namedType != nil {
+			union = cache.lookup(namedType.Obj())
+		}
+		if union == nil {
+			reportf(pass, lit.Pos(), categoryLiteral, "%s directive on a literal whose elements are not a union", allMembersDirective)
+			return
+		}
+		uses = append(uses, newUnionUse(pass, lit.Pos(), "literal", namedType.Obj()))
+
+		var handled []memberKey
+		for _, elt := range lit.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				if keys {
+					elt = kv.Key
+				} else {
+					elt = kv.Value
+				}
+			}
+			if key, ok := cache.caseKey(pass.TypesInfo.TypeOf(elt)); ok {
+				handled = append(handled, key)
+			}
+		}
+
+		missing, _ := findMissingTypes(union, handled, cache.requiredAt(union, lit.Pos()))
+		if len(missing) > 0 {
+			reportMembers(pass, cache.cfg, lit.Pos(), categoryLiteral, namedType.Obj().Name(), union.pkg,
+				"missing members in "+namedType.Obj().Name()+" literal",
+				missing)
+		}
+	})
+	return uses
+}
+
+// literalElemType returns the element type of a slice, array or map
+// composite literal type, or nil.
+func literalElemType(typ types.Type) types.Type {
+	if typ == nil {
+		return nil
+	}
+	switch t := typ.Underlying().(type) {
+	case *types.Slice:
+		return t.Elem()
+	case *types.Array:
+		return t.Elem()
+	case *types.Map:
+		return t.Elem()
+	case *types.Pointer:
+		return literalElemType(t.Elem())
+	}
+	return nil
+}
+
+// literalKeyUnion returns the key type of a map composite literal type if
+// it is a union, or nil.
+func literalKeyUnion(typ types.Type, cache *unionCache) *types.Named {
+	if typ == nil {
+		return nil
+	}
+	switch t := typ.Underlying().(type) {
+	case *types.Map:
+		if named := extractNamedInterface(t.Key()); named != nil && cache.lookup(named.Obj()) != nil {
+			return named
+		}
+	case *types.Pointer:
+		return literalKeyUnion(t.Elem(), cache)
+	}
+	return nil
+}
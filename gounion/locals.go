@@ -0,0 +1,199 @@
+package gounion
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// maxTrackedInits bounds the chain of initializers followed by
+// localInits.originType, as in x := any(s); y := x.
+const maxTrackedInits = 8
+
+// localInits records the initializers of the local variables of a package
+// that are never assigned after their declaration, so that type switches
+// on a variable that widened a union, as in
+//
+//	x := any(s)
+//	switch x.(type) {
+//
+// are checked against the union.
+type localInits struct {
+	pass  *analysis.Pass
+	init  map[*types.Var]ast.Expr
+	funcs map[*types.Func]*ast.FuncDecl // functions declared in the package
+}
+
+// newLocalInits records the initializers of the local variables of the
+// package, dropping those of variables assigned again or whose address is
+// taken.
+func newLocalInits(pass *analysis.Pass, inspect *inspector.Inspector) *localInits {
+	l := &localInits{
+		pass:  pass,
+		init:  make(map[*types.Var]ast.Expr),
+		funcs: make(map[*types.Func]*ast.FuncDecl),
+	}
+	reassigned := make(map[*types.Var]bool)
+	reassign := func(expr ast.Expr) {
+		if ident, ok := ast.Unparen(expr).(*ast.Ident); ok {
+			if v, ok := pass.TypesInfo.Uses[ident].(*types.Var); ok {
+				reassigned[v] = true
+			}
+		}
+	}
+
+	nodeFilter := []ast.Node{
+		(*ast.FuncDecl)(nil),
+		(*ast.AssignStmt)(nil),
+		(*ast.ValueSpec)(nil),
+		(*ast.RangeStmt)(nil),
+		(*ast.UnaryExpr)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if fn, ok := pass.TypesInfo.Defs[n.Name].(*types.Func); ok && n.Body != nil {
+				l.funcs[fn] = n
+			}
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if n.Tok == token.DEFINE && ok && len(n.Lhs) == len(n.Rhs) {
+					if v, ok := pass.TypesInfo.Defs[ident].(*types.Var); ok {
+						l.init[v] = n.Rhs[i]
+						continue
+					}
+				}
+				reassign(lhs)
+			}
+		case *ast.ValueSpec:
+			if len(n.Names) != len(n.Values) {
+				return
+			}
+			for i, name := range n.Names {
+				if v, ok := pass.TypesInfo.Defs[name].(*types.Var); ok && v.Parent() != pass.Pkg.Scope() {
+					l.init[v] = n.Values[i]
+				}
+			}
+		case *ast.RangeStmt:
+			if n.Tok == token.ASSIGN {
+				reassign(n.Key)
+				if n.Value != nil {
+					reassign(n.Value)
+				}
+			}
+		case *ast.UnaryExpr:
+			if n.Op == token.AND {
+				reassign(n.X)
+			}
+		}
+	})
+	for v := range reassigned {
+		delete(l.init, v)
+	}
+	return l
+}
+
+// originType returns the type of the expression the tag expr of a type
+// switch was widened from, looking through conversions to interface types,
+// variables initialized in turn, and calls to functions of the package
+// returning one of their parameters, as in
+//
+//	func widen(s Shape) any { return s }
+//
+// It returns nil if expr is not widened.
+func (l *localInits) originType(expr ast.Expr) types.Type {
+	origin := ast.Unparen(expr)
+	for range maxTrackedInits {
+		origin = l.unwrap(origin)
+		ident, ok := origin.(*ast.Ident)
+		if !ok {
+			break
+		}
+		v, ok := l.pass.TypesInfo.Uses[ident].(*types.Var)
+		if !ok || l.init[v] == nil {
+			break
+		}
+		origin = l.init[v]
+	}
+	if origin == ast.Unparen(expr) {
+		return nil
+	}
+	return l.pass.TypesInfo.TypeOf(origin)
+}
+
+// unwrap returns the operand of conversions of expr to interface types,
+// and the argument whose parameter is returned by calls to functions of
+// the package consisting of a return statement.
+func (l *localInits) unwrap(expr ast.Expr) ast.Expr {
+	for range maxTrackedInits {
+		call, ok := ast.Unparen(expr).(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 || call.Ellipsis.IsValid() {
+			return ast.Unparen(expr)
+		}
+		if tv, ok := l.pass.TypesInfo.Types[call.Fun]; ok && tv.IsType() {
+			if !types.IsInterface(tv.Type) {
+				return call
+			}
+			expr = call.Args[0]
+			continue
+		}
+		i := l.returnedParam(call)
+		if i < 0 {
+			return call
+		}
+		expr = call.Args[i]
+	}
+	return ast.Unparen(expr)
+}
+
+// returnedParam returns the index of the parameter returned, possibly
+// converted to an interface type, by the function called by call if it is
+// declared in the package with a single return statement as its body, or
+// -1.
+func (l *localInits) returnedParam(call *ast.CallExpr) int {
+	fn := typeutil.StaticCallee(l.pass.TypesInfo, call)
+	if fn == nil {
+		return -1
+	}
+	fn = fn.Origin()
+	decl := l.funcs[fn]
+	if decl == nil || len(decl.Body.List) != 1 {
+		return -1
+	}
+	ret, ok := decl.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return -1
+	}
+	result := ast.Unparen(ret.Results[0])
+	for {
+		conv, ok := result.(*ast.CallExpr)
+		if !ok || len(conv.Args) != 1 {
+			break
+		}
+		tv, ok := l.pass.TypesInfo.Types[conv.Fun]
+		if !ok || !tv.IsType() || !types.IsInterface(tv.Type) {
+			break
+		}
+		result = ast.Unparen(conv.Args[0])
+	}
+	ident, ok := result.(*ast.Ident)
+	if !ok {
+		return -1
+	}
+	sig := fn.Type().(*types.Signature)
+	for i := 0; i < sig.Params().Len(); i++ {
+		if sig.Params().At(i) != l.pass.TypesInfo.Uses[ident] {
+			continue
+		}
+		if i >= len(call.Args) || (sig.Variadic() && i == sig.Params().Len()-1) {
+			return -1
+		}
+		return i
+	}
+	return -1
+}
@@ -0,0 +1,173 @@
+package gounion
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"regexp"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// runtimePkgPath is the import path of the gounionrt runtime helpers.
+const runtimePkgPath = "github.com/YuitoSato/gounion/gounionrt"
+
+// matchFuncName matches the names of the gounionrt.MatchN helpers.
+var matchFuncName = regexp.MustCompile(`^Match[0-9]+$`)
+
+// importsRuntime reports whether pkg directly imports the gounionrt package.
+func importsRuntime(pkg *types.Package) bool {
+	for _, imp := range pkg.Imports() {
+		if imp.Path() == runtimePkgPath {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRuntimeCalls checks calls to the gounionrt helpers on union interfaces:
+// gounionrt.MatchN must provide a function for every member, and
+// gounionrt.NewDispatcher must register a handler for every member. It
+// returns the calls on unions.
+func checkRuntimeCalls(pass *analysis.Pass, inspect *inspector.Inspector, cache *unionCache) []UnionUse {
+	nodeFilter := []ast.Node{
+		(*ast.CallExpr)(nil),
+	}
+
+	var uses []UnionUse
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != runtimePkgPath {
+			return
+		}
+
+		var union *types.TypeName
+		switch {
+		case matchFuncName.MatchString(fn.Name()):
+			union = checkMatchCall(pass, call, fn, cache)
+		case fn.Name() == "NewDispatcher":
+			union = checkDispatcherCall(pass, call, cache)
+		}
+		if union != nil {
+			uses = append(uses, newUnionUse(pass, call.Pos(), "gounionrt."+fn.Name(), union))
+		}
+	})
+	return uses
+}
+
+// checkMatchCall checks that a gounionrt.MatchN call provides a function
+// for every member of the union. It returns the union, or nil if the call
+// is not on a union.
+func checkMatchCall(pass *analysis.Pass, call *ast.CallExpr, fn *types.Func, cache *unionCache) *types.TypeName {
+	// The type arguments are [S, A1, ..., An, R].
+	typeArgs := instanceTypeArgs(pass, call.Fun)
+	if typeArgs == nil || typeArgs.Len() < 2 {
+		return nil
+	}
+
+	namedType := extractNamedInterface(typeArgs.At(0))
+	if namedType == nil {
+		return nil
+	}
+
+	union := cache.lookup(namedType.Obj())
+	if union == nil {
+		return nil // Not a union interface
+	}
+
+	var handled []memberKey
+	for i := 1; i < typeArgs.Len()-1; i++ {
+		if key, ok := cache.caseKey(typeArgs.At(i)); ok {
+			handled = append(handled, key)
+		}
+	}
+
+	missing, _ := findMissingTypes(union, handled, cache.requiredAt(union, call.Pos()))
+
+	if len(missing) > 0 {
+		reportMembers(pass, cache.cfg, call.Pos(), categoryMatch, namedType.Obj().Name(), union.pkg,
+			fmt.Sprintf("missing cases in gounionrt.%s on %s", fn.Name(), namedType.Obj().Name()),
+			missing)
+	}
+	return namedType.Obj()
+}
+
+// checkDispatcherCall checks that a gounionrt.NewDispatcher call registers
+// a handler for every member of the union. Only calls whose arguments are
+// all direct gounionrt.Handle calls are checked, since handlers built
+// elsewhere can't be resolved statically. It returns the union, checked or
+// not, or nil if the call is not on a union.
+func checkDispatcherCall(pass *analysis.Pass, call *ast.CallExpr, cache *unionCache) *types.TypeName {
+	// The type arguments are [S].
+	typeArgs := instanceTypeArgs(pass, call.Fun)
+	if typeArgs == nil || typeArgs.Len() != 1 {
+		return nil
+	}
+
+	namedType := extractNamedInterface(typeArgs.At(0))
+	if namedType == nil {
+		return nil
+	}
+
+	union := cache.lookup(namedType.Obj())
+	if union == nil {
+		return nil // Not a union interface
+	}
+	if call.Ellipsis.IsValid() {
+		return namedType.Obj()
+	}
+
+	var handled []memberKey
+	for _, arg := range call.Args {
+		if !isRuntimeCall(pass, arg, "Handle") {
+			return namedType.Obj()
+		}
+
+		// The type arguments are [S, M].
+		handleCall := ast.Unparen(arg).(*ast.CallExpr)
+		handleArgs := instanceTypeArgs(pass, handleCall.Fun)
+		if handleArgs == nil || handleArgs.Len() != 2 {
+			return namedType.Obj()
+		}
+		if key, ok := cache.caseKey(handleArgs.At(1)); ok {
+			handled = append(handled, key)
+		}
+	}
+
+	missing, _ := findMissingTypes(union, handled, cache.requiredAt(union, call.Pos()))
+
+	if len(missing) > 0 {
+		reportMembers(pass, cache.cfg, call.Pos(), categoryMatch, namedType.Obj().Name(), union.pkg,
+			"missing handlers in gounionrt.NewDispatcher on "+namedType.Obj().Name(),
+			missing)
+	}
+	return namedType.Obj()
+}
+
+// instanceTypeArgs returns the type arguments of the generic function
+// instantiation denoted by fun, or nil if fun is not an instantiation.
+func instanceTypeArgs(pass *analysis.Pass, fun ast.Expr) *types.TypeList {
+	var ident *ast.Ident
+	switch f := ast.Unparen(fun).(type) {
+	case *ast.Ident:
+		ident = f
+	case *ast.SelectorExpr:
+		ident = f.Sel
+	case *ast.IndexExpr:
+		return instanceTypeArgs(pass, f.X)
+	case *ast.IndexListExpr:
+		return instanceTypeArgs(pass, f.X)
+	default:
+		return nil
+	}
+
+	inst, ok := pass.TypesInfo.Instances[ident]
+	if !ok {
+		return nil
+	}
+	return inst.TypeArgs
+}
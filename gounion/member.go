@@ -0,0 +1,59 @@
+package gounion
+
+import (
+	"go/types"
+	"strings"
+)
+
+// memberKey identifies a member type, or a type handled by a case, by object
+// identity rather than by its formatted name, so that same-named types of
+// different packages, aliases and instantiations compare correctly.
+type memberKey struct {
+	path    string // package path of the type's declaration
+	name    string // name of the declared type
+	pointer bool   // whether the key denotes the pointer type
+	targs   string // type arguments of an instantiation, when matched per instantiation
+}
+
+// parseMember returns the key of a member recorded in a fact as "Name" or
+// "*Name", relative to the package pkg declaring the union's members.
+func parseMember(pkg *types.Package, member string) memberKey {
+	name, pointer := strings.CutPrefix(member, "*")
+	key := memberKey{name: name, pointer: pointer}
+	if pkg != nil {
+		key.path = pkg.Path()
+	}
+	return key
+}
+
+// memberKeyOf returns the key of a named type or pointer to a named type.
+// Aliases are resolved and instantiated types map to their generic type,
+// unless perInstance is set: then an instantiation such as Some[int] has its
+// own key, which does not cover the generic member Some.
+// It reports false for other types.
+func memberKeyOf(typ types.Type, perInstance bool) (memberKey, bool) {
+	var key memberKey
+	typ = types.Unalias(typ)
+	if ptr, ok := typ.(*types.Pointer); ok {
+		key.pointer = true
+		typ = types.Unalias(ptr.Elem())
+	}
+
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return memberKey{}, false
+	}
+	obj := named.Origin().Obj()
+	key.name = obj.Name()
+	if targs := named.TypeArgs(); perInstance && targs.Len() > 0 {
+		list := make([]string, targs.Len())
+		for i := range list {
+			list[i] = types.TypeString(targs.At(i), nil)
+		}
+		key.targs = strings.Join(list, ",")
+	}
+	if obj.Pkg() != nil {
+		key.path = obj.Pkg().Path()
+	}
+	return key, true
+}
@@ -0,0 +1,122 @@
+package gounion
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"text/template"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// markerNameData is the data the MarkerName template is executed with.
+type markerNameData struct {
+	Interface string // name of the union interface
+}
+
+// markerNameTemplate parses the MarkerName template, or returns nil if the
+// check is disabled.
+func (c *config) markerNameTemplate() (*template.Template, error) {
+	if c.MarkerName == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("marker-name").Option("missingkey=error").Parse(c.MarkerName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid marker-name: %w", err)
+	}
+	return tmpl, nil
+}
+
+// checkMarkerNames reports union interfaces whose marker method is not
+// named as produced by tmpl, with a fix renaming the marker method of the
+// interface and of all its members.
+func checkMarkerNames(pass *analysis.Pass, inspect *inspector.Inspector, tmpl *template.Template) {
+	nodeFilter := []ast.Node{
+		(*ast.TypeSpec)(nil),
+	}
+
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		typeSpec := n.(*ast.TypeSpec)
+		if _, ok := typeSpec.Type.(*ast.InterfaceType); !ok {
+			return
+		}
+		typeName, ok := pass.TypesInfo.Defs[typeSpec.Name].(*types.TypeName)
+		if !ok {
+			return
+		}
+		iface, ok := typeName.Type().Underlying().(*types.Interface)
+		if !ok || len(registry.EmbeddedUnions(iface)) > 1 {
+			return
+		}
+		// Unions with several markers have no single expected name.
+		markers := registry.MarkerMethods(iface)
+		if len(markers) != 1 {
+			return
+		}
+		marker := markers[0]
+		// Markers inherited from an embedded union are checked there.
+		if !declaresMethod(iface, marker) {
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, markerNameData{Interface: typeName.Name()}); err != nil {
+			return
+		}
+		want := buf.String()
+		if want == marker || !token.IsIdentifier(want) {
+			return
+		}
+
+		pass.Report(analysis.Diagnostic{
+			Pos:      typeSpec.Name.Pos(),
+			Category: categoryMarkerName,
+			Message:  fmt.Sprintf("marker method %s of union %s should be named %s", marker, typeName.Name(), want),
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message:   fmt.Sprintf("Rename %s to %s", marker, want),
+				TextEdits: renameMarker(pass, iface, marker, want),
+			}},
+		})
+	})
+}
+
+// declaresMethod reports whether iface declares the named method itself,
+// rather than through an embedded interface.
+func declaresMethod(iface *types.Interface, name string) bool {
+	for i := 0; i < iface.NumExplicitMethods(); i++ {
+		if iface.ExplicitMethod(i).Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// renameMarker returns the edits renaming every declaration and use, in the
+// package, of the marker method of iface and of its implementations.
+func renameMarker(pass *analysis.Pass, iface *types.Interface, marker, name string) []analysis.TextEdit {
+	var edits []analysis.TextEdit
+	rename := func(id *ast.Ident, obj types.Object) {
+		fn, ok := obj.(*types.Func)
+		if !ok || fn.Name() != marker || fn.Pkg() != pass.Pkg {
+			return
+		}
+		recv := fn.Type().(*types.Signature).Recv()
+		if recv == nil || !types.Implements(recv.Type(), iface) && !types.Identical(recv.Type(), iface) {
+			return
+		}
+		edits = append(edits, analysis.TextEdit{Pos: id.Pos(), End: id.End(), NewText: []byte(name)})
+	}
+	for id, obj := range pass.TypesInfo.Defs {
+		rename(id, obj)
+	}
+	for id, obj := range pass.TypesInfo.Uses {
+		rename(id, obj)
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].Pos < edits[j].Pos })
+	return edits
+}
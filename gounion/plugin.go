@@ -1,6 +1,11 @@
 package gounion
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/golangci/plugin-module-register/register"
 	"golang.org/x/tools/go/analysis"
 )
@@ -9,17 +14,123 @@ func init() {
 	register.Plugin("gounion", New)
 }
 
-// New creates a new gounion plugin instance for golangci-lint.
+// New creates a new gounion plugin instance for golangci-lint. Each
+// setting is applied to the analyzer's flag of the same name, so that the
+// settings mean exactly what the flags do in the other drivers.
 func New(settings any) (register.LinterPlugin, error) {
-	return &plugin{}, nil
+	values, err := register.DecodeSettings[map[string]any](settings)
+	if err != nil {
+		return nil, err
+	}
+	a := newAnalyzer(&config{})
+	if err := applySettings(a, values); err != nil {
+		return nil, err
+	}
+	return &plugin{analyzer: a}, nil
 }
 
-type plugin struct{}
+type plugin struct {
+	analyzer *analysis.Analyzer
+}
 
 func (p *plugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
-	return []*analysis.Analyzer{Analyzer}, nil
+	return []*analysis.Analyzer{p.analyzer}, nil
 }
 
 func (p *plugin) GetLoadMode() string {
 	return register.LoadModeTypesInfo
 }
+
+// applySettings sets the flags of a from the decoded plugin settings, in
+// name order. Lists set comma-separated list flags, and the "unions"
+// mapping, from qualified union names to options, sets the repeatable
+// -union flag once per union.
+func applySettings(a *analysis.Analyzer, values map[string]any) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == "unions" {
+			if err := applyUnionSettings(a, values[name]); err != nil {
+				return err
+			}
+			continue
+		}
+		if a.Flags.Lookup(name) == nil || name == "union" {
+			return fmt.Errorf("unknown setting %q", name)
+		}
+		value, err := settingString(values[name])
+		if err != nil {
+			return fmt.Errorf("setting %q: %v", name, err)
+		}
+		if err := a.Flags.Set(name, value); err != nil {
+			return fmt.Errorf("setting %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// applyUnionSettings sets the -union flag of a for each union of the
+// "unions" setting.
+func applyUnionSettings(a *analysis.Analyzer, value any) error {
+	unions, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf(`setting "unions": want a mapping from union names to options, got %T`, value)
+	}
+	names := make([]string, 0, len(unions))
+	for name := range unions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		opts, ok := unions[name].(map[string]any)
+		if !ok {
+			return fmt.Errorf("setting \"unions\": want a mapping of options for %s, got %T", name, unions[name])
+		}
+		keys := make([]string, 0, len(opts))
+		for key := range opts {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var settings []string
+		for _, key := range keys {
+			v, err := settingString(opts[key])
+			if err != nil {
+				return fmt.Errorf("setting \"unions\": %s: %s: %v", name, key, err)
+			}
+			settings = append(settings, key+"="+v)
+		}
+		if err := a.Flags.Set("union", name+":"+strings.Join(settings, ",")); err != nil {
+			return fmt.Errorf(`setting "unions": %v`, err)
+		}
+	}
+	return nil
+}
+
+// settingString returns the flag value of a decoded setting.
+func settingString(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("want a list of strings, got a %T item", item)
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", fmt.Errorf("unsupported value of type %T", value)
+}
@@ -0,0 +1,160 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DiscriminatorTag is the struct tag key setting the discriminator of a
+// member, the string identifying it in encoded union values, e.g. the tag
+// discriminator:"circle" on a blank field _ struct{}. Members without the
+// tag are identified by their type name.
+const DiscriminatorTag = "discriminator"
+
+// Discriminator returns the discriminator of a member: the value of the
+// DiscriminatorTag of one of its struct fields, and true, or else the name
+// of the member, and false.
+func Discriminator(member *types.TypeName) (string, bool) {
+	if st, ok := member.Type().Underlying().(*types.Struct); ok {
+		for i := 0; i < st.NumFields(); i++ {
+			if value, ok := reflect.StructTag(st.Tag(i)).Lookup(DiscriminatorTag); ok {
+				return value, true
+			}
+		}
+	}
+	return member.Name(), false
+}
+
+// DiscriminatorLock records the discriminators of the members of unions,
+// keyed by the qualified name of the union (e.g. "example.com/shape.Shape")
+// and then by member name, so that changes to them, which break decoding of
+// values encoded before, are caught. It is committed as a JSON file.
+//
+// A member removed on purpose is retired by prefixing its name in the lock
+// with RetiredPrefix, e.g. "-Triangle": its discriminator is then kept out
+// of use, so that values encoded with it are not decoded as another member.
+type DiscriminatorLock map[string]map[string]string
+
+// RetiredPrefix prefixes the names of retired members in a
+// DiscriminatorLock.
+const RetiredPrefix = "-"
+
+// ReadDiscriminatorLock reads a DiscriminatorLock in JSON from r.
+func ReadDiscriminatorLock(r io.Reader) (DiscriminatorLock, error) {
+	var lock DiscriminatorLock
+	if err := json.NewDecoder(r).Decode(&lock); err != nil {
+		return nil, fmt.Errorf("reading discriminator lock: %w", err)
+	}
+	return lock, nil
+}
+
+// WriteTo writes the lock to w as indented JSON, with sorted keys.
+func (l DiscriminatorLock) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(l, "", "\t")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// Update records the current discriminators of the members of union,
+// keeping those of retired members and forgetting those of other removed
+// members, such as renamed members that kept their discriminator.
+func (l DiscriminatorLock) Update(union *types.TypeName, members []string) {
+	locked := make(map[string]string, len(members))
+	for name, d := range l[qualifiedName(union)] {
+		if strings.HasPrefix(name, RetiredPrefix) {
+			locked[name] = d
+		}
+	}
+	for _, obj := range memberObjects(union, members) {
+		locked[obj.Name()], _ = Discriminator(obj)
+	}
+	l[qualifiedName(union)] = locked
+}
+
+// DiscriminatorError is a problem with the discriminator of a member.
+type DiscriminatorError struct {
+	Member *types.TypeName // the union, for locked members no longer in it
+	Msg    string
+}
+
+// Error implements the error interface.
+func (e *DiscriminatorError) Error() string {
+	return e.Msg
+}
+
+// CheckDiscriminators returns the problems with the discriminators of the
+// members of union, named as in UnionInterface.Members: empty
+// discriminators, discriminators shared by several members, and, if lock is
+// not nil, discriminators that changed since they were locked, reuses of
+// the discriminators of retired members, and locked members that were
+// renamed or removed along with their discriminator without being retired.
+// New members are not in the lock and may have any other discriminator.
+func CheckDiscriminators(union *types.TypeName, members []string, lock DiscriminatorLock) []*DiscriminatorError {
+	var errs []*DiscriminatorError
+	locked := lock[qualifiedName(union)]
+	retired := make(map[string]string) // discriminator -> retired member
+	for name, d := range locked {
+		if name, ok := strings.CutPrefix(name, RetiredPrefix); ok {
+			retired[d] = name
+		}
+	}
+	owners := make(map[string]string) // discriminator -> member
+	current := make(map[string]bool)  // member names
+	for _, obj := range memberObjects(union, members) {
+		current[obj.Name()] = true
+		d, _ := Discriminator(obj)
+		switch prev, ok := locked[obj.Name()]; {
+		case d == "":
+			errs = append(errs, &DiscriminatorError{obj, fmt.Sprintf("%s has an empty discriminator", obj.Name())})
+			continue
+		case ok && prev != d:
+			errs = append(errs, &DiscriminatorError{obj, fmt.Sprintf("discriminator of %s changed from %q to %q since it was locked", obj.Name(), prev, d)})
+		}
+		if owner, ok := retired[d]; ok {
+			errs = append(errs, &DiscriminatorError{obj, fmt.Sprintf("%s has the discriminator %q of the retired member %s", obj.Name(), d, owner)})
+		}
+		if owner, ok := owners[d]; ok {
+			errs = append(errs, &DiscriminatorError{obj, fmt.Sprintf("%s has the discriminator %q of %s", obj.Name(), d, owner)})
+			continue
+		}
+		owners[d] = obj.Name()
+	}
+
+	var gone []string
+	for name, d := range locked {
+		if _, ok := owners[d]; !ok && !current[name] && !strings.HasPrefix(name, RetiredPrefix) {
+			gone = append(gone, name)
+		}
+	}
+	sort.Strings(gone)
+	for _, name := range gone {
+		errs = append(errs, &DiscriminatorError{union, fmt.Sprintf("locked member %s, with the discriminator %q, was renamed or removed; restore it or retire it as %q", name, locked[name], RetiredPrefix+name)})
+	}
+	return errs
+}
+
+// memberObjects returns the type names of the members of union, named as
+// in UnionInterface.Members, in order.
+func memberObjects(union *types.TypeName, members []string) []*types.TypeName {
+	var objs []*types.TypeName
+	for _, member := range members {
+		name, _, _ := strings.Cut(strings.TrimPrefix(member, "*"), "[")
+		if obj, ok := union.Pkg().Scope().Lookup(name).(*types.TypeName); ok {
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
+
+// qualifiedName returns the name of obj qualified by its package path.
+func qualifiedName(obj *types.TypeName) string {
+	return obj.Pkg().Path() + "." + obj.Name()
+}
@@ -0,0 +1,138 @@
+package registry
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// EnumDirective, in the doc comment of a const declaration, makes the
+// constants it declares the members of an enum of their named type, e.g.
+//
+//	//gounion:enum
+//	const (
+//		Red Color = iota
+//		Green
+//		Blue
+//	)
+//
+// Switches on the type must then handle every member, like type switches
+// on unions.
+const EnumDirective = "//gounion:enum"
+
+// Enum is an enum type declared in a package, together with its fact.
+type Enum struct {
+	Obj  *types.TypeName
+	Fact *EnumType
+}
+
+// FindEnums returns the enums declared with //gounion:enum in files, the
+// syntax of pkg, sorted by name. Like FindUnions, it can be used outside of
+// an analysis pass (e.g. by code generators).
+func FindEnums(pkg *types.Package, files []*ast.File) []Enum {
+	enums, _ := ParseEnums(pkg, files)
+	return enums
+}
+
+// ParseEnums is like FindEnums, and also returns the //gounion:enum
+// directives on const declarations whose constants do not share a named
+// type of pkg, which declare no enum.
+func ParseEnums(pkg *types.Package, files []*ast.File) ([]Enum, []*ast.Comment) {
+	var (
+		enums   []Enum
+		index   = make(map[*types.TypeName]int) // enum type -> position in enums
+		invalid []*ast.Comment
+	)
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+			directive := enumDirective(genDecl.Doc)
+			if directive == nil {
+				continue
+			}
+
+			obj, constants := enumConstants(pkg, genDecl)
+			if obj == nil {
+				invalid = append(invalid, directive)
+				continue
+			}
+			i, ok := index[obj]
+			if !ok {
+				i = len(enums)
+				index[obj] = i
+				enums = append(enums, Enum{Obj: obj, Fact: new(EnumType)})
+			}
+			enums[i].Fact.Constants = append(enums[i].Fact.Constants, constants...)
+		}
+	}
+
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Obj.Name() < enums[j].Obj.Name() })
+	return enums, invalid
+}
+
+// enumDirective returns the //gounion:enum directive of a doc comment, or
+// nil if it has none.
+func enumDirective(doc *ast.CommentGroup) *ast.Comment {
+	if doc == nil {
+		return nil
+	}
+	for _, c := range doc.List {
+		if c.Text == EnumDirective || strings.HasPrefix(c.Text, EnumDirective+" ") {
+			return c
+		}
+	}
+	return nil
+}
+
+// enumConstants returns the named type shared by the constants declared by
+// genDecl, and their names in declaration order. It returns a nil type if
+// the constants have no common named type declared in pkg.
+func enumConstants(pkg *types.Package, genDecl *ast.GenDecl) (*types.TypeName, []string) {
+	var (
+		obj       *types.TypeName
+		constants []string
+	)
+	for _, spec := range genDecl.Specs {
+		for _, name := range spec.(*ast.ValueSpec).Names {
+			if name.Name == "_" {
+				continue
+			}
+			c, ok := pkg.Scope().Lookup(name.Name).(*types.Const)
+			if !ok {
+				return nil, nil
+			}
+			named, ok := types.Unalias(c.Type()).(*types.Named)
+			if !ok || named.Obj().Pkg() != pkg || (obj != nil && named.Obj() != obj) {
+				return nil, nil
+			}
+			obj = named.Obj()
+			constants = append(constants, name.Name)
+		}
+	}
+	return obj, constants
+}
+
+// KindName returns the name, among names, that a constant of the kind type
+// named kind refers to, e.g. "Circle" for CircleKind, KindCircle or
+// ShapeKindCircle, as paired by the generated and refactored kind code.
+// Names are matched in one of these forms exactly first, so that KindleKind
+// refers to Kindle rather than to leKind. Otherwise, the constant is
+// returned without its affixes, which may name none of names.
+func KindName(constant, kind string, names []string) string {
+	for _, name := range names {
+		if constant == name+"Kind" || constant == "Kind"+name || constant == kind+name {
+			return name
+		}
+	}
+	for _, prefix := range []string{kind, "Kind"} {
+		if name, ok := strings.CutPrefix(constant, prefix); ok && name != "" {
+			return name
+		}
+	}
+	return strings.TrimSuffix(constant, "Kind")
+}
@@ -0,0 +1,39 @@
+package registry
+
+// Facts are serialized with encoding/gob by the analysis drivers, and
+// compared between runs by gopls to decide whether dependent packages must
+// be analyzed again. Their fields are therefore exported, of gob-encodable
+// types, and derived deterministically from the package: sorted or in
+// declaration order, and nil rather than empty, as gob decodes both as nil.
+
+// UnionInterface is a Fact indicating that an interface is a union type
+// with a private marker method and a set of implementing types.
+//
+// Members are recorded by name within the union's package, prefixed with
+// "*" for types whose pointer implements the marker. The analyzer compares
+// them with case types by identity (package path, name and pointerness),
+// not by these strings.
+type UnionInterface struct {
+	MarkerMethod string   // e.g., "isNode"; the first by name if the interface has several, which members all implement
+	Members      []string // e.g., ["*BadExpr", "*Ident", "*BasicLit"]
+	// Constraints holds the build constraint of each member, parallel to
+	// Members, or "" for members declared in unconstrained files. It is nil
+	// if no member is constrained.
+	Constraints []string
+}
+
+// AFact implements the analysis.Fact interface.
+func (*UnionInterface) AFact() {}
+
+// EnumType is a Fact indicating that a named type is an enum: its constants
+// declared in const groups annotated with //gounion:enum.
+//
+// Constants are recorded by name within the enum's package, in declaration
+// order. The analyzer compares them with case expressions by value, so
+// that aliases of a constant cover it too.
+type EnumType struct {
+	Constants []string // e.g., ["Red", "Green", "Blue"]
+}
+
+// AFact implements the analysis.Fact interface.
+func (*EnumType) AFact() {}
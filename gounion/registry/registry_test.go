@@ -0,0 +1,224 @@
+package registry_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+)
+
+const src = `package shapes
+
+type Shape interface{ isShape() }
+
+type Circle struct{}
+
+func (Circle) isShape() {}
+
+type Square struct{}
+
+func (*Square) isShape() {}
+
+type Wrapped struct{ Circle }
+
+type Round = Circle
+
+type Color int
+
+//gounion:enum
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+
+//gounion:enum
+const Size = 1
+`
+
+func check(t *testing.T) (*types.Package, []*ast.File) {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "shapes.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("example.com/shapes", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg, []*ast.File{f}
+}
+
+func TestFindUnions(t *testing.T) {
+	pkg, _ := check(t)
+
+	unions := registry.FindUnions(pkg)
+	if len(unions) != 1 || unions[0].Obj.Name() != "Shape" {
+		t.Fatalf("FindUnions() = %v, want Shape", unions)
+	}
+	want := &registry.UnionInterface{MarkerMethod: "isShape", Members: []string{"*Square", "Circle", "Wrapped"}}
+	if !reflect.DeepEqual(unions[0].Fact, want) {
+		t.Errorf("fact = %+v, want %+v", unions[0].Fact, want)
+	}
+
+	excluded := registry.UnionOf(unions[0].Obj, true)
+	if got := []string{"*Square", "Circle"}; !reflect.DeepEqual(excluded.Members, got) {
+		t.Errorf("UnionOf(Shape, true).Members = %v, want %v", excluded.Members, got)
+	}
+}
+
+func TestParseEnums(t *testing.T) {
+	pkg, files := check(t)
+
+	enums, invalid := registry.ParseEnums(pkg, files)
+	if len(enums) != 1 || enums[0].Obj.Name() != "Color" {
+		t.Fatalf("ParseEnums() = %v, want Color", enums)
+	}
+	if want := []string{"Red", "Green", "Blue"}; !reflect.DeepEqual(enums[0].Fact.Constants, want) {
+		t.Errorf("constants = %v, want %v", enums[0].Fact.Constants, want)
+	}
+	if len(invalid) != 1 {
+		t.Errorf("got %d invalid directives, want 1 (on Size)", len(invalid))
+	}
+}
+
+func TestKindName(t *testing.T) {
+	names := []string{"Circle", "Kindle", "Square"}
+	tests := []struct {
+		constant string
+		want     string
+	}{
+		{"CircleKind", "Circle"},
+		{"KindCircle", "Circle"},
+		{"ShapeKindCircle", "Circle"},
+		{"KindleKind", "Kindle"},
+		{"KindKindle", "Kindle"},
+		{"ShapeKindKindle", "Kindle"},
+		{"KindUnknown", "Unknown"},
+		{"TriangleKind", "Triangle"},
+	}
+	for _, tt := range tests {
+		if got := registry.KindName(tt.constant, "ShapeKind", names); got != tt.want {
+			t.Errorf("KindName(%q) = %q, want %q", tt.constant, got, tt.want)
+		}
+	}
+}
+
+// TestFactsGob checks that the facts survive the gob round trip of the
+// analysis drivers unchanged, and encode to the same bytes on every run, as
+// gopls requires to reuse the facts of unchanged packages.
+func TestFactsGob(t *testing.T) {
+	pkg, files := check(t)
+	enums := registry.FindEnums(pkg, files)
+	facts := []interface{}{
+		registry.FindUnions(pkg)[0].Fact,
+		&registry.UnionInterface{MarkerMethod: "isShape", Members: []string{"Circle", "Square"}, Constraints: []string{"", "linux"}},
+		enums[0].Fact,
+	}
+
+	for _, fact := range facts {
+		for _, field := range reflect.VisibleFields(reflect.TypeOf(fact).Elem()) {
+			if !field.IsExported() {
+				t.Errorf("%T has an unexported field %s, which gob drops", fact, field.Name)
+			}
+		}
+
+		encode := func() []byte {
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(fact); err != nil {
+				t.Fatalf("encoding %T: %v", fact, err)
+			}
+			return buf.Bytes()
+		}
+		data := encode()
+		if again := encode(); !bytes.Equal(data, again) {
+			t.Errorf("%T encodes differently between runs", fact)
+		}
+
+		decoded := reflect.New(reflect.TypeOf(fact).Elem()).Interface()
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(decoded); err != nil {
+			t.Fatalf("decoding %T: %v", fact, err)
+		}
+		if !reflect.DeepEqual(decoded, fact) {
+			t.Errorf("%T = %+v after a gob round trip, want %+v", fact, decoded, fact)
+		}
+	}
+}
+
+const discriminatorSrc = `package events
+
+type Event interface{ isEvent() }
+
+type Created struct {
+	_ struct{} ` + "`discriminator:\"created\"`" + `
+}
+
+type Updated struct {
+	_ struct{} ` + "`discriminator:\"created\"`" + `
+}
+
+type Deleted struct {
+	_ struct{} ` + "`discriminator:\"\"`" + `
+}
+
+type Renamed struct{}
+
+func (Created) isEvent() {}
+func (Updated) isEvent() {}
+func (Deleted) isEvent() {}
+func (Renamed) isEvent() {}
+`
+
+func TestCheckDiscriminators(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "events.go", discriminatorSrc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("example.com/events", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	union := registry.FindUnions(pkg)[0]
+
+	// Moved was renamed to Created, keeping its discriminator, but Archived
+	// was removed along with it, and Legacy retired.
+	lock := registry.DiscriminatorLock{"example.com/events.Event": {
+		"Created": "created", "Renamed": "renamed", "Moved": "created", "Archived": "archived", "-Legacy": "Renamed",
+	}}
+	var got []string
+	for _, err := range registry.CheckDiscriminators(union.Obj, union.Fact.Members, lock) {
+		got = append(got, err.Member.Name()+": "+err.Error())
+	}
+	want := []string{
+		`Deleted: Deleted has an empty discriminator`,
+		`Renamed: discriminator of Renamed changed from "renamed" to "Renamed" since it was locked`,
+		`Renamed: Renamed has the discriminator "Renamed" of the retired member Legacy`,
+		`Updated: Updated has the discriminator "created" of Created`,
+		`Event: locked member Archived, with the discriminator "archived", was renamed or removed; restore it or retire it as "-Archived"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckDiscriminators() = %q, want %q", got, want)
+	}
+
+	lock.Update(union.Obj, union.Fact.Members)
+	var buf bytes.Buffer
+	if _, err := lock.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := registry.ReadDiscriminatorLock(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantLock := registry.DiscriminatorLock{"example.com/events.Event": {"-Legacy": "Renamed", "Created": "created", "Deleted": "", "Renamed": "Renamed", "Updated": "created"}}
+	if !reflect.DeepEqual(read, wantLock) {
+		t.Errorf("updated lock = %v, want %v", read, wantLock)
+	}
+}
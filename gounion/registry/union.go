@@ -0,0 +1,232 @@
+// Package registry detects the unions and enums of a type-checked package,
+// as the gounion analyzer does, and defines the facts it exports for them.
+//
+// It depends only on go/ast and go/types, so that code generators and other
+// linters can share gounion's notion of a union without the analyzer:
+//
+//	for _, u := range registry.FindUnions(pkg) {
+//		fmt.Println(u.Obj.Name(), u.Fact.Members)
+//	}
+//
+// The API of this package is stable: members are only added to it.
+package registry
+
+import (
+	"go/types"
+	"sort"
+)
+
+// Union is a union interface declared in a package, together with its fact.
+type Union struct {
+	Obj  *types.TypeName
+	Fact *UnionInterface
+}
+
+// FindUnions returns the union interfaces declared at package level in pkg,
+// sorted by name. It derives membership from type information, so it can be
+// used outside of an analysis pass (e.g. by code generators).
+func FindUnions(pkg *types.Package) []Union {
+	var unions []Union
+
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || typeName.IsAlias() {
+			continue
+		}
+
+		if fact := UnionOf(typeName, false); fact != nil {
+			unions = append(unions, Union{Obj: typeName, Fact: fact})
+		}
+	}
+
+	return unions
+}
+
+// UnionOf derives the union fact for an interface type name directly from
+// type information, without consulting exported facts. It returns nil if
+// the interface is not a union, including interfaces embedding several
+// unions, which are their intersection. With excludeEmbedded, types that
+// only have the marker methods through an embedded field are not members.
+//
+// The Constraints of the fact are not set, as they depend on the syntax of
+// the package.
+func UnionOf(typeName *types.TypeName, excludeEmbedded bool) *UnionInterface {
+	if typeName.Pkg() == nil {
+		return nil
+	}
+
+	iface, ok := typeName.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil
+	}
+
+	if len(EmbeddedUnions(iface)) > 1 {
+		return nil
+	}
+
+	markers := MarkerMethods(iface)
+	if len(markers) == 0 {
+		return nil
+	}
+
+	return &UnionInterface{
+		MarkerMethod: markers[0],
+		Members:      Members(typeName.Pkg(), markers, excludeEmbedded),
+	}
+}
+
+// MarkerMethods returns the marker methods of an interface, sorted by
+// name. A marker method is:
+// - unexported (starts with lowercase)
+// - has no parameters
+// - has no return values
+//
+// Members of a union must implement all of its marker methods, so that an
+// interface adding a marker to an embedded union (layered sealing) has the
+// members of both.
+func MarkerMethods(iface *types.Interface) []string {
+	var markers []string
+	for i := 0; i < iface.NumMethods(); i++ {
+		method := iface.Method(i)
+
+		// Check if unexported
+		if method.Exported() {
+			continue
+		}
+
+		sig, ok := method.Type().(*types.Signature)
+		if !ok {
+			continue
+		}
+
+		// Check no parameters
+		if sig.Params().Len() != 0 {
+			continue
+		}
+
+		// Check no return values
+		if sig.Results().Len() != 0 {
+			continue
+		}
+
+		markers = append(markers, method.Name())
+	}
+	sort.Strings(markers)
+	return markers
+}
+
+// EmbeddedUnions returns the union interfaces directly embedded in iface.
+// An interface embedding several unions has the members common to all of them.
+func EmbeddedUnions(iface *types.Interface) []*types.TypeName {
+	var unions []*types.TypeName
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		named, ok := types.Unalias(iface.EmbeddedType(i)).(*types.Named)
+		if !ok {
+			continue
+		}
+		if embedded, ok := named.Underlying().(*types.Interface); ok && len(MarkerMethods(embedded)) > 0 {
+			unions = append(unions, named.Obj())
+		}
+	}
+	return unions
+}
+
+// Members returns the types declared at package level in pkg that
+// implement all the given marker methods, sorted, prefixed with "*" for
+// types whose pointer implements them. With excludeEmbedded, types that
+// only have them through embedded fields are skipped.
+func Members(pkg *types.Package, markers []string, excludeEmbedded bool) []string {
+	var members []string
+
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+
+		// Aliases name a type declared elsewhere, a member under its own name
+		typeName, ok := obj.(*types.TypeName)
+		if !ok || typeName.IsAlias() {
+			continue
+		}
+
+		// Skip interfaces themselves
+		if _, ok := typeName.Type().Underlying().(*types.Interface); ok {
+			continue
+		}
+
+		if excludeEmbedded && markersPromoted(pkg, typeName.Type(), markers) {
+			continue
+		}
+
+		// Check both value type and pointer type for the marker methods
+		switch lookupMarkerMethods(pkg, typeName.Type(), markers) {
+		case markerOnValue:
+			members = append(members, typeName.Name())
+		case markerOnPointer:
+			members = append(members, "*"+typeName.Name())
+		}
+	}
+
+	// Sort members for consistent output
+	sort.Strings(members)
+
+	return members
+}
+
+// markerReceiver describes through which receiver a type implements a marker method.
+type markerReceiver int
+
+const (
+	markerAbsent    markerReceiver = iota // the type does not have the marker method
+	markerOnValue                         // the value type has the marker method
+	markerOnPointer                       // only the pointer type has the marker method
+)
+
+// lookupMarkerMethod reports whether typ has the given marker method, and
+// whether it is reachable from the value type or only from the pointer type.
+// It uses types.LookupFieldOrMethod instead of building method sets, which
+// avoids two allocations per candidate type.
+func lookupMarkerMethod(pkg *types.Package, typ types.Type, markerMethod string) markerReceiver {
+	if obj, _, _ := types.LookupFieldOrMethod(typ, false, pkg, markerMethod); isMethod(obj) {
+		return markerOnValue
+	}
+	if obj, _, _ := types.LookupFieldOrMethod(typ, true, pkg, markerMethod); isMethod(obj) {
+		return markerOnPointer
+	}
+	return markerAbsent
+}
+
+// lookupMarkerMethods is like lookupMarkerMethod for several marker
+// methods, which typ must all have. It reports markerOnPointer if any of
+// them is only reachable from the pointer type.
+func lookupMarkerMethods(pkg *types.Package, typ types.Type, markers []string) markerReceiver {
+	recv := markerOnValue
+	for _, marker := range markers {
+		switch lookupMarkerMethod(pkg, typ, marker) {
+		case markerAbsent:
+			return markerAbsent
+		case markerOnPointer:
+			recv = markerOnPointer
+		}
+	}
+	return recv
+}
+
+// markersPromoted reports whether typ has all the given marker methods
+// only as methods promoted from embedded fields, rather than declaring any
+// of them.
+func markersPromoted(pkg *types.Package, typ types.Type, markers []string) bool {
+	for _, marker := range markers {
+		obj, index, _ := types.LookupFieldOrMethod(typ, true, pkg, marker)
+		if !isMethod(obj) || len(index) == 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// isMethod reports whether obj is a method (as opposed to a field or nothing).
+func isMethod(obj types.Object) bool {
+	_, ok := obj.(*types.Func)
+	return ok
+}
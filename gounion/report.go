@@ -0,0 +1,154 @@
+package gounion
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Categories of the diagnostics, which //gounion:file-ignore directives
+// can be restricted to.
+const (
+	categorySwitch          = "switch"           // type switches
+	categoryEnum            = "enum"             // switches on //gounion:enum types
+	categoryMatch           = "match"            // calls to the gounionrt helpers
+	categoryErrorsAs        = "errors-as"        // errors.As chains
+	categoryLiteral         = "literal"          // //gounion:all-members literals
+	categoryFrozen          = "frozen"           // //gounion:frozen unions
+	categoryMarkerName      = "marker-name"      // -marker-name
+	categoryMaxMembers      = "max-members"      // -max-members
+	categorySharedMembers   = "shared-members"   // -shared-members
+	categoryIdenticalUnions = "identical-unions" // -identical-unions
+	categorySummary         = "summary"          // -summary
+	categoryResult          = "result"           // -definite-result
+	categoryDiscriminator   = "discriminator"    // -discriminators
+	categoryIgnore          = "ignore"           // expired or malformed //gounion:ignore and //gounion:file-ignore directives
+)
+
+// categories lists the diagnostic categories.
+var categories = []string{
+	categorySwitch,
+	categoryEnum,
+	categoryMatch,
+	categoryErrorsAs,
+	categoryLiteral,
+	categoryFrozen,
+	categoryMarkerName,
+	categoryMaxMembers,
+	categorySharedMembers,
+	categoryIdenticalUnions,
+	categorySummary,
+	categoryResult,
+	categoryDiscriminator,
+	categoryIgnore,
+}
+
+// installReporter wraps pass.Report to post-process every diagnostic the
+// analyzer reports, according to cfg, and to drop those suppressed by a
+// //gounion:file-ignore or staticcheck //lint: directive or outside the
+// packages and files cfg checks. The diagnostics of //gounion:ignore
+// directives are reported through it, so that they can be suppressed too.
+func installReporter(pass *analysis.Pass, cfg *config) error {
+	if !cfg.ChecksPackage(pass.Pkg.Path()) {
+		pass.Report = func(analysis.Diagnostic) {}
+		return nil
+	}
+	today, err := cfg.ignoreDate()
+	if err != nil {
+		return err
+	}
+	ignored, ignoredLines := lintIgnores(pass, fileIgnores(pass))
+	var directives []analysis.Diagnostic
+	report := pass.Report
+	pass.Report = func(d analysis.Diagnostic) { directives = append(directives, d) }
+	ignoredLines = gounionIgnores(pass, ignoredLines, today)
+	pass.Report = report
+	defer func() {
+		for _, d := range directives {
+			pass.Report(d)
+		}
+	}()
+	if !cfg.LineDirectives && !cfg.SkipTests && ignored == nil && ignoredLines == nil {
+		return nil
+	}
+
+	pass.Report = func(d analysis.Diagnostic) {
+		file := pass.Fset.File(d.Pos)
+		if ignored.suppresses(file, d.Category) || ignoredLines.suppresses(pass.Fset, d.Pos) || file != nil && !cfg.ChecksFile(file.Name()) {
+			return
+		}
+		if cfg.LineDirectives {
+			// The position is left alone, in the generated file that drivers
+			// know of, and the original source is named next to it.
+			if posn, ok := sourcePosition(pass.Fset, d.Pos); ok {
+				posn.Filename = filepath.Base(posn.Filename)
+				d.Related = append(d.Related, analysis.RelatedInformation{
+					Pos:     d.Pos,
+					Message: "generated from " + posn.String(),
+				})
+			}
+		}
+		report(d)
+	}
+	return nil
+}
+
+// reportf reports a diagnostic of the given category at pos.
+func reportf(pass *analysis.Pass, pos token.Pos, category, format string, args ...any) {
+	pass.Report(analysis.Diagnostic{
+		Pos:      pos,
+		Category: category,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// sourcePosition returns the position in the original source of pos set
+// by a //line directive (e.g. shapes.templ:11, or shapes.templ:11:3 if
+// the directive sets a column), and false if pos is not
+// affected by one.
+func sourcePosition(fset *token.FileSet, pos token.Pos) (token.Position, bool) {
+	adjusted := fset.PositionFor(pos, true)
+	raw := fset.PositionFor(pos, false)
+	if adjusted.Line <= 0 || adjusted.Filename == raw.Filename && adjusted.Line == raw.Line {
+		return token.Position{}, false
+	}
+	return adjusted, true
+}
+
+// defaultMaxListedMembers is the number of members listed in a diagnostic
+// when MaxListedMembers is zero.
+const defaultMaxListedMembers = 5
+
+// reportMembers reports a diagnostic about union of the given category at pos with the message msg
+// followed by the given members declared in pkg, e.g. missing cases. Beyond MaxListedMembers,
+// the message ends with "+N more", and every member is attached as related
+// information at its declaration. The diagnostic suggests the given fixes, if any.
+func reportMembers(pass *analysis.Pass, cfg *config, pos token.Pos, category, union string, pkg *types.Package, msg string, members []string, fixes ...analysis.SuggestedFix) {
+	limit := cfg.MaxListedMembers
+	if limit == 0 {
+		limit = defaultMaxListedMembers
+	}
+	if limit < 0 || len(members) <= limit {
+		reportAbout(pass, analysis.Diagnostic{Pos: pos, Category: category, Message: msg + ": " + joinNames(members), SuggestedFixes: fixes}, union, pkg, members)
+		return
+	}
+
+	diag := analysis.Diagnostic{
+		Pos:      pos,
+		Category: category,
+		Message:  msg + ": " + joinNames(members[:limit]) + ", +" + strconv.Itoa(len(members)-limit) + " more",
+
+		SuggestedFixes: fixes,
+	}
+	for i, decl := range memberPositions(pkg, members) {
+		if !decl.IsValid() {
+			decl = pos
+		}
+		diag.Related = append(diag.Related, analysis.RelatedInformation{Pos: decl, Message: members[i]})
+	}
+	reportAbout(pass, diag, union, pkg, members)
+}
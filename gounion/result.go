@@ -0,0 +1,97 @@
+package gounion
+
+import (
+	"go/token"
+	"go/types"
+	"reflect"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"golang.org/x/tools/go/analysis"
+)
+
+// Result is the result of the analyzer on a package: the type switches on
+// unions it found, the other code depending on their members, the unions
+// it declares, and its diagnostics as findings. Drivers use it to report
+// checked switches, e.g. as test cases, alongside the diagnostics.
+type Result struct {
+	Switches []CheckedSwitch
+	Uses     []UnionUse
+	Unions   []DeclaredUnion // unions declared in the package, sorted by name
+	Findings []Finding       // diagnostics reported, in order
+
+	// Explanation holds the steps deciding whether the type named by the
+	// explain option, if declared in the package, is a union.
+	Explanation []string
+}
+
+// DeclaredUnion is a union declared in the analyzed package.
+type DeclaredUnion struct {
+	Name    string
+	Members []string // as in UnionInterface.Members
+}
+
+// CheckedSwitch is a type switch on a union.
+type CheckedSwitch struct {
+	Pos      token.Pos
+	Union    string   // name of the union, as in diagnostics
+	UnionPkg string   // path of the package declaring the union
+	Func     string   // enclosing function, e.g. "area" or "(*Renderer).Draw"; empty outside of functions
+	Handled  []string // members with a case, qualified, in the order of the union's members
+	Missing  []string // members without a case, qualified; nil if the switch is exhaustive
+	Default  bool     // whether the switch has a default case
+
+	// DefaultAccepted reports that the default case handles the missing
+	// members, so that the switch is not reported for them (see
+	// -strict-default).
+	DefaultAccepted bool
+
+	// MissingPos holds the declaration of each missing member, parallel
+	// to Missing (NoPos if unknown).
+	MissingPos []token.Pos
+}
+
+// UnionUse is code, other than a type switch, that must be updated when a
+// member is added to a union: a gounionrt matcher or dispatcher call, a
+// //gounion:all-members literal, or a file generated by gounion-gen
+// referring to the union.
+type UnionUse struct {
+	Pos      token.Pos // for generated files, the package clause
+	Kind     string    // "gounionrt.Match2" (or another arity), "gounionrt.NewDispatcher", "literal" or "generated"
+	Union    string
+	UnionPkg string // path of the package declaring the union
+	Func     string // enclosing function, as in CheckedSwitch
+}
+
+// newUnionUse returns the use of the union obj at pos.
+func newUnionUse(pass *analysis.Pass, pos token.Pos, kind string, obj *types.TypeName) UnionUse {
+	use := UnionUse{
+		Pos:   pos,
+		Kind:  kind,
+		Union: obj.Name(),
+		Func:  funcName(fileOf(pass, pos), pos),
+	}
+	if obj.Pkg() != nil {
+		use.UnionPkg = obj.Pkg().Path()
+	}
+	return use
+}
+
+// resultType is the ResultType of the analyzer.
+var resultType = reflect.TypeOf((*Result)(nil))
+
+// declaredUnions returns the unions declared in pkg. excludeEmbedded is as
+// for exportUnionFacts.
+func declaredUnions(pkg *types.Package, excludeEmbedded bool) []DeclaredUnion {
+	var unions []DeclaredUnion
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || typeName.IsAlias() {
+			continue
+		}
+		if fact := registry.UnionOf(typeName, excludeEmbedded); fact != nil {
+			unions = append(unions, DeclaredUnion{Name: name, Members: fact.Members})
+		}
+	}
+	return unions
+}
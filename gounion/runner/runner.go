@@ -0,0 +1,93 @@
+// Package runner runs the gounion analyzer on packages from Go programs,
+// passing each of its findings to a callback with the union, the position
+// and the missing members it is about, and its suggested fix, so that code
+// review bots, editor plugins or migration scripts can embed gounion
+// without parsing its output.
+//
+//	err := runner.Run([]string{"./..."}, runner.Options{}, func(f runner.Finding) {
+//		fmt.Println(f.Posn, f.Union, f.Missing)
+//	})
+package runner
+
+import (
+	"go/token"
+
+	"github.com/YuitoSato/gounion/gounion"
+	"github.com/YuitoSato/gounion/internal/driver"
+)
+
+// Options configure a run.
+type Options struct {
+	// Dir is the directory to load packages from; empty means the
+	// current directory.
+	Dir string
+
+	// Tests also checks test packages.
+	Tests bool
+
+	// Settings are the analyzer's options, by flag name, as in the
+	// golangci-lint settings, e.g. {"per-member": true, "terminators":
+	// []any{"log.Fatal"}}. They apply to this run only.
+	Settings map[string]any
+}
+
+// Finding is a diagnostic of the analyzer.
+type Finding struct {
+	Posn     token.Position
+	Category string // e.g. "switch", as in //gounion:file-ignore directives
+	Message  string
+	Union    string   // name of the union or enum the finding is about, if any
+	UnionPkg string   // path of the package declaring the union
+	Missing  []string // members missing, qualified as in Message
+	Fix      *Fix     // suggested fix, if any
+}
+
+// Fix is a suggested fix of a finding.
+type Fix struct {
+	Message string
+	Edits   []Edit
+}
+
+// Edit replaces the bytes [Start, End) of a file with NewText.
+type Edit struct {
+	Filename   string
+	Start, End int // byte offsets
+	NewText    string
+}
+
+// Run loads the packages matching patterns, analyzes them and calls fn
+// with each finding, in position order. Findings reported identically in
+// several variants of a package, such as its test variant, are passed once.
+func Run(patterns []string, opts Options, fn func(Finding)) error {
+	plugin, err := gounion.New(opts.Settings)
+	if err != nil {
+		return err
+	}
+	analyzers, err := plugin.BuildAnalyzers()
+	if err != nil {
+		return err
+	}
+
+	report, err := driver.Check(analyzers[0], patterns, driver.Options{Dir: opts.Dir, Tests: opts.Tests})
+	if err != nil {
+		return err
+	}
+	for _, d := range report.Diagnostics {
+		f := Finding{
+			Posn:     d.Posn,
+			Category: d.Category,
+			Message:  d.Message,
+			Union:    d.Union,
+			UnionPkg: d.UnionPkg,
+			Missing:  d.Missing,
+		}
+		if len(d.Edits) > 0 {
+			f.Fix = &Fix{Message: d.Fix}
+			for _, e := range d.Edits {
+				f.Fix.Edits = append(f.Fix.Edits, Edit(e))
+			}
+		}
+		fn(f)
+	}
+	return nil
+}
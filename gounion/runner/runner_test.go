@@ -0,0 +1,67 @@
+package runner_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/YuitoSato/gounion/gounion/runner"
+)
+
+func TestRun(t *testing.T) {
+	var findings []runner.Finding
+	err := runner.Run([]string{"."}, runner.Options{Dir: "testdata/shape"}, func(f runner.Finding) {
+		findings = append(findings, f)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(findings), findings)
+	}
+	f := findings[0]
+	if got := filepath.Base(f.Posn.Filename); got != "shape.go" || f.Posn.Line != 14 {
+		t.Errorf("finding at %s:%d, want shape.go:14", got, f.Posn.Line)
+	}
+	want := []string{"shape.*Square", "shape.*Triangle"}
+	if f.Category != "switch" || f.Union != "Shape" || f.UnionPkg != "example.com/shape" || !reflect.DeepEqual(f.Missing, want) {
+		t.Errorf("finding about %s %s.%s missing %v, want switch example.com/shape.Shape missing %v", f.Category, f.UnionPkg, f.Union, f.Missing, want)
+	}
+	if f.Fix != nil {
+		t.Errorf("finding has a fix %q, want none", f.Fix.Message)
+	}
+}
+
+func TestRunSettings(t *testing.T) {
+	var findings []runner.Finding
+	err := runner.Run([]string{"."}, runner.Options{
+		Dir:      "testdata/shape",
+		Settings: map[string]any{"per-member": true},
+	}, func(f runner.Finding) {
+		findings = append(findings, f)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var missing []string
+	for _, f := range findings {
+		missing = append(missing, f.Missing...)
+		if f.Fix == nil || len(f.Fix.Edits) != 1 {
+			t.Errorf("finding %q: got fix %+v, want one edit", f.Message, f.Fix)
+		}
+	}
+	if want := []string{"shape.*Square", "shape.*Triangle"}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing members = %v, want %v", missing, want)
+	}
+}
+
+func TestRunInvalidSettings(t *testing.T) {
+	err := runner.Run([]string{"."}, runner.Options{
+		Dir:      "testdata/shape",
+		Settings: map[string]any{"no-such-option": true},
+	}, func(runner.Finding) {})
+	if err == nil {
+		t.Error("Run with an unknown setting: got nil error")
+	}
+}
@@ -0,0 +1,19 @@
+package shape
+
+type Shape interface{ isShape() }
+
+type Circle struct{}
+type Square struct{}
+type Triangle struct{}
+
+func (*Circle) isShape()   {}
+func (*Square) isShape()   {}
+func (*Triangle) isShape() {}
+
+func name(s Shape) string {
+	switch s.(type) {
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
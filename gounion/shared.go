@@ -0,0 +1,83 @@
+package gounion
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkSharedMembers reports types declared in the package that are members
+// of several of its unions, with the declarations of those unions as
+// related information. A union implied by another one the type belongs to,
+// such as the union embedded by a layered union, does not count.
+func checkSharedMembers(pass *analysis.Pass, excludeEmbedded bool) {
+	scope := pass.Pkg.Scope()
+	names := scope.Names()
+
+	unionsOf := make(map[string][]*types.TypeName) // member name -> unions, by name
+	for _, name := range names {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || typeName.IsAlias() {
+			continue
+		}
+		fact := registry.UnionOf(typeName, excludeEmbedded)
+		if fact == nil {
+			continue
+		}
+		for _, member := range fact.Members {
+			member = strings.TrimPrefix(member, "*")
+			unionsOf[member] = append(unionsOf[member], typeName)
+		}
+	}
+
+	members := make([]string, 0, len(unionsOf))
+	for member := range unionsOf {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	for _, member := range members {
+		unions := distinctUnions(unionsOf[member])
+		if len(unions) < 2 {
+			continue
+		}
+		obj := scope.Lookup(member)
+		if obj == nil {
+			continue
+		}
+
+		unionNames := make([]string, len(unions))
+		diag := analysis.Diagnostic{Pos: obj.Pos(), Category: categorySharedMembers}
+		for i, union := range unions {
+			unionNames[i] = union.Name()
+			diag.Related = append(diag.Related, analysis.RelatedInformation{
+				Pos:     union.Pos(),
+				Message: "union " + union.Name(),
+			})
+		}
+		diag.Message = member + " is a member of several unions: " + strings.Join(unionNames, ", ")
+		pass.Report(diag)
+	}
+}
+
+// distinctUnions returns the unions that are not implied by another one of
+// unions, i.e. whose interface no other interface of unions implements.
+func distinctUnions(unions []*types.TypeName) []*types.TypeName {
+	var distinct []*types.TypeName
+	for _, u := range unions {
+		implied := false
+		for _, v := range unions {
+			if v != u && types.Implements(v.Type(), u.Type().Underlying().(*types.Interface)) {
+				implied = true
+				break
+			}
+		}
+		if !implied {
+			distinct = append(distinct, u)
+		}
+	}
+	return distinct
+}
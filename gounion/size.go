@@ -0,0 +1,35 @@
+package gounion
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// checkUnionSizes reports unions declared in the package that have more
+// than max members, as a hint to split them into smaller unions.
+func checkUnionSizes(pass *analysis.Pass, inspect *inspector.Inspector, max int, excludeEmbedded bool) {
+	nodeFilter := []ast.Node{
+		(*ast.TypeSpec)(nil),
+	}
+
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		typeSpec := n.(*ast.TypeSpec)
+		if _, ok := typeSpec.Type.(*ast.InterfaceType); !ok {
+			return
+		}
+		typeName, ok := pass.TypesInfo.Defs[typeSpec.Name].(*types.TypeName)
+		if !ok {
+			return
+		}
+		fact := registry.UnionOf(typeName, excludeEmbedded)
+		if fact == nil || len(fact.Members) <= max {
+			return
+		}
+		reportf(pass, typeSpec.Name.Pos(), categoryMaxMembers, "union %s has %d members, more than the maximum of %d; consider splitting it",
+			typeName.Name(), len(fact.Members), max)
+	})
+}
@@ -0,0 +1,59 @@
+package gounion
+
+import (
+	"go/ast"
+	"slices"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// A TerminalDetector reports whether the statements of a case body end it
+// terminally, as a safety guard such as a call to an in-house fatal logger
+// or an alerting helper that never returns, rather than by handling the
+// value. It is called on the non-empty bodies of default cases of switches
+// on unions and enums, and with -definite-result also on those of the
+// other cases and of the blocks they end with. It must be safe for
+// concurrent use.
+type TerminalDetector func(pass *analysis.Pass, body []ast.Stmt) bool
+
+var terminalDetectors struct {
+	sync.RWMutex
+	list []*TerminalDetector
+}
+
+// RegisterTerminalDetector adds d to the detectors consulted, after the
+// built-in panic, error return and -terminators heuristics, to decide
+// whether a default case is a guard, so that the switch is still checked
+// for exhaustiveness, and whether a case ends with a terminating statement
+// for -definite-result. It is meant to be called from an init function of
+// wrapper binaries running the analyzer, before any analysis starts. The
+// returned function removes d from the detectors again, e.g. at the end of
+// a test.
+func RegisterTerminalDetector(d TerminalDetector) (unregister func()) {
+	terminalDetectors.Lock()
+	defer terminalDetectors.Unlock()
+	p := &d
+	terminalDetectors.list = append(terminalDetectors.list, p)
+	return func() {
+		terminalDetectors.Lock()
+		defer terminalDetectors.Unlock()
+		terminalDetectors.list = slices.DeleteFunc(terminalDetectors.list, func(q *TerminalDetector) bool { return q == p })
+	}
+}
+
+// detectedTerminal reports whether one of the registered detectors
+// considers body terminal.
+func detectedTerminal(pass *analysis.Pass, body []ast.Stmt) bool {
+	if len(body) == 0 {
+		return false
+	}
+	terminalDetectors.RLock()
+	defer terminalDetectors.RUnlock()
+	for _, d := range terminalDetectors.list {
+		if (*d)(pass, body) {
+			return true
+		}
+	}
+	return false
+}
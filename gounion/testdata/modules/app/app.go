@@ -0,0 +1,20 @@
+package app
+
+import (
+	"example.com/app/event"
+	"example.com/lib"
+)
+
+// Unions of the current module are checked.
+func handle(e event.Event) {
+	switch e.(type) { // want "missing cases in type switch on Event: event.\\*Deleted"
+	case *event.Created:
+	}
+}
+
+// Unions of dependencies are not.
+func tokenize(t lib.Token) {
+	switch t.(type) {
+	case *lib.Word:
+	}
+}
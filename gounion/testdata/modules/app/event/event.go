@@ -0,0 +1,13 @@
+package event
+
+type Event interface { // want Event:`&\{isEvent \[\*Created \*Deleted\] \[\]\}`
+	isEvent()
+}
+
+type Created struct{}
+
+func (*Created) isEvent() {}
+
+type Deleted struct{}
+
+func (*Deleted) isEvent() {}
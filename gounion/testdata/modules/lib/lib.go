@@ -0,0 +1,13 @@
+package lib
+
+type Token interface {
+	isToken()
+}
+
+type Word struct{}
+
+func (*Word) isToken() {}
+
+type Number struct{}
+
+func (*Number) isToken() {}
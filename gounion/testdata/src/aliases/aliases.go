@@ -0,0 +1,42 @@
+package aliases
+
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Square\] \[\]\}`
+	isShape()
+}
+
+type Circle struct{ R float64 }
+type Square struct{ S float64 }
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+// Figure is an alias of the union, and Round an alias of a member, which
+// is not a member of its own.
+type (
+	Figure = Shape
+	Round  = Circle
+)
+
+// ===========================================
+// Test Cases: switches on an alias of a union
+// ===========================================
+
+// Area - OK: the case on the alias Round covers *Circle
+func Area(f Figure) float64 {
+	switch f := f.(type) {
+	case *Round:
+		return 3 * f.R * f.R
+	case *Square:
+		return f.S * f.S
+	}
+	return 0
+}
+
+// Name - NG: switches on an alias are checked as switches on the union
+func Name(f Figure) string {
+	switch f.(type) { // want `missing cases in type switch on Shape: aliases\.\*Square`
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
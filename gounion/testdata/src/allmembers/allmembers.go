@@ -0,0 +1,36 @@
+package allmembers
+
+import "union"
+
+//gounion:all-members
+var fixtures = []union.Shape{ // want "missing members in Shape literal: union.\\*Triangle"
+	&union.Circle{},
+	&union.Rectangle{},
+}
+
+//gounion:all-members
+var complete = []union.Shape{
+	&union.Circle{},
+	&union.Rectangle{},
+	&union.Triangle{},
+}
+
+var registry = map[string]union.Result{ //gounion:all-members // want "missing members in Result literal: union.\\*Success"
+	"error": &union.Error{},
+}
+
+// Literals without the directive are not checked.
+var partial = []union.Shape{
+	&union.Circle{},
+}
+
+//gounion:all-members
+var numbers = []int{1, 2, 3} // want "//gounion:all-members directive on a literal whose elements are not a union"
+
+// Maps keyed by a union have their keys checked.
+//
+//gounion:all-members
+var transitions = map[union.Shape][]union.Shape{ // want "missing members in Shape literal: union.\\*Rectangle"
+	&union.Circle{}:   {&union.Triangle{}},
+	&union.Triangle{}: nil,
+}
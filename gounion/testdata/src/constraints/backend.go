@@ -0,0 +1,28 @@
+package constraints
+
+type Backend interface { // want Backend:`&\{isBackend \[\*Disk (\*Fake )?\*Memory\] \[!gounion_nodisk (_test )?\]\}`
+	isBackend()
+}
+
+type Memory struct{}
+
+func (*Memory) isBackend() {}
+
+// Disk is only a member when gounion_nodisk is not set, so switches in
+// unconstrained files cannot rely on it existing.
+func describe(b Backend) string {
+	switch b.(type) { // want "type switch on Backend has no default case for members unavailable under this file's build constraints: constraints.\\*Disk"
+	case *Memory:
+		return "memory"
+	}
+	return ""
+}
+
+func describeWithDefault(b Backend) string {
+	switch b.(type) {
+	case *Memory:
+		return "memory"
+	default:
+		return "other"
+	}
+}
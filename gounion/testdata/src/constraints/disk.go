@@ -0,0 +1,16 @@
+//go:build !gounion_nodisk
+
+package constraints
+
+type Disk struct{}
+
+func (*Disk) isBackend() {}
+
+// Under this file's constraints Disk is always a member.
+func describeDisk(b Backend) string {
+	switch b.(type) { // want "missing cases in type switch on Backend: constraints.\\*Disk"
+	case *Memory:
+		return "memory"
+	}
+	return ""
+}
@@ -0,0 +1,17 @@
+package constraints
+
+type Fake struct{}
+
+func (*Fake) isBackend() {}
+
+// Test files see the test-only member.
+func describeFake(b Backend) string {
+	switch b.(type) { // want "missing cases in type switch on Backend: constraints.\\*Fake"
+	case *Memory:
+		return "memory"
+	case *Disk:
+		return "disk"
+	default:
+		panic("unreachable")
+	}
+}
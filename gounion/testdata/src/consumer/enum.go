@@ -0,0 +1,16 @@
+package consumer
+
+import "enum"
+
+// ===========================================
+// Test Cases: Switching on an enum from external package
+// ===========================================
+
+// IsCool - NG: Missing Red
+func IsCool(c enum.Color) bool {
+	switch c { // want "missing cases in switch on Color: enum.Red"
+	case enum.Green, enum.Blue:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package debuglog
+
+// Shape is a union.
+type Shape interface { // want Shape:`&\{isShape \[\*Circle\] \[\]\}`
+	isShape()
+}
+
+type Circle struct{}
+
+func (*Circle) isShape() {}
+
+// Exported has an exported marker, so it is not a union.
+type Exported interface {
+	IsExported()
+}
+
+// Ambiguous has two markers, which members must both implement.
+type Ambiguous interface { // want Ambiguous:`&\{isA \[\] \[\]\}`
+	isA()
+	isB()
+}
+
+// Empty is a union without members.
+type Empty interface { // want Empty:`&\{isEmpty \[\] \[\]\}`
+	isEmpty()
+}
+
+// Stringer has no marker method.
+type Stringer interface {
+	String() string
+}
+
+func switches(s Shape, e Exported, v any) {
+	switch s.(type) {
+	case *Circle:
+	default:
+	}
+
+	switch e.(type) {
+	}
+
+	switch v.(type) {
+	}
+}
@@ -0,0 +1,31 @@
+package defaultbody
+
+import "union"
+
+// ===========================================
+// Test Cases: custom default body (run with -require-default and -default-body)
+// ===========================================
+
+func unhandled(union string, v any) error {
+	return nil
+}
+
+// ProcessResult - the fix inserts the configured body, using the switch variable
+func ProcessResult(r union.Result) (string, error) {
+	switch r := r.(type) { // want `exhaustive type switch on Result has no default case`
+	case *union.Success:
+		return r.Value, nil
+	case *union.Error:
+		return "", nil
+	}
+	return "", nil
+}
+
+// ProcessShape - without a bound variable, the switched expression is used
+func ProcessShape(s union.Shape) (string, error) {
+	switch s.(type) { // want `exhaustive type switch on Shape has no default case`
+	case *union.Circle, *union.Rectangle, *union.Triangle:
+		return "shape", nil
+	}
+	return "", nil
+}
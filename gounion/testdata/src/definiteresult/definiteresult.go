@@ -0,0 +1,116 @@
+package definiteresult
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ===========================================
+// Test Cases: cases without a result (run with -definite-result)
+// ===========================================
+
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Square Point\] \[\]\}`
+	isShape()
+}
+
+type Circle struct{ R float64 }
+
+func (*Circle) isShape() {}
+
+type Square struct{ W float64 }
+
+func (*Square) isShape() {}
+
+type Point struct{}
+
+func (Point) isShape() {}
+
+// area - NG: the *Square case forgets its return
+func area(s Shape) float64 {
+	switch s := s.(type) {
+	case *Circle:
+		return math.Pi * s.R * s.R
+	case *Square: // want `case \*Square of type switch on Shape does not return, falling through to the return after the switch`
+		math.Pow(s.W, 2)
+	case Point:
+		return 0
+	}
+	return 0
+}
+
+// name - NG: the Point case neither returns nor assigns the result
+func name(s Shape) string {
+	var n string
+	switch s.(type) {
+	case *Circle:
+		n = "circle"
+	case *Square:
+		n = "square"
+	case Point: // want `case Point of type switch on Shape neither returns nor assigns n`
+		fmt.Println("point")
+	}
+	return n
+}
+
+// perimeter - NG: the empty default returns zero named results
+func perimeter(s Shape) (p float64, err error) {
+	switch s := s.(type) {
+	case *Circle:
+		p = 2 * math.Pi * s.R
+	case *Square:
+		if s.W < 0 {
+			return 0, errors.New("negative width")
+		} else {
+			p = 4 * s.W
+		}
+	case Point:
+		panic("no perimeter")
+	default: // want `default case of type switch on Shape neither returns nor assigns p or err`
+	}
+	return
+}
+
+// nested - OK: every case ends with a terminating statement
+func nested(s Shape, round bool) int {
+	switch s.(type) {
+	case *Circle:
+		if round {
+			return 1
+		} else {
+			return 2
+		}
+	case *Square:
+		for {
+		}
+	case Point:
+		switch {
+		case round:
+			return 3
+		default:
+			panic("unreachable")
+		}
+	}
+	return 0
+}
+
+// describe - OK: no result after the switch
+func describe(s Shape) {
+	switch s.(type) {
+	case *Circle:
+		fmt.Println("circle")
+	case *Square, Point:
+		fmt.Println("angular")
+	}
+}
+
+// sideEffects - OK: no case produces a result
+func sideEffects(s Shape) error {
+	switch s.(type) {
+	case *Circle:
+		fmt.Println("circle")
+	case *Square:
+	case Point:
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package discriminator
+
+// Event is a union whose members are encoded with their discriminator.
+// Archived was removed without being retired.
+type Event interface { // want Event:`&\{isEvent \[Created Deleted Moved Renamed Updated\] \[\]\}` `locked member Archived, with the discriminator "archived", was renamed or removed; restore it or retire it as "-Archived" in union Event`
+	isEvent()
+}
+
+type Created struct {
+	_  struct{} `discriminator:"created"`
+	ID string
+}
+
+type Updated struct { // want `Updated has the discriminator "created" of Created in union Event`
+	_  struct{} `discriminator:"created"`
+	ID string
+}
+
+type Deleted struct { // want `Deleted has an empty discriminator in union Event`
+	_ struct{} `discriminator:""`
+}
+
+type Renamed struct { // want `discriminator of Renamed changed from "renamed" to "Renamed" since it was locked in union Event`
+	Name string
+}
+
+// Moved is not locked yet, but reuses the discriminator of the retired Legacy.
+type Moved struct { // want `Moved has the discriminator "moved" of the retired member Legacy in union Event`
+	_ struct{} `discriminator:"moved"`
+}
+
+func (Created) isEvent() {}
+func (Updated) isEvent() {}
+func (Deleted) isEvent() {}
+func (Renamed) isEvent() {}
+func (Moved) isEvent()   {}
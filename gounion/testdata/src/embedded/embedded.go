@@ -0,0 +1,41 @@
+package embedded
+
+// Event is a union whose members are wrapped by other types.
+type Event interface { // want Event:`&\{isEvent \[\*Click \*Replayed Key\] \[\]\}`
+	isEvent()
+}
+
+type Click struct{ X, Y int }
+
+func (*Click) isEvent() {}
+
+type Key struct{ Code rune }
+
+func (Key) isEvent() {}
+
+// Logged and Timed only have the marker method through an embedded
+// member, so they are excluded with -exclude-embedded.
+type Logged struct {
+	*Click
+	Message string
+}
+
+type Timed struct {
+	Key
+	At int64
+}
+
+// Replayed declares the marker method itself, so it remains a member
+// even though it embeds one.
+type Replayed struct {
+	*Click
+}
+
+func (*Replayed) isEvent() {}
+
+func handle(e Event) {
+	switch e.(type) { // want `missing cases in type switch on Event: embedded\.\*Replayed`
+	case *Click:
+	case Key:
+	}
+}
@@ -0,0 +1,46 @@
+package enum
+
+// Color is an enum of its constants below.
+type Color int // want Color:`&\{\[Red Green Blue\]\}`
+
+//gounion:enum
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+
+// Crimson is not a member, but covers Red in switches.
+const Crimson = Red
+
+// Level is an enum declared by several const groups.
+type Level string // want Level:`&\{\[Debug Info Warn Error\]\}`
+
+//gounion:enum
+const (
+	Debug Level = "debug"
+	Info  Level = "info"
+)
+
+//gounion:enum
+const (
+	Warn  Level = "warn"
+	Error Level = "error"
+)
+
+// Size has constants, but is not an enum.
+type Size int
+
+const (
+	Small Size = iota
+	Large
+)
+
+//gounion:enum // want "//gounion:enum directive on constants without a common named type"
+const (
+	Mixed Color = iota
+	Other Size  = iota
+)
+
+//gounion:enum // want "//gounion:enum directive on constants without a common named type"
+const Untyped = 1
@@ -0,0 +1,84 @@
+package enum
+
+// ===========================================
+// Test Cases: Switches on enums
+// ===========================================
+
+// Name - OK: All members handled
+func Name(c Color) string {
+	switch c {
+	case Red:
+		return "red"
+	case Green:
+		return "green"
+	case Blue:
+		return "blue"
+	}
+	return ""
+}
+
+// Warm - NG: Missing Green and Blue
+func Warm(c Color) bool {
+	switch c { // want "missing cases in switch on Color: enum.Green, enum.Blue"
+	case Red:
+		return true
+	}
+	return false
+}
+
+// Alias - OK: Crimson has the value of Red
+func Alias(c Color) bool {
+	switch c {
+	case Crimson, Green:
+		return true
+	case Blue:
+	}
+	return false
+}
+
+// Literal - OK: Cases are compared by value
+func Literal(c Color) bool {
+	switch c {
+	case 0, 1, 2:
+		return true
+	}
+	return false
+}
+
+// Fallback - OK: Default case handles the rest
+func Fallback(c Color) bool {
+	switch c {
+	case Red:
+		return true
+	default:
+		return false
+	}
+}
+
+// Verbose - NG: Missing Error
+func Verbose(l Level) bool {
+	switch l { // want "missing cases in switch on Level: enum.Error"
+	case Debug, Info:
+		return true
+	case Warn:
+	}
+	return false
+}
+
+// Sizes - OK: Size is not an enum
+func Sizes(s Size) bool {
+	switch s {
+	case Small:
+		return true
+	}
+	return false
+}
+
+// Tagless - OK: Switches without a tag are not checked
+func Tagless(c Color) bool {
+	switch {
+	case c == Red:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package errorsas
+
+import (
+	"errors"
+	"fmt"
+)
+
+// StoreError is an error union.
+type StoreError interface { // want StoreError:`&\{isStoreError \[\*ConflictError \*NotFoundError \*TimeoutError\] \[\]\}`
+	error
+	isStoreError()
+}
+
+type NotFoundError struct{ Key string }
+type ConflictError struct{ Key string }
+type TimeoutError struct{}
+
+func (e *NotFoundError) Error() string { return "not found: " + e.Key }
+func (e *ConflictError) Error() string { return "conflict: " + e.Key }
+func (e *TimeoutError) Error() string  { return "timeout" }
+
+func (*NotFoundError) isStoreError() {}
+func (*ConflictError) isStoreError() {}
+func (*TimeoutError) isStoreError()  {}
+
+// status - NG: TimeoutError is not handled
+func status(err error) int {
+	var notFound *NotFoundError
+	var conflict *ConflictError
+	if errors.As(err, &notFound) { // want `missing errors.As branches on err for StoreError: errorsas\.\*TimeoutError`
+		return 404
+	} else if errors.As(err, &conflict) {
+		return 409
+	}
+	return 500
+}
+
+// statusComplete - OK: every member has a branch
+func statusComplete(err error) int {
+	var notFound *NotFoundError
+	var conflict *ConflictError
+	var timeout *TimeoutError
+	if errors.As(err, &notFound) {
+		return 404
+	} else if errors.As(err, &conflict) {
+		return 409
+	} else if errors.As(err, &timeout) {
+		return 504
+	}
+	return 500
+}
+
+// statusElse - OK: the final else handles the other members
+func statusElse(err error) int {
+	var notFound *NotFoundError
+	var conflict *ConflictError
+	if errors.As(err, &notFound) {
+		return 404
+	} else if errors.As(err, &conflict) {
+		return 409
+	} else {
+		return 500
+	}
+}
+
+// single - OK: a single errors.As is not a dispatch chain
+func single(err error) bool {
+	var notFound *NotFoundError
+	return errors.As(err, &notFound)
+}
+
+// mixed - OK: chains with other conditions are not checked
+func mixed(err error, verbose bool) string {
+	var notFound *NotFoundError
+	var conflict *ConflictError
+	if errors.As(err, &notFound) {
+		return "not found"
+	} else if verbose {
+		return fmt.Sprint(err)
+	} else if errors.As(err, &conflict) {
+		return "conflict"
+	}
+	return ""
+}
+
+// Op is a union whose members are not errors.
+type Op interface { // want Op:`&\{isOp \[\*Get \*Put\] \[\]\}`
+	isOp()
+}
+
+type Get struct{}
+type Put struct{}
+
+func (*Get) isOp()         {}
+func (*Put) isOp()         {}
+func (*Get) Error() string { return "get" }
+
+// notErrorUnion - OK: Op has a member that is not an error
+func notErrorUnion(err error) {
+	var get *Get
+	var other *NotFoundError
+	if errors.As(err, &get) {
+	} else if errors.As(err, &other) {
+	}
+}
@@ -0,0 +1,13 @@
+// Code produced by a legacy tool, without a generated-code header.
+
+//gounion:file-ignore // legacy, see the migration plan
+
+package fileignore
+
+func perimeter(s Shape) int {
+	switch s.(type) {
+	case *Circle:
+		return 1
+	}
+	return 0
+}
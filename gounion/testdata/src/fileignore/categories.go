@@ -0,0 +1,14 @@
+//gounion:file-ignore literal
+
+package fileignore
+
+//gounion:all-members
+var fixtures = []Shape{&Circle{}} // suppressed
+
+func name(s Shape) string {
+	switch s.(type) { // want "missing cases in type switch on Shape: fileignore.\\*Square"
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
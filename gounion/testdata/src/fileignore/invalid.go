@@ -0,0 +1,13 @@
+//gounion:file-ignore switches // want `unknown category "switches" in //gounion:file-ignore directive \(want one of switch, enum, match, errors-as, literal, frozen, marker-name, max-members, shared-members, identical-unions, summary, result, discriminator, ignore\)`
+
+package fileignore
+
+//gounion:file-ignore // want "//gounion:file-ignore directive must precede the package clause"
+
+func sides(s Shape) int {
+	switch s.(type) { // want "missing cases in type switch on Shape: fileignore.\\*Square"
+	case *Circle:
+		return 0
+	}
+	return 4
+}
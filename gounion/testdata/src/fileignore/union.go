@@ -0,0 +1,19 @@
+package fileignore
+
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Square\] \[\]\}`
+	isShape()
+}
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+func area(s Shape) int {
+	switch s.(type) { // want "missing cases in type switch on Shape: fileignore.\\*Square"
+	case *Circle:
+		return 1
+	}
+	return 0
+}
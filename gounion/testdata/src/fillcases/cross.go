@@ -0,0 +1,12 @@
+package fillcases
+
+import "fillcases/shape"
+
+// CrossPackage - NG: no cases; the variable named after the union would
+// shadow the package shape, which the default case uses
+func CrossPackage() error {
+	switch shape.Current().(type) { // want `missing cases in type switch on Shape: shape\.\*Circle, shape\.\*Square`
+	default:
+		return shape.ErrUnknown
+	}
+}
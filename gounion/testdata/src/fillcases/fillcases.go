@@ -0,0 +1,60 @@
+package fillcases
+
+// Shape is a union of shapes.
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Square\] \[\]\}`
+	isShape()
+}
+
+type Circle struct{ Radius float64 }
+type Square struct{ Side float64 }
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+func current() Shape { return &Circle{} }
+
+// Empty - NG: no cases, the fix binds the switched variable
+func Empty(s Shape) {
+	switch s.(type) { // want `missing cases in type switch on Shape: fillcases\.\*Circle, fillcases\.\*Square`
+	}
+}
+
+// Call - NG: no cases, the fix binds a variable named after the union
+func Call() {
+	switch current().(type) { // want `missing cases in type switch on Shape: fillcases\.\*Circle, fillcases\.\*Square`
+	}
+}
+
+// BoundUsed - NG: only a default using the bound variable
+func BoundUsed(s Shape) {
+	switch v := s.(type) { // want `missing cases in type switch on Shape: fillcases\.\*Circle, fillcases\.\*Square`
+	default:
+		panic(v)
+	}
+}
+
+// Partial - NG: some cases, no fix filling the switch
+func Partial(s Shape) {
+	switch s.(type) { // want `missing cases in type switch on Shape: fillcases\.\*Square`
+	case *Circle:
+	}
+}
+
+// Type is a union whose name is a keyword once lower cased.
+type Type interface { // want Type:`&\{isType \[\*Basic \*Named\] \[\]\}`
+	isType()
+}
+
+type Basic struct{}
+type Named struct{}
+
+func (*Basic) isType() {}
+func (*Named) isType() {}
+
+func currentType() Type { return &Basic{} }
+
+// Keyword - NG: no cases, the fix binds type_ rather than the keyword
+func Keyword() {
+	switch currentType().(type) { // want `missing cases in type switch on Type: fillcases\.\*Basic, fillcases\.\*Named`
+	}
+}
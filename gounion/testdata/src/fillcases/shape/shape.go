@@ -0,0 +1,20 @@
+package shape
+
+import "errors"
+
+// Shape is a union of shapes, switched on by fillcases.
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Square\] \[\]\}`
+	isShape()
+}
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+// ErrUnknown is returned for shapes not handled.
+var ErrUnknown = errors.New("unknown shape")
+
+// Current returns the current shape.
+func Current() Shape { return &Circle{} }
@@ -0,0 +1,34 @@
+package frozen
+
+// Message is serialized with a fixed set of variants.
+//
+//gounion:frozen Ping, Pong
+type Message interface { // want Message:`&\{isMessage \[\*Ping \*Pong \*Reset\] \[\]\}`
+	isMessage()
+}
+
+type Ping struct{}
+type Pong struct{}
+type Reset struct{} // want "Reset implements frozen union Message; add it to the //gounion:frozen directive to unfreeze it"
+
+func (*Ping) isMessage()  {}
+func (*Pong) isMessage()  {}
+func (*Reset) isMessage() {}
+
+// Status is frozen without listing its members.
+//
+//gounion:frozen
+type Status interface { // want Status:`&\{isStatus \[Active Closed\] \[\]\}` "//gounion:frozen directive on Status lists no members"
+	isStatus()
+}
+
+type Active struct{}
+type Closed struct{}
+
+func (Active) isStatus() {}
+func (Closed) isStatus() {}
+
+//gounion:frozen Plain
+type Plain interface { // want "//gounion:frozen directive on Plain, which is not a union"
+	String() string
+}
@@ -0,0 +1,35 @@
+// Package gounionrt is a stub of the runtime helpers for tests.
+package gounionrt
+
+import (
+	"context"
+	"errors"
+)
+
+func Match2[S any, A, B any, R any](v S, a func(A) R, b func(B) R) R {
+	panic("stub")
+}
+
+func Match3[S any, A, B, C any, R any](v S, a func(A) R, b func(B) R, c func(C) R) R {
+	panic("stub")
+}
+
+func MustHandle[R any, S any](v S) R {
+	panic("stub")
+}
+
+type Handler[S any] struct{}
+
+func Handle[S any, M any](fn func(M) error) Handler[S] {
+	panic("stub")
+}
+
+type Dispatcher[S any] struct{}
+
+func NewDispatcher[S any](handlers ...Handler[S]) *Dispatcher[S] {
+	panic("stub")
+}
+
+func ReportUnhandled[S any](ctx context.Context, v S) {}
+
+var ErrUnhandledMember = errors.New("gounionrt: unhandled union member")
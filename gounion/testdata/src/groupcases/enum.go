@@ -0,0 +1,20 @@
+package groupcases
+
+type Weekday int // want Weekday:`&\{\[Monday Tuesday Saturday Sunday\]\}`
+
+//gounion:enum
+const (
+	Monday Weekday = iota
+	Tuesday
+	Saturday
+	Sunday
+)
+
+func weekend(d Weekday) bool {
+	switch d { // want "missing case in switch on Weekday: groupcases.Tuesday"
+	case Saturday, Sunday:
+		return true
+	case Monday:
+	}
+	return false
+}
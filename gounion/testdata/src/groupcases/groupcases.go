@@ -0,0 +1,31 @@
+package groupcases
+
+type Token interface { // want Token:`&\{isToken \[Comma Ident Number String\] \[\]\}`
+	isToken()
+}
+
+type Comma struct{}
+type Ident struct{}
+type Number struct{}
+type String struct{}
+
+func (Comma) isToken()  {}
+func (Ident) isToken()  {}
+func (Number) isToken() {}
+func (String) isToken() {}
+
+func literal(t Token) bool {
+	switch t.(type) { // want "missing case in type switch on Token: groupcases.Comma" "missing case in type switch on Token: groupcases.String"
+	case Ident, Number:
+		return true
+	}
+	return false
+}
+
+func punctuation(t Token) bool {
+	switch t.(type) { // want "missing case in type switch on Token: groupcases.Ident" "missing case in type switch on Token: groupcases.Number" "missing case in type switch on Token: groupcases.String"
+	case Comma:
+		return true
+	}
+	return false
+}
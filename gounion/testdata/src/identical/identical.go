@@ -0,0 +1,35 @@
+package identical
+
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Square\] \[\]\}`
+	isShape()
+}
+
+// Figure duplicates Shape.
+type Figure interface { // want Figure:`&\{isFigure \[\*Circle \*Square\] \[\]\}` "union Figure has the same members as Shape \\(\\*Circle, \\*Square\\); consider merging them or declaring one as an alias of the other"
+	isFigure()
+}
+
+// Drawable shares only some members with Shape.
+type Drawable interface { // want Drawable:`&\{isDrawable \[\*Circle \*Text\] \[\]\}`
+	isDrawable()
+}
+
+// Empty has no members, like Unused.
+type Empty interface { // want Empty:`&\{isEmpty \[\] \[\]\}`
+	isEmpty()
+}
+
+type Unused interface { // want Unused:`&\{isUnused \[\] \[\]\}`
+	isUnused()
+}
+
+type Circle struct{}
+type Square struct{}
+type Text struct{}
+
+func (*Circle) isShape()    {}
+func (*Circle) isFigure()   {}
+func (*Circle) isDrawable() {}
+func (*Square) isShape()    {}
+func (*Square) isFigure()   {}
+func (*Text) isDrawable()   {}
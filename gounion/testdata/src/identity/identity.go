@@ -0,0 +1,34 @@
+package identity
+
+import "union"
+
+// Triangle wraps union.Triangle; it implements union.Shape through
+// embedding, but is a different type from the member union.Triangle.
+type Triangle struct {
+	*union.Triangle
+}
+
+// Round is an alias of a member.
+type Round = union.Circle
+
+// Area - NG: the local Triangle does not handle union.Triangle
+func Area(s union.Shape) float64 {
+	switch s.(type) { // want "missing cases in type switch on Shape: union.\\*Triangle"
+	case *Round:
+		return 1
+	case *union.Rectangle:
+		return 2
+	case *Triangle:
+		return 3
+	}
+	return 0
+}
+
+// Perimeter - OK: cases through aliases are matched to the aliased member
+func Perimeter(s union.Shape) float64 {
+	switch s.(type) {
+	case *Round, *union.Rectangle, *union.Triangle:
+		return 1
+	}
+	return 0
+}
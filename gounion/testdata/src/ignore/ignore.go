@@ -0,0 +1,61 @@
+package ignore
+
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Square\] \[\]\}`
+	isShape()
+}
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+func area(s Shape) int {
+	//gounion:ignore
+	switch s.(type) {
+	case *Circle:
+		return 1
+	}
+	return 0
+}
+
+func sides(s Shape) int {
+	switch s.(type) { //gounion:ignore reason=only circles reach this point
+	case *Circle:
+		return 0
+	}
+	return 4
+}
+
+func name(s Shape) string {
+	//gounion:ignore until=2999-12-31 reason=squares are named in v2
+	switch s.(type) {
+	case *Circle:
+		return "circle"
+	}
+
+	//gounion:ignore until=2001-01-31 reason=squares are named in v2 // want `//gounion:ignore directive expired on 2001-01-31: squares are named in v2`
+	switch s.(type) { // want "missing cases in type switch on Shape: ignore.\\*Square"
+	case *Circle:
+		return "circle"
+	}
+
+	//gounion:ignore until=2001-01-31 // want `//gounion:ignore directive expired on 2001-01-31$`
+	switch s.(type) { // want "missing cases in type switch on Shape: ignore.\\*Square"
+	case *Circle:
+		return "circle"
+	}
+
+	//gounion:ignore until=31/12/2999 // want `invalid date "31/12/2999" in //gounion:ignore directive \(want YYYY-MM-DD\)`
+	switch s.(type) { // want "missing cases in type switch on Shape: ignore.\\*Square"
+	case *Circle:
+		return "circle"
+	}
+
+	//gounion:ignore squares are named in v2 // want `unknown option "squares" in //gounion:ignore directive \(want until= or reason=\)`
+	switch s.(type) { // want "missing cases in type switch on Shape: ignore.\\*Square"
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
@@ -0,0 +1,33 @@
+package ignoredate
+
+// ===========================================
+// Test Cases: //gounion:ignore expiry on a fixed date (run with
+// -ignore-date=2030-06-30)
+// ===========================================
+
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Square\] \[\]\}`
+	isShape()
+}
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+func name(s Shape) string {
+	// OK: expires after the given date
+	//gounion:ignore until=2030-07-01 reason=squares are named in v2
+	switch s.(type) {
+	case *Circle:
+		return "circle"
+	}
+
+	// NG: expired before the given date, though not yet today
+	//gounion:ignore until=2030-06-01 reason=squares are named in v2 // want `//gounion:ignore directive expired on 2030-06-01: squares are named in v2`
+	switch s.(type) { // want "missing cases in type switch on Shape: ignoredate.\\*Square"
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
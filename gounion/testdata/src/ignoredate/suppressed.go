@@ -0,0 +1,12 @@
+//gounion:file-ignore ignore // expired directives are tracked elsewhere
+
+package ignoredate
+
+func sides(s Shape) int {
+	//gounion:ignore until=2030-06-01
+	switch s.(type) { // want "missing cases in type switch on Shape: ignoredate.\\*Square"
+	case *Circle:
+		return 0
+	}
+	return 4
+}
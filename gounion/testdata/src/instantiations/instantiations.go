@@ -0,0 +1,30 @@
+package instantiations
+
+import "generic"
+
+// ===========================================
+// Test Cases: per-instantiation matching (run with -match-instantiations)
+// ===========================================
+
+// Get - NG: Some[int] does not cover every instantiation of Some
+func Get(o generic.Option) int {
+	switch o := o.(type) { // want "missing cases in type switch on Option: generic.\\*Some"
+	case *generic.Some[int]:
+		return o.Value
+	case *generic.None:
+		return 0
+	}
+	return 0
+}
+
+// GetWithDefault - OK: the default handles the other instantiations
+func GetWithDefault(o generic.Option) int {
+	switch o := o.(type) {
+	case *generic.Some[int]:
+		return o.Value
+	case *generic.None:
+		return 0
+	default:
+		return -1
+	}
+}
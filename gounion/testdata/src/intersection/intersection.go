@@ -0,0 +1,55 @@
+package intersection
+
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Line \*Square\] \[\]\}`
+	isShape()
+}
+
+type Serializable interface { // want Serializable:`&\{isSerializable \[\*Blob \*Circle \*Square\] \[\]\}`
+	isSerializable()
+}
+
+// Stored values are both shapes and serializable: *Circle or *Square.
+type Stored interface {
+	Shape
+	Serializable
+}
+
+type Circle struct{}
+
+func (*Circle) isShape()        {}
+func (*Circle) isSerializable() {}
+
+type Square struct{}
+
+func (*Square) isShape()        {}
+func (*Square) isSerializable() {}
+
+type Line struct{}
+
+func (*Line) isShape() {}
+
+type Blob struct{}
+
+func (*Blob) isSerializable() {}
+
+func load(s Stored) {
+	switch s.(type) { // want "missing cases in type switch on Stored: intersection.\\*Square"
+	case *Circle:
+	}
+}
+
+func save(s Stored) {
+	switch s.(type) {
+	case *Circle:
+	case *Square:
+	}
+}
+
+func encode(v interface {
+	Shape
+	Serializable
+}) {
+	switch v.(type) { // want "missing cases in type switch on Shape & Serializable: intersection.\\*Circle"
+	case *Square:
+	}
+}
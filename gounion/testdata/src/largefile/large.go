@@ -0,0 +1,56 @@
+package largefile
+
+// This file exceeds the line threshold used in the test, so switches in it
+// are not checked. Union interfaces declared here are still detected.
+
+// Polygon is a union type declared in a large file.
+type Polygon interface { // want Polygon:`&\{isPolygon \[\*Pentagon \*Square\] \[\]\}`
+	isPolygon()
+}
+
+type Pentagon struct{}
+
+type Square struct{}
+
+func (*Pentagon) isPolygon() {}
+func (*Square) isPolygon()   {}
+
+// DescribeLarge - OK: Missing Square case, but the file is skipped
+func DescribeLarge(p Polygon) string {
+	switch p.(type) {
+	case *Pentagon:
+		return "pentagon"
+	}
+	return ""
+}
+
+// padding line 1
+// padding line 2
+// padding line 3
+// padding line 4
+// padding line 5
+// padding line 6
+// padding line 7
+// padding line 8
+// padding line 9
+// padding line 10
+// padding line 11
+// padding line 12
+// padding line 13
+// padding line 14
+// padding line 15
+// padding line 16
+// padding line 17
+// padding line 18
+// padding line 19
+// padding line 20
+// padding line 21
+// padding line 22
+// padding line 23
+// padding line 24
+// padding line 25
+// padding line 26
+// padding line 27
+// padding line 28
+// padding line 29
+// padding line 30
@@ -0,0 +1,10 @@
+package largefile
+
+// DescribeSmall - NG: Missing Square case, file is below the threshold
+func DescribeSmall(p Polygon) string {
+	switch p.(type) { // want `missing cases in type switch on Polygon: largefile\.\*Square`
+	case *Pentagon:
+		return "pentagon"
+	}
+	return ""
+}
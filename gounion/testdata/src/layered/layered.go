@@ -0,0 +1,47 @@
+package layered
+
+// Node is sealed by isNode.
+type Node interface { // want Node:`&\{isNode \[\*Call \*Ident \*Return\] \[\]\}`
+	isNode()
+}
+
+// Expr adds a second marker to Node: its members must implement both.
+type Expr interface { // want Expr:`&\{isExpr \[\*Call \*Ident\] \[\]\}`
+	Node
+	isExpr()
+}
+
+type Ident struct{ Name string }
+type Call struct{ Fun Expr }
+type Return struct{ Value Expr }
+
+// Orphan implements isExpr but not isNode, so it is no member of Expr.
+type Orphan struct{}
+
+func (*Ident) isNode()  {}
+func (*Call) isNode()   {}
+func (*Return) isNode() {}
+
+func (*Ident) isExpr()  {}
+func (*Call) isExpr()   {}
+func (*Orphan) isExpr() {}
+
+// eval - OK: every Expr member is handled
+func eval(e Expr) string {
+	switch e := e.(type) {
+	case *Ident:
+		return e.Name
+	case *Call:
+		return eval(e.Fun) + "()"
+	}
+	return ""
+}
+
+// name - NG: Call is not handled
+func name(e Expr) string {
+	switch e := e.(type) { // want `missing cases in type switch on Expr: layered\.\*Call`
+	case *Ident:
+		return e.Name
+	}
+	return ""
+}
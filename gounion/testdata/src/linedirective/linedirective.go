@@ -0,0 +1,28 @@
+package linedirective
+
+import "union"
+
+// ===========================================
+// Test Cases: //line directives (run with -line-directives)
+// ===========================================
+
+// ProcessResult - NG: Missing Error case, not affected by a //line directive
+func ProcessResult(r union.Result) string {
+	switch r.(type) { // want `missing cases in type switch on Result: union\.\*Error$`
+	case *union.Success:
+		return "success"
+	}
+	return ""
+}
+
+// DrawShape - NG: Missing Rectangle and Triangle cases, reported in the
+// template.
+//
+//line shapes.templ:10
+func DrawShape(s union.Shape) string {
+	switch s.(type) { // want `missing cases in type switch on Shape: union\.\*Rectangle, union\.\*Triangle$`
+	case *union.Circle:
+		return "circle"
+	}
+	return ""
+}
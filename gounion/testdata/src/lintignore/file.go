@@ -0,0 +1,11 @@
+package lintignore
+
+//lint:file-ignore gounion generated by a legacy tool
+
+func perimeter(s Shape) int {
+	switch s.(type) {
+	case *Circle:
+		return 1
+	}
+	return 0
+}
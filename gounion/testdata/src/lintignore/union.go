@@ -0,0 +1,58 @@
+package lintignore
+
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Square\] \[\]\}`
+	isShape()
+}
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+func area(s Shape) int {
+	//lint:ignore gounion squares are measured elsewhere
+	switch s.(type) {
+	case *Circle:
+		return 1
+	}
+	return 0
+}
+
+func sides(s Shape) int {
+	switch s.(type) { //lint:ignore SA9003,gounion only circles reach this point
+	case *Circle:
+		return 0
+	}
+	return 4
+}
+
+func name(s Shape) string {
+	//lint:ignore goun* legacy
+	switch s.(type) {
+	case *Circle:
+		return "circle"
+	}
+
+	//lint:ignore SA4006 another check
+	switch s.(type) { // want "missing cases in type switch on Shape: lintignore.\\*Square"
+	case *Circle:
+		return "circle"
+	}
+
+	//lint:ignore gounion
+	switch s.(type) { // want "missing cases in type switch on Shape: lintignore.\\*Square"
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
+
+func corners(s Shape) int {
+	t := s            //lint:ignore gounion a trailing directive only applies to its own line
+	switch t.(type) { // want "missing cases in type switch on Shape: lintignore.\\*Square"
+	case *Circle:
+		return 0
+	}
+	return 4
+}
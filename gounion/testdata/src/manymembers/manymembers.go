@@ -0,0 +1,42 @@
+package manymembers
+
+// Op is a union with more members than diagnostics list.
+type Op interface { // want Op:`&\{isOp \[Add Div Mod Mul Neg Not Shl Sub\] \[\]\}`
+	isOp()
+}
+
+type Add struct{}
+type Sub struct{}
+type Mul struct{}
+type Div struct{}
+type Mod struct{}
+type Neg struct{}
+type Not struct{}
+type Shl struct{}
+
+func (Add) isOp() {}
+func (Sub) isOp() {}
+func (Mul) isOp() {}
+func (Div) isOp() {}
+func (Mod) isOp() {}
+func (Neg) isOp() {}
+func (Not) isOp() {}
+func (Shl) isOp() {}
+
+// eval - NG: seven members are missing, five are listed
+func eval(op Op) int {
+	switch op.(type) { // want `missing cases in type switch on Op: manymembers\.Div, manymembers\.Mod, manymembers\.Mul, manymembers\.Neg, manymembers\.Not, \+2 more$`
+	case Add:
+		return 1
+	}
+	return 0
+}
+
+// unary - NG: exactly five members are missing, all are listed
+func unary(op Op) int {
+	switch op.(type) { // want `missing cases in type switch on Op: manymembers\.Div, manymembers\.Mod, manymembers\.Mul, manymembers\.Shl, manymembers\.Sub$`
+	case Add, Neg, Not:
+		return 1
+	}
+	return 0
+}
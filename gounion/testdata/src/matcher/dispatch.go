@@ -0,0 +1,38 @@
+package matcher
+
+import (
+	"union"
+
+	"github.com/YuitoSato/gounion/gounionrt"
+)
+
+// ===========================================
+// Test Cases: gounionrt.NewDispatcher registrations
+// ===========================================
+
+// NewResultDispatcher - NG: Missing Error handler
+func NewResultDispatcher() *gounionrt.Dispatcher[union.Result] {
+	return gounionrt.NewDispatcher( // want `missing handlers in gounionrt.NewDispatcher on Result: union\.\*Error`
+		gounionrt.Handle[union.Result](func(*union.Success) error { return nil }),
+	)
+}
+
+// NewResultDispatcherComplete - OK: All members handled
+func NewResultDispatcherComplete() *gounionrt.Dispatcher[union.Result] {
+	return gounionrt.NewDispatcher(
+		gounionrt.Handle[union.Result](func(*union.Success) error { return nil }),
+		gounionrt.Handle[union.Result](func(*union.Error) error { return nil }),
+	)
+}
+
+// NewShapeDispatcherExplicit - NG: Explicit type argument, missing Rectangle and Triangle
+func NewShapeDispatcherExplicit() *gounionrt.Dispatcher[union.Shape] {
+	return gounionrt.NewDispatcher[union.Shape]( // want `missing handlers in gounionrt.NewDispatcher on Shape: union\.\*Rectangle, union\.\*Triangle`
+		gounionrt.Handle[union.Shape](func(*union.Circle) error { return nil }),
+	)
+}
+
+// NewShapeDispatcherSpread - OK: Handlers built elsewhere can't be checked
+func NewShapeDispatcherSpread(handlers []gounionrt.Handler[union.Shape]) *gounionrt.Dispatcher[union.Shape] {
+	return gounionrt.NewDispatcher(handlers...)
+}
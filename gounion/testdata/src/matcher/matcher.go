@@ -0,0 +1,52 @@
+package matcher
+
+import (
+	"union"
+
+	"github.com/YuitoSato/gounion/gounionrt"
+)
+
+// ===========================================
+// Test Cases: gounionrt.MatchN helpers
+// ===========================================
+
+// AreaMatch - NG: Missing Triangle function
+func AreaMatch(s union.Shape) float64 {
+	return gounionrt.Match2(s, // want `missing cases in gounionrt.Match2 on Shape: union\.\*Triangle`
+		func(c *union.Circle) float64 { return 3.14 * c.Radius * c.Radius },
+		func(r *union.Rectangle) float64 { return r.Width * r.Height },
+	)
+}
+
+// AreaMatchComplete - OK: All members covered
+func AreaMatchComplete(s union.Shape) float64 {
+	return gounionrt.Match3(s,
+		func(c *union.Circle) float64 { return 3.14 * c.Radius * c.Radius },
+		func(r *union.Rectangle) float64 { return r.Width * r.Height },
+		func(t *union.Triangle) float64 { return 0.5 * t.Base * t.Height },
+	)
+}
+
+// AreaMatchExplicit - NG: Explicit type arguments, missing Rectangle and Triangle
+func AreaMatchExplicit(s union.Shape) float64 {
+	return gounionrt.Match2[union.Shape, *union.Circle, *union.Circle, float64](s, // want `missing cases in gounionrt.Match2 on Shape: union\.\*Rectangle, union\.\*Triangle`
+		func(c *union.Circle) float64 { return 0 },
+		func(c *union.Circle) float64 { return 0 },
+	)
+}
+
+// ResultMatch - OK: All members covered
+func ResultMatch(r union.Result) string {
+	return gounionrt.Match2(r,
+		func(*union.Success) string { return "success" },
+		func(*union.Error) string { return "error" },
+	)
+}
+
+// AnyMatch - OK: Not a union interface
+func AnyMatch(v any) string {
+	return gounionrt.Match2(v,
+		func(int) string { return "int" },
+		func(string) string { return "string" },
+	)
+}
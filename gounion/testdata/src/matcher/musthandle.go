@@ -0,0 +1,48 @@
+package matcher
+
+import (
+	"union"
+
+	"github.com/YuitoSato/gounion/gounionrt"
+)
+
+// ===========================================
+// Test Cases: gounionrt.MustHandle in default
+// ===========================================
+
+// AreaMustHandle - NG: default returns MustHandle, missing Triangle
+func AreaMustHandle(s union.Shape) float64 {
+	switch s := s.(type) { // want `missing cases in type switch on Shape: union\.\*Triangle`
+	case *union.Circle:
+		return 3.14 * s.Radius * s.Radius
+	case *union.Rectangle:
+		return s.Width * s.Height
+	default:
+		return gounionrt.MustHandle[float64](s)
+	}
+}
+
+// DescribeMustHandle - NG: default calls MustHandle as a statement, missing Error
+func DescribeMustHandle(r union.Result) string {
+	switch r.(type) { // want `missing cases in type switch on Result: union\.\*Error`
+	case *union.Success:
+		return "success"
+	default:
+		gounionrt.MustHandle[struct{}](r)
+	}
+	return ""
+}
+
+// AreaMustHandleComplete - OK: All cases covered, default returns MustHandle
+func AreaMustHandleComplete(s union.Shape) float64 {
+	switch s := s.(type) {
+	case *union.Circle:
+		return 3.14 * s.Radius * s.Radius
+	case *union.Rectangle:
+		return s.Width * s.Height
+	case *union.Triangle:
+		return 0.5 * s.Base * s.Height
+	default:
+		return gounionrt.MustHandle[float64](s)
+	}
+}
@@ -0,0 +1,23 @@
+package matcher
+
+import (
+	"context"
+	"union"
+
+	"github.com/YuitoSato/gounion/gounionrt"
+)
+
+// ===========================================
+// Test Cases: gounionrt.ReportUnhandled in default
+// ===========================================
+
+// AreaReportUnhandled - OK: default ends with ReportUnhandled, an acknowledged escape hatch
+func AreaReportUnhandled(ctx context.Context, s union.Shape) float64 {
+	switch s := s.(type) {
+	case *union.Circle:
+		return 3.14 * s.Radius * s.Radius
+	default:
+		gounionrt.ReportUnhandled(ctx, s)
+		return 0
+	}
+}
@@ -0,0 +1,42 @@
+package matcher
+
+import (
+	"fmt"
+	"union"
+
+	"github.com/YuitoSato/gounion/gounionrt"
+)
+
+// ===========================================
+// Test Cases: gounionrt.ErrUnhandledMember in default
+// ===========================================
+
+// AreaErrUnhandledMember - OK: default returns ErrUnhandledMember, an acknowledged escape hatch
+func AreaErrUnhandledMember(s union.Shape) (float64, error) {
+	switch s := s.(type) {
+	case *union.Circle:
+		return 3.14 * s.Radius * s.Radius, nil
+	default:
+		return 0, gounionrt.ErrUnhandledMember
+	}
+}
+
+// AreaWrappedErrUnhandledMember - OK: default wraps ErrUnhandledMember
+func AreaWrappedErrUnhandledMember(s union.Shape) (float64, error) {
+	switch s := s.(type) {
+	case *union.Circle:
+		return 3.14 * s.Radius * s.Radius, nil
+	default:
+		return 0, fmt.Errorf("area of %T: %w", s, gounionrt.ErrUnhandledMember)
+	}
+}
+
+// AreaOtherError - NG: default returns another error, a safety guard
+func AreaOtherError(s union.Shape) (float64, error) {
+	switch s := s.(type) { // want `missing cases in type switch on Shape: union\.\*Rectangle, union\.\*Triangle`
+	case *union.Circle:
+		return 3.14 * s.Radius * s.Radius, nil
+	default:
+		return 0, fmt.Errorf("area of %T: unknown shape", s)
+	}
+}
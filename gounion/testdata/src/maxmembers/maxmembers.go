@@ -0,0 +1,29 @@
+package maxmembers
+
+// Op has more members than allowed.
+type Op interface { // want Op:`&\{isOp \[Add Div Mul Sub\] \[\]\}` "union Op has 4 members, more than the maximum of 3; consider splitting it"
+	isOp()
+}
+
+type Add struct{}
+type Sub struct{}
+type Mul struct{}
+type Div struct{}
+
+func (Add) isOp() {}
+func (Sub) isOp() {}
+func (Mul) isOp() {}
+func (Div) isOp() {}
+
+// Bit has as many members as allowed.
+type Bit interface { // want Bit:`&\{isBit \[And Or Xor\] \[\]\}`
+	isBit()
+}
+
+type And struct{}
+type Or struct{}
+type Xor struct{}
+
+func (And) isBit() {}
+func (Or) isBit()  {}
+func (Xor) isBit() {}
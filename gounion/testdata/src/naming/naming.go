@@ -0,0 +1,28 @@
+package naming
+
+// Shape - NG: its marker should be named isShape
+type Shape interface { // want Shape:`&\{shape \[\*Circle Square\] \[\]\}` "marker method shape of union Shape should be named isShape"
+	shape()
+}
+
+type Circle struct{}
+
+func (*Circle) shape() {}
+
+type Square struct{}
+
+func (Square) shape() {}
+
+// mark calls the marker method, which is renamed as well.
+func mark(s Shape) {
+	s.shape()
+}
+
+// Token - OK: its marker follows the convention
+type Token interface { // want Token:`&\{isToken \[Ident\] \[\]\}`
+	isToken()
+}
+
+type Ident struct{}
+
+func (Ident) isToken() {}
@@ -0,0 +1,50 @@
+package overrides
+
+// ===========================================
+// Test Cases: per-union overrides
+// Run with -strict-default and -union=overrides.Event:strict-default=false
+// ===========================================
+
+// Command is a union checked in strict mode (the global option).
+type Command interface { // want Command:`&\{isCommand \[\*Start \*Stop\] \[\]\}`
+	isCommand()
+}
+
+type Start struct{}
+
+type Stop struct{}
+
+func (*Start) isCommand() {}
+func (*Stop) isCommand()  {}
+
+// Event is a union whose override allows defaults.
+type Event interface { // want Event:`&\{isEvent \[\*Created \*Deleted\] \[\]\}`
+	isEvent()
+}
+
+type Created struct{}
+
+type Deleted struct{}
+
+func (*Created) isEvent() {}
+func (*Deleted) isEvent() {}
+
+// HandleCommand - NG: strict default, missing Stop
+func HandleCommand(c Command) string {
+	switch c.(type) { // want `missing cases in type switch on Command: overrides\.\*Stop`
+	case *Start:
+		return "start"
+	default:
+		return "unknown"
+	}
+}
+
+// HandleEvent - OK: the override allows defaults for Event
+func HandleEvent(e Event) string {
+	switch e.(type) {
+	case *Created:
+		return "created"
+	default:
+		return "unknown"
+	}
+}
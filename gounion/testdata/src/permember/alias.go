@@ -0,0 +1,12 @@
+package permember
+
+import u2 "union"
+
+func describe(r u2.Result) string {
+	switch r.(type) { // want "missing case in type switch on Result: union.\\*Success"
+	case *u2.Error:
+		return "error"
+	default:
+		panic("unreachable")
+	}
+}
@@ -0,0 +1,18 @@
+package permember
+
+type Color int // want Color:`&\{\[Red Green Blue\]\}`
+
+//gounion:enum
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+
+func warm(c Color) bool {
+	switch c { // want "missing case in switch on Color: permember.Green" "missing case in switch on Color: permember.Blue"
+	case Red:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package permember
+
+import "union"
+
+func area(s union.Shape) float64 {
+	switch s := s.(type) { // want "missing case in type switch on Shape: union.\\*Rectangle" "missing case in type switch on Shape: union.\\*Triangle"
+	case *union.Circle:
+		return s.Radius
+	}
+	return 0
+}
+
+type Local interface { // want Local:`&\{isLocal \[\*B A\] \[\]\}`
+	isLocal()
+}
+
+type A struct{}
+
+func (A) isLocal() {}
+
+type B struct{}
+
+func (*B) isLocal() {}
+
+func local(l Local) {
+	switch l.(type) { // want "missing case in type switch on Local: permember.\\*B"
+	case A:
+	}
+}
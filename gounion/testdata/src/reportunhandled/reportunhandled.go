@@ -0,0 +1,26 @@
+package reportunhandled
+
+import (
+	"context"
+	"union"
+
+	"github.com/YuitoSato/gounion/gounionrt"
+)
+
+// ===========================================
+// Test Cases: gounionrt.ReportUnhandled in default
+// ===========================================
+
+// AreaReportUnhandled - default ends with ReportUnhandled, missing Triangle.
+// Accepted by default, reported with -check-report-unhandled.
+func AreaReportUnhandled(ctx context.Context, s union.Shape) float64 {
+	switch s := s.(type) { // want `missing cases in type switch on Shape: union\.\*Triangle`
+	case *union.Circle:
+		return 3.14 * s.Radius * s.Radius
+	case *union.Rectangle:
+		return s.Width * s.Height
+	default:
+		gounionrt.ReportUnhandled(ctx, s)
+		return 0
+	}
+}
@@ -0,0 +1,39 @@
+package requiredefault
+
+import "union"
+
+// ===========================================
+// Test Cases: require-default mode (run with -require-default)
+// ===========================================
+
+// ProcessResult - NG: Exhaustive but no default case
+func ProcessResult(r union.Result) string {
+	switch r.(type) { // want `exhaustive type switch on Result has no default case`
+	case *union.Success:
+		return "success"
+	case *union.Error:
+		return "error"
+	}
+	return ""
+}
+
+// ProcessResultWithDefault - OK: Exhaustive with a default case
+func ProcessResultWithDefault(r union.Result) string {
+	switch r.(type) {
+	case *union.Success:
+		return "success"
+	case *union.Error:
+		return "error"
+	default:
+		panic("unreachable")
+	}
+}
+
+// DrawShape - NG: Non-exhaustive switches are reported for the missing cases only
+func DrawShape(s union.Shape) string {
+	switch s.(type) { // want `missing cases in type switch on Shape: union\.\*Rectangle, union\.\*Triangle`
+	case *union.Circle:
+		return "circle"
+	}
+	return ""
+}
@@ -0,0 +1,12 @@
+package generated
+
+import "settings"
+
+// Name - OK: diagnostics in excluded packages are dropped
+func Name(c settings.Color) string {
+	switch c {
+	case settings.Red:
+		return "red"
+	}
+	return ""
+}
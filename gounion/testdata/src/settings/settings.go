@@ -0,0 +1,97 @@
+package settings
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Square\] \[\]\}`
+	isShape()
+}
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+type Color int // want Color:`&\{\[Red Green\]\}`
+
+//gounion:enum
+const (
+	Red Color = iota
+	Green
+)
+
+// Fatal - NG: log.Fatal is a terminator, so the default is a guard
+func Fatal(s Shape) string {
+	switch s.(type) { // want "missing cases in type switch on Shape: settings.\\*Square"
+	case *Circle:
+		return "circle"
+	default:
+		log.Fatalf("unhandled shape %T", s)
+	}
+	return ""
+}
+
+// Logger is a structured logger, whose Fatal methods are terminators.
+type Logger interface {
+	Info(msg string)
+	Fatal(msg string)
+}
+
+// FatalMethod - NG: Logger.Fatal is a terminator on an interface receiver
+func FatalMethod(s Shape, logger Logger) string {
+	switch s.(type) { // want "missing cases in type switch on Shape: settings.\\*Square"
+	case *Circle:
+		return "circle"
+	default:
+		logger.Fatal("unhandled shape")
+	}
+	return ""
+}
+
+// PanicMethod - NG: log.Logger.Panic* matches (*log.Logger).Panicf
+func PanicMethod(s Shape) string {
+	switch s.(type) { // want "missing cases in type switch on Shape: settings.\\*Square"
+	case *Circle:
+		return "circle"
+	default:
+		log.Default().Panicf("unhandled shape %T", s)
+	}
+	return ""
+}
+
+// InfoMethod - OK: Logger.Info is not a terminator
+func InfoMethod(s Shape, logger Logger) string {
+	switch s.(type) {
+	case *Circle:
+		return "circle"
+	default:
+		logger.Info("unhandled shape")
+	}
+	return ""
+}
+
+// Exit - OK: os.Exit is not a terminator
+func Exit(s Shape) string {
+	switch s.(type) {
+	case *Circle:
+		return "circle"
+	default:
+		os.Exit(1)
+	}
+	return ""
+}
+
+// Hue - NG: Terminators apply to enum switches too
+func Hue(c Color) int {
+	switch c { // want "missing cases in switch on Color: settings.Green"
+	case Red:
+		return 0
+	default:
+		log.Fatal(fmt.Sprint("unhandled color ", int(c)))
+	}
+	return -1
+}
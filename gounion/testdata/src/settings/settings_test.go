@@ -0,0 +1,10 @@
+package settings
+
+// area - OK: diagnostics in test files are skipped
+func area(s Shape) int {
+	switch s.(type) {
+	case *Circle:
+		return 3
+	}
+	return 0
+}
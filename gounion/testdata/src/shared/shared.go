@@ -0,0 +1,25 @@
+package shared
+
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Square\] \[\]\}`
+	isShape()
+}
+
+type Drawable interface { // want Drawable:`&\{isDrawable \[\*Circle \*Text\] \[\]\}`
+	isDrawable()
+}
+
+// Polygon is layered on Shape: its members are also members of Shape.
+type Polygon interface { // want Polygon:`&\{isPolygon \[\*Square\] \[\]\}`
+	Shape
+	isPolygon()
+}
+
+type Circle struct{} // want "Circle is a member of several unions: Drawable, Shape"
+type Square struct{}
+type Text struct{}
+
+func (*Circle) isShape()    {}
+func (*Circle) isDrawable() {}
+func (*Square) isShape()    {}
+func (*Square) isPolygon()  {}
+func (*Text) isDrawable()   {}
@@ -0,0 +1,36 @@
+package structural
+
+type Animal interface { // want Animal:`&\{isAnimal \[\*Cat \*Dog\] \[\]\}`
+	isAnimal()
+}
+
+type Cat struct{}
+
+func (*Cat) isAnimal() {}
+
+func (*Cat) Name() string { return "cat" }
+
+type Dog struct{}
+
+func (*Dog) isAnimal() {}
+
+// sound takes an interface literal identical to Animal.
+func sound(a interface{ isAnimal() }) string {
+	switch a.(type) { // want "missing cases in type switch on Animal: structural.\\*Dog"
+	case *Cat:
+		return "meow"
+	}
+	return ""
+}
+
+// Interfaces with other method sets are not matched.
+func name(a interface {
+	isAnimal()
+	Name() string
+}) string {
+	switch a.(type) {
+	case *Cat:
+		return "cat"
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package summary // want `3 of 4 union switches non-exhaustive; unions affected: Result, summaryshapes.Shape, union.Shape`
+
+import (
+	"summaryshapes"
+	"union"
+)
+
+// ===========================================
+// Test Cases: per-package summary (run with -summary)
+// ===========================================
+
+// ProcessResult - NG: Missing Error case
+func ProcessResult(r union.Result) string {
+	switch r.(type) { // want `missing cases in type switch on Result: union\.\*Error`
+	case *union.Success:
+		return "success"
+	}
+	return ""
+}
+
+// DrawShape - NG: Missing Rectangle and Triangle cases
+func DrawShape(s union.Shape) string {
+	switch s.(type) { // want `missing cases in type switch on Shape: union\.\*Rectangle, union\.\*Triangle`
+	case *union.Circle:
+		return "circle"
+	}
+	return ""
+}
+
+// DrawOtherShape - NG: Missing Square, of a union named like union.Shape
+func DrawOtherShape(s summaryshapes.Shape) string {
+	switch s.(type) { // want `missing cases in type switch on Shape: summaryshapes\.\*Square`
+	case *summaryshapes.Circle:
+		return "circle"
+	}
+	return ""
+}
+
+// DrawShapeWithDefault - OK: Has default case
+func DrawShapeWithDefault(s union.Shape) string {
+	switch s.(type) {
+	case *union.Circle:
+		return "circle"
+	default:
+		return "other"
+	}
+}
+
+// Describe - OK: Not a union switch, not counted
+func Describe(v any) string {
+	switch v.(type) {
+	case int:
+		return "int"
+	}
+	return ""
+}
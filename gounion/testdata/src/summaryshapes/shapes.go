@@ -0,0 +1,13 @@
+package summaryshapes
+
+// Shape is a union named like union.Shape, which the summary must not
+// merge with it.
+type Shape interface {
+	isShape()
+}
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
@@ -0,0 +1,36 @@
+package terminal
+
+import "union"
+
+// ===========================================
+// Test Cases: default cases ending with a call recognized by a registered
+// TerminalDetector (Alert)
+// ===========================================
+
+// Alert pages the on-call engineer and stops the process.
+func Alert(format string, args ...any) {}
+
+// Notify pages the on-call engineer and returns.
+func Notify(format string, args ...any) {}
+
+// AreaAlert - NG: default ends with Alert, missing Rectangle and Triangle
+func AreaAlert(s union.Shape) float64 {
+	switch s := s.(type) { // want `missing cases in type switch on Shape: union\.\*Rectangle, union\.\*Triangle`
+	case *union.Circle:
+		return 3.14 * s.Radius * s.Radius
+	default:
+		Alert("unexpected shape %T", s)
+	}
+	return 0
+}
+
+// AreaNotify - OK: default ends with Notify, which is not recognized
+func AreaNotify(s union.Shape) float64 {
+	switch s := s.(type) {
+	case *union.Circle:
+		return 3.14 * s.Radius * s.Radius
+	default:
+		Notify("unexpected shape %T", s)
+	}
+	return 0
+}
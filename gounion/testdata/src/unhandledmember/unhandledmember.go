@@ -0,0 +1,38 @@
+package unhandledmember
+
+import (
+	"errors"
+	"fmt"
+	"union"
+
+	"github.com/YuitoSato/gounion/gounionrt"
+)
+
+// ===========================================
+// Test Cases: gounionrt.ErrUnhandledMember in default
+// ===========================================
+
+// AreaErrUnhandledMember - default returns ErrUnhandledMember, missing Triangle.
+// Accepted by default, reported with -check-unhandled-member.
+func AreaErrUnhandledMember(s union.Shape) (float64, error) {
+	switch s := s.(type) { // want `missing cases in type switch on Shape: union\.\*Triangle`
+	case *union.Circle:
+		return 3.14 * s.Radius * s.Radius, nil
+	case *union.Rectangle:
+		return s.Width * s.Height, nil
+	default:
+		return 0, gounionrt.ErrUnhandledMember
+	}
+}
+
+// AreaJoinedErrUnhandledMember - default joins ErrUnhandledMember, missing Triangle.
+func AreaJoinedErrUnhandledMember(s union.Shape) (float64, error) {
+	switch s := s.(type) { // want `missing cases in type switch on Shape: union\.\*Triangle`
+	case *union.Circle:
+		return 3.14 * s.Radius * s.Radius, nil
+	case *union.Rectangle:
+		return s.Width * s.Height, nil
+	default:
+		return 0, errors.Join(fmt.Errorf("area of %T", s), gounionrt.ErrUnhandledMember)
+	}
+}
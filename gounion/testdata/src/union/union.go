@@ -11,7 +11,7 @@ import (
 
 // Result is a union type representing operation outcome.
 // The isResult() marker method restricts implementations to this package.
-type Result interface { // want Result:`&\{isResult \[\*Error \*Success\]\}`
+type Result interface { // want Result:`&\{isResult \[\*Error \*Success\] \[\]\}`
 	isResult()
 }
 
@@ -34,7 +34,7 @@ func (*Error) isResult()   {}
 // ===========================================
 
 // Shape is a union type representing geometric shapes.
-type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Rectangle \*Triangle\]\}`
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Rectangle \*Triangle\] \[\]\}`
 	isShape()
 }
 
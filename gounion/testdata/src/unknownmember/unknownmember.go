@@ -0,0 +1,106 @@
+package unknownmember
+
+import "fmt"
+
+// ===========================================
+// Test Cases: unions with an unknown member (run with -strict-default and
+// -unknown-members=Unknown*)
+// ===========================================
+
+// Event is open: UnknownEvent stands for events of newer producers.
+type Event interface { // want Event:`&\{isEvent \[\*Created \*Deleted \*UnknownEvent\] \[\]\}`
+	isEvent()
+}
+
+type Created struct{}
+
+func (*Created) isEvent() {}
+
+type Deleted struct{}
+
+func (*Deleted) isEvent() {}
+
+type UnknownEvent struct{ Raw []byte }
+
+func (*UnknownEvent) isEvent() {}
+
+// Shape is closed.
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Square\] \[\]\}`
+	isShape()
+}
+
+type Circle struct{}
+
+func (*Circle) isShape() {}
+
+type Square struct{}
+
+func (*Square) isShape() {}
+
+// describe - NG: the default handles the other events, but not UnknownEvent
+func describe(e Event) string {
+	switch e.(type) { // want `missing cases in type switch on Event: unknownmember\.\*UnknownEvent`
+	case *Created:
+		return "created"
+	default:
+		return "other"
+	}
+}
+
+// describeUnknown - OK: the default handles the other events of an open union
+func describeUnknown(e Event) string {
+	switch e.(type) {
+	case *Created:
+		return "created"
+	case *UnknownEvent:
+		return "unknown"
+	default:
+		return "other"
+	}
+}
+
+// handle - NG: UnknownEvent is still required without a default
+func handle(e Event) string {
+	switch e.(type) { // want `missing cases in type switch on Event: unknownmember\.\*UnknownEvent`
+	case *Created:
+		return "created"
+	case *Deleted:
+		return "deleted"
+	}
+	return ""
+}
+
+// handleGuard - NG: a guard default is still checked
+func handleGuard(e Event) string {
+	switch e.(type) { // want `missing cases in type switch on Event: unknownmember\.\*UnknownEvent`
+	case *Created:
+		return "created"
+	case *Deleted:
+		return "deleted"
+	default:
+		panic(fmt.Sprintf("unexpected event %T", e))
+	}
+}
+
+// handleAll - OK: every event is handled
+func handleAll(e Event) string {
+	switch e.(type) {
+	case *Created:
+		return "created"
+	case *Deleted:
+		return "deleted"
+	case *UnknownEvent:
+		return "unknown"
+	}
+	return ""
+}
+
+// area - NG: -strict-default checks the default of a closed union
+func area(s Shape) float64 {
+	switch s.(type) { // want `missing cases in type switch on Shape: unknownmember\.\*Square`
+	case *Circle:
+		return 1
+	default:
+		return 0
+	}
+}
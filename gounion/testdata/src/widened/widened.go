@@ -0,0 +1,97 @@
+package widened
+
+import "fmt"
+
+type Shape interface { // want Shape:`&\{isShape \[\*Circle \*Square\] \[\]\}`
+	isShape()
+}
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+// ===========================================
+// Test Cases: switches on locals widening a union
+// ===========================================
+
+func widen(s Shape) any { return s }
+
+func widenGeneric[T any](v T) any { return any(v) }
+
+// Converted - NG: x is initialized from a Shape
+func Converted(s Shape) string {
+	x := any(s)
+	switch x.(type) { // want `missing cases in type switch on Shape: widened\.\*Square`
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
+
+// Declared - NG: var declarations are tracked too, through another local
+func Declared(s Shape) string {
+	var x interface{} = s
+	y := x
+	switch y := y.(type) { // want `missing cases in type switch on Shape: widened\.\*Circle`
+	case *Square:
+		return fmt.Sprint(y)
+	}
+	return ""
+}
+
+// Widened - NG: widen returns its parameter
+func Widened(s Shape) string {
+	v := widen(s)
+	switch v.(type) { // want `missing cases in type switch on Shape: widened\.\*Square`
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
+
+// WidenedGeneric - NG: so does widenGeneric
+func WidenedGeneric(s Shape) string {
+	switch widenGeneric(s).(type) { // want `missing cases in type switch on Shape: widened\.\*Square`
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
+
+// Reassigned - OK: x may hold another value
+func Reassigned(s Shape, other any) string {
+	x := any(s)
+	if other != nil {
+		x = other
+	}
+	switch x.(type) {
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
+
+// Addressed - OK: x may be assigned through its address
+func Addressed(s Shape) string {
+	x := any(s)
+	set(&x)
+	switch x.(type) {
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
+
+func set(p *any) { *p = "value" }
+
+// Concrete - OK: x is initialized from a member, not from the union
+func Concrete() string {
+	x := any(&Circle{})
+	switch x.(type) {
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
@@ -3,22 +3,24 @@ package gounion
 import (
 	"go/ast"
 	"go/types"
-	"sort"
 
+	"github.com/YuitoSato/gounion/gounion/registry"
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/ast/inspector"
 )
 
 // exportUnionFacts scans the current package for union interfaces
 // and exports facts for them along with their implementing types.
-func exportUnionFacts(pass *analysis.Pass, inspect *inspector.Inspector) {
+// With excludeEmbedded, types that only have the marker method through an
+// embedded field are not members.
+func exportUnionFacts(pass *analysis.Pass, inspect *inspector.Inspector, excludeEmbedded bool) {
 	// Find all interface type declarations
 	nodeFilter := []ast.Node{
 		(*ast.GenDecl)(nil),
 	}
 
-	// Map to store union interfaces: interface object -> marker method name
-	unionInterfaces := make(map[*types.TypeName]string)
+	// Map to store union interfaces: interface object -> marker method names
+	unionInterfaces := make(map[*types.TypeName][]string)
 
 	inspect.Preorder(nodeFilter, func(n ast.Node) {
 		genDecl := n.(*ast.GenDecl)
@@ -52,98 +54,45 @@ func exportUnionFacts(pass *analysis.Pass, inspect *inspector.Inspector) {
 				continue
 			}
 
+			// Interfaces embedding several unions are their intersection,
+			// derived from the embedded unions' facts.
+			if len(registry.EmbeddedUnions(iface)) > 1 {
+				continue
+			}
+
 			// Check for marker methods
-			markerMethod := findMarkerMethod(iface)
-			if markerMethod == "" {
+			markers := registry.MarkerMethods(iface)
+			if len(markers) == 0 {
 				continue
 			}
 
-			unionInterfaces[typeName] = markerMethod
+			unionInterfaces[typeName] = markers
 		}
 	})
 
 	// For each union interface, find its members and export the fact
-	for typeName, markerMethod := range unionInterfaces {
-		members := findUnionMembers(pass, markerMethod)
+	files := newFileConstraintIndex(pass)
+	for typeName, markers := range unionInterfaces {
+		members := registry.Members(pass.Pkg, markers, excludeEmbedded)
 
 		fact := &UnionInterface{
-			MarkerMethod: markerMethod,
+			MarkerMethod: markers[0],
 			Members:      members,
+			Constraints:  memberConstraints(files, pass.Pkg, members, markers[0]),
 		}
 		pass.ExportObjectFact(typeName, fact)
 	}
 }
 
-// findMarkerMethod checks if an interface has a marker method.
-// A marker method is:
-// - unexported (starts with lowercase)
-// - has no parameters
-// - has no return values
-func findMarkerMethod(iface *types.Interface) string {
-	for i := 0; i < iface.NumMethods(); i++ {
-		method := iface.Method(i)
-
-		// Check if unexported
-		if method.Exported() {
-			continue
-		}
-
-		sig, ok := method.Type().(*types.Signature)
-		if !ok {
-			continue
-		}
-
-		// Check no parameters
-		if sig.Params().Len() != 0 {
-			continue
-		}
-
-		// Check no return values
-		if sig.Results().Len() != 0 {
-			continue
-		}
-
-		return method.Name()
-	}
-	return ""
-}
-
-// findUnionMembers finds all types in the package that implement
-// the given marker method.
-func findUnionMembers(pass *analysis.Pass, markerMethod string) []string {
-	var members []string
-
-	scope := pass.Pkg.Scope()
-	for _, name := range scope.Names() {
-		obj := scope.Lookup(name)
-
-		typeName, ok := obj.(*types.TypeName)
-		if !ok {
-			continue
-		}
-
-		// Skip interfaces themselves
-		if _, ok := typeName.Type().Underlying().(*types.Interface); ok {
-			continue
-		}
-
-		// Check both value type and pointer type for the marker method
-		if hasMarkerMethod(pass.Pkg, typeName.Type(), markerMethod) {
-			members = append(members, typeName.Name())
-		} else if hasMarkerMethod(pass.Pkg, types.NewPointer(typeName.Type()), markerMethod) {
-			members = append(members, "*"+typeName.Name())
-		}
-	}
-
-	// Sort members for consistent output
-	sort.Strings(members)
-
-	return members
-}
-
-// hasMarkerMethod checks if a type has the given marker method.
-func hasMarkerMethod(pkg *types.Package, typ types.Type, markerMethod string) bool {
-	mset := types.NewMethodSet(typ)
-	sel := mset.Lookup(pkg, markerMethod)
-	return sel != nil
+// Union is a union interface declared in a package, together with its fact.
+//
+// Deprecated: Use registry.Union.
+type Union = registry.Union
+
+// FindUnions returns the union interfaces declared at package level in pkg,
+// sorted by name.
+//
+// Deprecated: Use registry.FindUnions.
+func FindUnions(pkg *types.Package) []Union {
+	return registry.FindUnions(pkg)
 }
@@ -0,0 +1,241 @@
+// Package gounionjson encodes and decodes union interfaces as JSON objects
+// carrying a discriminator field.
+//
+// Generated code (gounion-gen -target=json) registers a factory per member
+// under its discriminator. Decoding reads the discriminator and calls the
+// matching factory, so no reflection is needed to pick the member type.
+//
+//	{"type":"Circle","Radius":1.5}
+package gounionjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// DefaultField is the name of the discriminator field unless changed with SetField.
+const DefaultField = "type"
+
+// registry holds the discriminator mappings of one union.
+type registry struct {
+	field          string
+	factories      map[string]func() any
+	discriminators map[reflect.Type]string
+}
+
+var (
+	mu         sync.RWMutex
+	registries = make(map[reflect.Type]*registry)
+)
+
+// lookup returns the registry of union S, creating it if create is set.
+func lookup[S any](create bool) *registry {
+	key := reflect.TypeFor[S]()
+
+	mu.RLock()
+	r := registries[key]
+	mu.RUnlock()
+	if r != nil || !create {
+		return r
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if r = registries[key]; r == nil {
+		r = &registry{
+			field:          DefaultField,
+			factories:      make(map[string]func() any),
+			discriminators: make(map[reflect.Type]string),
+		}
+		registries[key] = r
+	}
+	return r
+}
+
+// Register registers the member of union S created by factory under the
+// given discriminator. It is typically called from generated init functions
+// and panics if the discriminator is already registered.
+func Register[S any](discriminator string, factory func() S) {
+	r := lookup[S](true)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := r.factories[discriminator]; ok {
+		panic(fmt.Sprintf("gounionjson: discriminator %q registered twice for %s", discriminator, reflect.TypeFor[S]()))
+	}
+	r.factories[discriminator] = func() any { return factory() }
+	r.discriminators[reflect.TypeOf(factory())] = discriminator
+}
+
+// SetField sets the name of the discriminator field of union S.
+func SetField[S any](field string) {
+	r := lookup[S](true)
+
+	mu.Lock()
+	defer mu.Unlock()
+	r.field = field
+}
+
+// Marshal encodes v as a JSON object with the discriminator field of its
+// member prepended to the member's own fields. It fails if the member has a
+// field of the same name, which would be encoded twice.
+func Marshal[S any](v S) ([]byte, error) {
+	r := lookup[S](false)
+	if r == nil {
+		return nil, fmt.Errorf("gounionjson: no members registered for %s", reflect.TypeFor[S]())
+	}
+
+	mu.RLock()
+	discriminator, ok := r.discriminators[reflect.TypeOf(v)]
+	field := r.field
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("gounionjson: %T is not a registered member of %s", v, reflect.TypeFor[S]())
+	}
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) < 2 || body[0] != '{' {
+		return nil, fmt.Errorf("gounionjson: %T does not encode as a JSON object", v)
+	}
+	if hasKey(body, field) {
+		return nil, fmt.Errorf("gounionjson: %T has a field %q, the discriminator field of %s", v, field, reflect.TypeFor[S]())
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	key, _ := json.Marshal(field)
+	value, _ := json.Marshal(discriminator)
+	buf.Write(key)
+	buf.WriteByte(':')
+	buf.Write(value)
+	if rest := bytes.TrimSpace(body[1 : len(body)-1]); len(rest) > 0 {
+		buf.WriteByte(',')
+		buf.Write(rest)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// hasKey reports whether the JSON object obj has a member named key.
+func hasKey(obj []byte, key string) bool {
+	dec := json.NewDecoder(bytes.NewReader(obj))
+	if _, err := dec.Token(); err != nil {
+		return false
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		if t == key {
+			return true
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+// Unmarshal decodes a JSON object produced by Marshal into a new member of
+// union S, chosen by the discriminator field.
+func Unmarshal[S any](data []byte) (S, error) {
+	var zero S
+
+	r := lookup[S](false)
+	if r == nil {
+		return zero, fmt.Errorf("gounionjson: no members registered for %s", reflect.TypeFor[S]())
+	}
+
+	mu.RLock()
+	field := r.field
+	mu.RUnlock()
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return zero, err
+	}
+	raw, ok := fields[field]
+	if !ok {
+		return zero, fmt.Errorf("gounionjson: missing discriminator field %q for %s", field, reflect.TypeFor[S]())
+	}
+	var discriminator string
+	if err := json.Unmarshal(raw, &discriminator); err != nil {
+		return zero, fmt.Errorf("gounionjson: discriminator field %q: %w", field, err)
+	}
+
+	mu.RLock()
+	factory, ok := r.factories[discriminator]
+	mu.RUnlock()
+	if !ok {
+		return zero, fmt.Errorf("gounionjson: unknown discriminator %q for %s", discriminator, reflect.TypeFor[S]())
+	}
+
+	// Factories of members implementing S on their value type return
+	// values, which are decoded through a pointer to a copy.
+	v := factory()
+	if t := reflect.TypeOf(v); t.Kind() != reflect.Pointer {
+		p := reflect.New(t)
+		if err := json.Unmarshal(data, p.Interface()); err != nil {
+			return zero, err
+		}
+		return p.Elem().Interface().(S), nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return zero, err
+	}
+	return v.(S), nil
+}
+
+// Decode reads the next JSON object from dec and decodes it like Unmarshal,
+// for streams of union values.
+func Decode[S any](dec *json.Decoder) (S, error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		var zero S
+		return zero, err
+	}
+	return Unmarshal[S](raw)
+}
+
+// Field holds a union value inside a larger struct, encoding it with its
+// discriminator:
+//
+//	type Drawing struct {
+//		Shapes []gounionjson.Field[shape.Shape]
+//	}
+type Field[S any] struct {
+	Value S
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f Field[S]) MarshalJSON() ([]byte, error) {
+	if any(f.Value) == nil {
+		return []byte("null"), nil
+	}
+	return Marshal(f.Value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *Field[S]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		var zero S
+		f.Value = zero
+		return nil
+	}
+	v, err := Unmarshal[S](data)
+	if err != nil {
+		return err
+	}
+	f.Value = v
+	return nil
+}
@@ -0,0 +1,143 @@
+package gounionjson_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/YuitoSato/gounion/gounionjson"
+)
+
+type shape interface{ isShape() }
+
+type circle struct {
+	Radius float64
+}
+
+type empty struct{}
+
+type point struct {
+	X, Y int
+}
+
+type labeled struct {
+	Kind string `json:"type"`
+}
+
+func (*circle) isShape()  {}
+func (*empty) isShape()   {}
+func (point) isShape()    {}
+func (*labeled) isShape() {}
+
+func init() {
+	gounionjson.Register[shape]("circle", func() shape { return new(circle) })
+	gounionjson.Register[shape]("empty", func() shape { return new(empty) })
+	gounionjson.Register[shape]("point", func() shape { return point{} })
+	gounionjson.Register[shape]("labeled", func() shape { return new(labeled) })
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		in   shape
+		want string
+	}{
+		{in: &circle{Radius: 1.5}, want: `{"type":"circle","Radius":1.5}`},
+		{in: &empty{}, want: `{"type":"empty"}`},
+	}
+
+	for _, tt := range tests {
+		got, err := gounionjson.Marshal(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Marshal(%#v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMarshalFieldConflict(t *testing.T) {
+	// The member's own "type" field would be encoded next to the
+	// discriminator, and one of them lost when decoding.
+	if data, err := gounionjson.Marshal[shape](&labeled{Kind: "x"}); err == nil {
+		t.Errorf("Marshal() = %s, want an error for the field named like the discriminator", data)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	got, err := gounionjson.Unmarshal[shape]([]byte(`{"Radius":2,"type":"circle"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := got.(*circle); !ok || c.Radius != 2 {
+		t.Errorf("Unmarshal() = %#v, want &circle{Radius: 2}", got)
+	}
+
+	if _, err := gounionjson.Unmarshal[shape]([]byte(`{"type":"square"}`)); err == nil {
+		t.Error("expected an error for an unknown discriminator")
+	}
+	if _, err := gounionjson.Unmarshal[shape]([]byte(`{"Radius":2}`)); err == nil {
+		t.Error("expected an error for a missing discriminator")
+	}
+}
+
+func TestRoundTripValueMember(t *testing.T) {
+	data, err := gounionjson.Marshal[shape](point{X: 1, Y: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"type":"point","X":1,"Y":2}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+
+	got, err := gounionjson.Unmarshal[shape](data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != (point{X: 1, Y: 2}) {
+		t.Errorf("Unmarshal() = %#v, want point{X: 1, Y: 2}", got)
+	}
+}
+
+func TestDecodeStream(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(`{"type":"circle","Radius":1} {"type":"empty"}`))
+
+	first, err := gounionjson.Decode[shape](dec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := gounionjson.Decode[shape](dec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := first.(*circle); !ok {
+		t.Errorf("first = %#v, want *circle", first)
+	}
+	if _, ok := second.(*empty); !ok {
+		t.Errorf("second = %#v, want *empty", second)
+	}
+}
+
+func TestField(t *testing.T) {
+	type drawing struct {
+		Shapes []gounionjson.Field[shape]
+	}
+
+	in := drawing{Shapes: []gounionjson.Field[shape]{{Value: &circle{Radius: 3}}, {Value: &empty{}}}}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"Shapes":[{"type":"circle","Radius":3},{"type":"empty"}]}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+
+	var out drawing
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := out.Shapes[0].Value.(*circle); !ok || c.Radius != 3 {
+		t.Errorf("Shapes[0] = %#v, want &circle{Radius: 3}", out.Shapes[0].Value)
+	}
+}
@@ -0,0 +1,62 @@
+package gounionrt
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrNoHandler is returned by Dispatcher.Dispatch when no handler is
+// registered for the dynamic type of the value.
+var ErrNoHandler = errors.New("gounionrt: no handler registered")
+
+// Handler handles values of one member type of the union S.
+// Create handlers with Handle.
+type Handler[S any] struct {
+	typ reflect.Type
+	fn  func(S) error
+}
+
+// Handle creates a handler for the member type M of the union S.
+// M is inferred from fn:
+//
+//	gounionrt.Handle[Event](func(e *UserCreated) error { ... })
+func Handle[S any, M any](fn func(M) error) Handler[S] {
+	return Handler[S]{
+		typ: reflect.TypeFor[M](),
+		fn: func(v S) error {
+			return fn(any(v).(M))
+		},
+	}
+}
+
+// Dispatcher routes union values to the handler registered for their
+// dynamic type, e.g. for event or message buses.
+//
+// The gounion analyzer checks that the handlers passed to NewDispatcher
+// cover every member of the union, so new event types can't be dropped
+// silently.
+type Dispatcher[S any] struct {
+	handlers map[reflect.Type]func(S) error
+}
+
+// NewDispatcher creates a dispatcher for the union S from one handler per
+// member. If several handlers are given for the same member, the last wins.
+func NewDispatcher[S any](handlers ...Handler[S]) *Dispatcher[S] {
+	d := &Dispatcher[S]{handlers: make(map[reflect.Type]func(S) error, len(handlers))}
+	for _, h := range handlers {
+		d.handlers[h.typ] = h.fn
+	}
+	return d
+}
+
+// Dispatch calls the handler registered for the dynamic type of v and
+// returns its error. It returns an error wrapping ErrNoHandler if there is
+// no such handler.
+func (d *Dispatcher[S]) Dispatch(v S) error {
+	fn, ok := d.handlers[reflect.TypeOf(v)]
+	if !ok {
+		return fmt.Errorf("%w for %T", ErrNoHandler, v)
+	}
+	return fn(v)
+}
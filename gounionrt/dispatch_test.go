@@ -0,0 +1,41 @@
+package gounionrt_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/YuitoSato/gounion/gounionrt"
+)
+
+func TestDispatcher(t *testing.T) {
+	var got []string
+	d := gounionrt.NewDispatcher(
+		gounionrt.Handle[shape](func(c *circle) error {
+			got = append(got, "circle")
+			return nil
+		}),
+		gounionrt.Handle[shape](func(sq *square) error {
+			got = append(got, "square")
+			return nil
+		}),
+	)
+
+	for _, s := range []shape{&circle{}, &square{}} {
+		if err := d.Dispatch(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(got) != 2 || got[0] != "circle" || got[1] != "square" {
+		t.Errorf("handled = %v, want [circle square]", got)
+	}
+}
+
+func TestDispatcherNoHandler(t *testing.T) {
+	d := gounionrt.NewDispatcher(
+		gounionrt.Handle[shape](func(c *circle) error { return nil }),
+	)
+
+	if err := d.Dispatch(&square{}); !errors.Is(err, gounionrt.ErrNoHandler) {
+		t.Errorf("Dispatch() error = %v, want ErrNoHandler", err)
+	}
+}
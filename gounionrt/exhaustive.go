@@ -0,0 +1,87 @@
+package gounionrt
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// MissingHandlersError is returned by Exhaustive when members of a union
+// have no registered handler, or handlers are registered for non-members.
+type MissingHandlersError struct {
+	Missing []reflect.Type // members without a handler, in registry order
+	Unknown []reflect.Type // handled types that are not members
+}
+
+// Error implements the error interface.
+func (e *MissingHandlersError) Error() string {
+	var b strings.Builder
+	b.WriteString("gounionrt:")
+	if len(e.Missing) > 0 {
+		b.WriteString(" no handler registered for members " + joinTypes(e.Missing))
+	}
+	if len(e.Unknown) > 0 {
+		if len(e.Missing) > 0 {
+			b.WriteString(";")
+		}
+		b.WriteString(" handlers registered for non-members " + joinTypes(e.Unknown))
+	}
+	return b.String()
+}
+
+// Exhaustive checks at runtime that handlers has an entry for every member
+// type listed by a union's generated registry (e.g. ShapeMembers()), and no
+// entry for other types. It returns a *MissingHandlersError otherwise.
+//
+// It complements the static check of NewDispatcher for handlers that are
+// wired dynamically, e.g. by plugins, and is meant to be called at startup
+// or from a health check:
+//
+//	if err := gounionrt.Exhaustive(shape.ShapeMembers(), handlers); err != nil {
+//		log.Fatal(err)
+//	}
+func Exhaustive[H any](members []reflect.Type, handlers map[reflect.Type]H) error {
+	var err MissingHandlersError
+
+	isMember := make(map[reflect.Type]bool, len(members))
+	for _, m := range members {
+		isMember[m] = true
+		if _, ok := handlers[m]; !ok {
+			err.Missing = append(err.Missing, m)
+		}
+	}
+	for t := range handlers {
+		if !isMember[t] {
+			err.Unknown = append(err.Unknown, t)
+		}
+	}
+
+	if len(err.Missing) == 0 && len(err.Unknown) == 0 {
+		return nil
+	}
+	// Map iteration order is random; keep the message stable.
+	sortTypes(err.Unknown)
+	return &err
+}
+
+// Exhaustive checks that the dispatcher has a handler for every member type
+// listed by a union's generated registry, as the package-level Exhaustive.
+func (d *Dispatcher[S]) Exhaustive(members []reflect.Type) error {
+	return Exhaustive(members, d.handlers)
+}
+
+// joinTypes formats types as a comma-separated list.
+func joinTypes(types []reflect.Type) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// sortTypes sorts types by their string form.
+func sortTypes(types []reflect.Type) {
+	slices.SortFunc(types, func(a, b reflect.Type) int {
+		return strings.Compare(a.String(), b.String())
+	})
+}
@@ -0,0 +1,56 @@
+package gounionrt_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/YuitoSato/gounion/gounionrt"
+)
+
+// shapeMembers mimics the registry generated by gounion-gen.
+func shapeMembers() []reflect.Type {
+	return []reflect.Type{
+		reflect.TypeFor[*circle](),
+		reflect.TypeFor[*square](),
+	}
+}
+
+func TestExhaustive(t *testing.T) {
+	handlers := map[reflect.Type]string{
+		reflect.TypeFor[*circle](): "circle",
+		reflect.TypeFor[*square](): "square",
+	}
+	if err := gounionrt.Exhaustive(shapeMembers(), handlers); err != nil {
+		t.Errorf("Exhaustive() = %v, want nil", err)
+	}
+}
+
+func TestExhaustiveMissing(t *testing.T) {
+	handlers := map[reflect.Type]string{
+		reflect.TypeFor[*circle](): "circle",
+		reflect.TypeFor[string]():  "string",
+	}
+
+	err := gounionrt.Exhaustive(shapeMembers(), handlers)
+	var missing *gounionrt.MissingHandlersError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Exhaustive() = %v, want *MissingHandlersError", err)
+	}
+	want := "gounionrt: no handler registered for members *gounionrt_test.square; handlers registered for non-members string"
+	if err.Error() != want {
+		t.Errorf("Exhaustive() = %q, want %q", err, want)
+	}
+}
+
+func TestDispatcherExhaustive(t *testing.T) {
+	d := gounionrt.NewDispatcher(
+		gounionrt.Handle[shape](func(c *circle) error { return nil }),
+	)
+
+	err := d.Exhaustive(shapeMembers())
+	var missing *gounionrt.MissingHandlersError
+	if !errors.As(err, &missing) || len(missing.Missing) != 1 || missing.Missing[0] != reflect.TypeFor[*square]() {
+		t.Errorf("Exhaustive() = %v, want missing *square", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Package gounionrt provides runtime helpers for union interfaces
+// (interfaces with unexported marker methods).
+//
+// The helpers are checked by the gounion analyzer: for example, a call to
+// Match3 must provide one function per member of the union it matches on.
+package gounionrt
+
+// Match2 calls the function whose parameter type matches the dynamic type
+// of v and returns its result. It panics if none matches.
+func Match2[S any, A, B any, R any](v S, a func(A) R, b func(B) R) R {
+	switch x := any(v).(type) {
+	case A:
+		return a(x)
+	case B:
+		return b(x)
+	}
+	panic(unhandled(v))
+}
+
+// Match3 calls the function whose parameter type matches the dynamic type
+// of v and returns its result. It panics if none matches.
+func Match3[S any, A, B, C any, R any](v S, a func(A) R, b func(B) R, c func(C) R) R {
+	switch x := any(v).(type) {
+	case A:
+		return a(x)
+	case B:
+		return b(x)
+	case C:
+		return c(x)
+	}
+	panic(unhandled(v))
+}
+
+// Match4 calls the function whose parameter type matches the dynamic type
+// of v and returns its result. It panics if none matches.
+func Match4[S any, A, B, C, D any, R any](v S, a func(A) R, b func(B) R, c func(C) R, d func(D) R) R {
+	switch x := any(v).(type) {
+	case A:
+		return a(x)
+	case B:
+		return b(x)
+	case C:
+		return c(x)
+	case D:
+		return d(x)
+	}
+	panic(unhandled(v))
+}
+
+// Match5 calls the function whose parameter type matches the dynamic type
+// of v and returns its result. It panics if none matches.
+func Match5[S any, A, B, C, D, E any, R any](v S, a func(A) R, b func(B) R, c func(C) R, d func(D) R, e func(E) R) R {
+	switch x := any(v).(type) {
+	case A:
+		return a(x)
+	case B:
+		return b(x)
+	case C:
+		return c(x)
+	case D:
+		return d(x)
+	case E:
+		return e(x)
+	}
+	panic(unhandled(v))
+}
+
+// Match6 calls the function whose parameter type matches the dynamic type
+// of v and returns its result. It panics if none matches.
+func Match6[S any, A, B, C, D, E, F any, R any](v S, a func(A) R, b func(B) R, c func(C) R, d func(D) R, e func(E) R, f func(F) R) R {
+	switch x := any(v).(type) {
+	case A:
+		return a(x)
+	case B:
+		return b(x)
+	case C:
+		return c(x)
+	case D:
+		return d(x)
+	case E:
+		return e(x)
+	case F:
+		return f(x)
+	}
+	panic(unhandled(v))
+}
+
+// Match7 calls the function whose parameter type matches the dynamic type
+// of v and returns its result. It panics if none matches.
+func Match7[S any, A, B, C, D, E, F, G any, R any](v S, a func(A) R, b func(B) R, c func(C) R, d func(D) R, e func(E) R, f func(F) R, g func(G) R) R {
+	switch x := any(v).(type) {
+	case A:
+		return a(x)
+	case B:
+		return b(x)
+	case C:
+		return c(x)
+	case D:
+		return d(x)
+	case E:
+		return e(x)
+	case F:
+		return f(x)
+	case G:
+		return g(x)
+	}
+	panic(unhandled(v))
+}
+
+// Match8 calls the function whose parameter type matches the dynamic type
+// of v and returns its result. It panics if none matches.
+func Match8[S any, A, B, C, D, E, F, G, H any, R any](v S, a func(A) R, b func(B) R, c func(C) R, d func(D) R, e func(E) R, f func(F) R, g func(G) R, h func(H) R) R {
+	switch x := any(v).(type) {
+	case A:
+		return a(x)
+	case B:
+		return b(x)
+	case C:
+		return c(x)
+	case D:
+		return d(x)
+	case E:
+		return e(x)
+	case F:
+		return f(x)
+	case G:
+		return g(x)
+	case H:
+		return h(x)
+	}
+	panic(unhandled(v))
+}
@@ -0,0 +1,51 @@
+package gounionrt_test
+
+import (
+	"testing"
+
+	"github.com/YuitoSato/gounion/gounionrt"
+)
+
+type shape interface{ isShape() }
+
+type circle struct{ radius float64 }
+
+type square struct{ side float64 }
+
+func (*circle) isShape() {}
+func (*square) isShape() {}
+
+func area(s shape) float64 {
+	return gounionrt.Match2(s,
+		func(c *circle) float64 { return 3 * c.radius * c.radius },
+		func(sq *square) float64 { return sq.side * sq.side },
+	)
+}
+
+func TestMatch2(t *testing.T) {
+	if got := area(&circle{radius: 2}); got != 12 {
+		t.Errorf("area(circle) = %v, want 12", got)
+	}
+	if got := area(&square{side: 3}); got != 9 {
+		t.Errorf("area(square) = %v, want 9", got)
+	}
+}
+
+func TestMatch2PanicsOnNil(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for nil value")
+		}
+	}()
+	area(nil)
+}
+
+func TestMustHandle(t *testing.T) {
+	defer func() {
+		want := "gounionrt: unhandled member *gounionrt_test.square of union gounionrt_test.shape: &{side:2}"
+		if got := recover(); got != want {
+			t.Errorf("panic = %q, want %q", got, want)
+		}
+	}()
+	gounionrt.MustHandle[float64, shape](&square{side: 2})
+}
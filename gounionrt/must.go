@@ -0,0 +1,26 @@
+package gounionrt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MustHandle panics with a message describing v as an unhandled member of
+// the union S. It is meant for default branches of type switches that
+// should be unreachable:
+//
+//	default:
+//		return gounionrt.MustHandle[float64](s)
+//
+// The result type R lets the call be used in return statements. The
+// gounion analyzer treats a default case ending in MustHandle like one
+// ending in panic, so the switch must still be exhaustive.
+func MustHandle[R any, S any](v S) R {
+	panic(unhandled(v))
+}
+
+// unhandled formats the panic message for a value of union S that no
+// branch handled: its dynamic type, the union's name and a value dump.
+func unhandled[S any](v S) string {
+	return fmt.Sprintf("gounionrt: unhandled member %T of union %s: %+v", v, reflect.TypeFor[S](), v)
+}
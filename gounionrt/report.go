@@ -0,0 +1,90 @@
+package gounionrt
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Reporter receives unhandled union members reported by ReportUnhandled.
+type Reporter func(ctx context.Context, union, member reflect.Type, v any)
+
+var (
+	reporter atomic.Pointer[Reporter]
+
+	countsMu sync.Mutex
+	counts   = make(map[string]uint64)
+)
+
+// SetReporter replaces the function ReportUnhandled calls after counting,
+// e.g. to emit metrics. Passing nil restores the default, which logs a
+// warning with slog.
+func SetReporter(r Reporter) {
+	if r == nil {
+		reporter.Store(nil)
+		return
+	}
+	reporter.Store(&r)
+}
+
+// ReportUnhandled records v as an unhandled member of the union S instead
+// of panicking, for default branches during gradual rollouts of new
+// members:
+//
+//	default:
+//		gounionrt.ReportUnhandled(ctx, s)
+//		return 0
+//
+// It increments the count returned by UnhandledCounts and calls the
+// reporter set with SetReporter. The gounion analyzer treats a default case
+// ending in ReportUnhandled as an acknowledged escape hatch.
+func ReportUnhandled[S any](ctx context.Context, v S) {
+	union := reflect.TypeFor[S]()
+	member := reflect.TypeOf(v)
+
+	countsMu.Lock()
+	counts[union.String()+"/"+typeString(member)]++
+	countsMu.Unlock()
+
+	if r := reporter.Load(); r != nil {
+		(*r)(ctx, union, member, v)
+		return
+	}
+	slog.WarnContext(ctx, "gounionrt: unhandled union member",
+		slog.String("union", union.String()),
+		slog.String("member", typeString(member)))
+}
+
+// ErrUnhandledMember is the error returned, or wrapped, by default
+// branches that deliberately leave members of a union unhandled:
+//
+//	default:
+//		return fmt.Errorf("pricing %T: %w", s, gounionrt.ErrUnhandledMember)
+//
+// The gounion analyzer treats a default case returning it as an
+// acknowledged escape hatch, and errors.Is finds such branches at run time.
+var ErrUnhandledMember = errors.New("gounionrt: unhandled union member")
+
+// UnhandledCounts returns how often ReportUnhandled was called, keyed by
+// "<union>/<member>", e.g. "shape.Shape/*shape.Hexagon".
+func UnhandledCounts() map[string]uint64 {
+	countsMu.Lock()
+	defer countsMu.Unlock()
+
+	snapshot := make(map[string]uint64, len(counts))
+	for k, v := range counts {
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
+// typeString formats a possibly nil type.
+func typeString(t reflect.Type) string {
+	if t == nil {
+		return "<nil>"
+	}
+	return t.String()
+}
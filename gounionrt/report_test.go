@@ -0,0 +1,27 @@
+package gounionrt_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/YuitoSato/gounion/gounionrt"
+)
+
+func TestReportUnhandled(t *testing.T) {
+	var reported []string
+	gounionrt.SetReporter(func(ctx context.Context, union, member reflect.Type, v any) {
+		reported = append(reported, union.String()+" "+member.String())
+	})
+	defer gounionrt.SetReporter(nil)
+
+	before := gounionrt.UnhandledCounts()["gounionrt_test.shape/*gounionrt_test.square"]
+	gounionrt.ReportUnhandled[shape](context.Background(), &square{})
+
+	if len(reported) != 1 || reported[0] != "gounionrt_test.shape *gounionrt_test.square" {
+		t.Errorf("reported = %q", reported)
+	}
+	if got := gounionrt.UnhandledCounts()["gounionrt_test.shape/*gounionrt_test.square"]; got != before+1 {
+		t.Errorf("count = %d, want %d", got, before+1)
+	}
+}
@@ -0,0 +1,572 @@
+package driver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/objectpath"
+)
+
+// action is the analysis of a root package by the analyzer, as a
+// checker.Action.
+type action struct {
+	Package     *packages.Package
+	Diagnostics []analysis.Diagnostic
+	Result      any
+	Err         error
+}
+
+// analyze type-checks pkgs and their dependencies, as loaded by load in
+// the configuration c, in dependency order, and runs a on pkgs, returning
+// their actions in order. Unlike checker.Analyze, it holds the syntax of a
+// package only while checking and analyzing it, so that the syntax trees
+// and type information of the whole graph are never in memory at once.
+//
+// Dependencies are analyzed too if an analyzer has facts, unless their
+// facts are found in opts.FactsDir, and their function bodies are not
+// type-checked otherwise. The facts of analyzed packages are written to
+// opts.FactsDir, if set, for later runs and other shards. Type errors are
+// printed, and returned as a load error.
+func analyze(a *analysis.Analyzer, pkgs []*packages.Package, c Config, opts Options) ([]*action, error) {
+	r := &runner{
+		analyzers: requiredAnalyzers(a),
+		fset:      token.NewFileSet(),
+		dir:       opts.FactsDir,
+		config:    c,
+		roots:     make(map[*packages.Package]*action, len(pkgs)),
+		done:      make(map[*packages.Package]chan struct{}),
+		keys:      make(map[*packages.Package]string),
+		objects:   make(map[objectFactKey]analysis.Fact),
+		packages:  make(map[packageFactKey]analysis.Fact),
+	}
+	for _, an := range r.analyzers {
+		for _, f := range an.FactTypes {
+			r.facts = true
+			gob.Register(f)
+		}
+	}
+	if r.dir != "" && r.facts {
+		if err := os.MkdirAll(r.dir, 0o777); err != nil {
+			return nil, err
+		}
+		id, err := r.identity()
+		if err != nil {
+			return nil, err
+		}
+		r.id = id
+	}
+
+	actions := make([]*action, len(pkgs))
+	for i, pkg := range pkgs {
+		actions[i] = &action{Package: pkg}
+		r.roots[pkg] = actions[i]
+	}
+
+	var wg sync.WaitGroup
+	r.cpu = make(chan struct{}, runtime.GOMAXPROCS(0))
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		r.done[pkg] = make(chan struct{})
+	})
+	for pkg := range r.done {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(r.done[pkg])
+			for _, imp := range pkg.Imports {
+				<-r.done[imp]
+			}
+			r.cpu <- struct{}{}
+			defer func() { <-r.cpu }()
+			r.process(pkg)
+		}()
+	}
+	wg.Wait()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	if n := packages.PrintErrors(pkgs); n > 0 {
+		return nil, loadError(n, c, opts)
+	}
+	return actions, nil
+}
+
+// runner holds the state of an analyze call.
+type runner struct {
+	analyzers []*analysis.Analyzer // a and the analyzers it requires, required first
+	facts     bool                 // whether an analyzer has facts
+	fset      *token.FileSet
+	dir       string // Options.FactsDir
+	id        string // identity of the analyzers, part of fact artifact keys
+	config    Config
+	roots     map[*packages.Package]*action
+
+	done map[*packages.Package]chan struct{} // closed once a package is processed
+	cpu  chan struct{}                       // tokens bounding the packages processed at once
+
+	mu       sync.Mutex
+	keys     map[*packages.Package]string // keys of the fact artifacts of processed packages
+	objects  map[objectFactKey]analysis.Fact
+	packages map[packageFactKey]analysis.Fact
+	err      error // first I/O error writing or reading artifacts
+}
+
+// objectFactKey and packageFactKey identify a fact of a type about an
+// object or package.
+type (
+	objectFactKey struct {
+		obj types.Object
+		typ reflect.Type
+	}
+	packageFactKey struct {
+		pkg *types.Package
+		typ reflect.Type
+	}
+)
+
+// process type-checks pkg, after its imports, and analyzes it if it is a
+// root or its facts are needed and not found in an artifact.
+func (r *runner) process(pkg *packages.Package) {
+	if pkg.PkgPath == "unsafe" {
+		pkg.Types = types.Unsafe
+		return
+	}
+	for _, imp := range pkg.Imports {
+		if imp.IllTyped {
+			pkg.IllTyped = true
+			return
+		}
+	}
+
+	srcs := make([][]byte, len(pkg.CompiledGoFiles))
+	for i, name := range pkg.CompiledGoFiles {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			pkg.Errors = append(pkg.Errors, packages.Error{Msg: err.Error(), Kind: packages.ParseError})
+			pkg.IllTyped = true
+			return
+		}
+		srcs[i] = src
+	}
+
+	root := r.roots[pkg]
+	var key string
+	if r.id != "" {
+		key = r.key(pkg, srcs)
+	}
+	var art *factArtifact
+	if root == nil && r.facts && key != "" {
+		art = r.readArtifact(key)
+	}
+	analyzed := root != nil || r.facts && art == nil
+
+	// Comments are needed by analyzers, e.g. for directives, but not to
+	// type-check dependencies.
+	mode := parser.AllErrors | parser.SkipObjectResolution
+	if analyzed {
+		mode |= parser.ParseComments
+	}
+	files := make([]*ast.File, 0, len(srcs))
+	for i, src := range srcs {
+		f, err := parser.ParseFile(r.fset, pkg.CompiledGoFiles[i], src, mode)
+		if f != nil {
+			files = append(files, f)
+		}
+		if err != nil {
+			r.record(pkg, err)
+		}
+	}
+
+	pkg.Fset = r.fset
+	pkg.Types = types.NewPackage(pkg.PkgPath, pkg.Name)
+	var info *types.Info
+	if analyzed {
+		info = &types.Info{
+			Types:        make(map[ast.Expr]types.TypeAndValue),
+			Defs:         make(map[*ast.Ident]types.Object),
+			Uses:         make(map[*ast.Ident]types.Object),
+			Implicits:    make(map[ast.Node]types.Object),
+			Instances:    make(map[*ast.Ident]types.Instance),
+			Scopes:       make(map[ast.Node]*types.Scope),
+			Selections:   make(map[*ast.SelectorExpr]*types.Selection),
+			FileVersions: make(map[*ast.File]string),
+		}
+	}
+	tc := &types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if path == "unsafe" {
+				return types.Unsafe, nil
+			}
+			imp := pkg.Imports[path]
+			if imp == nil || imp.Types == nil {
+				return nil, fmt.Errorf("no metadata for %s", path)
+			}
+			return imp.Types, nil
+		}),
+		// Declarations alone determine the types dependents see.
+		IgnoreFuncBodies: !analyzed,
+		Error:            func(err error) { r.record(pkg, err) },
+		Sizes:            pkg.TypesSizes,
+	}
+	if pkg.Module != nil && pkg.Module.GoVersion != "" {
+		tc.GoVersion = "go" + pkg.Module.GoVersion
+	}
+	if err := types.NewChecker(tc, r.fset, pkg.Types, info).Files(files); err != nil && len(pkg.Errors) == 0 {
+		r.record(pkg, err)
+	}
+	if len(pkg.Errors) > 0 {
+		pkg.IllTyped = true
+		return
+	}
+
+	r.mu.Lock()
+	r.keys[pkg] = key
+	r.mu.Unlock()
+
+	if !analyzed {
+		if art != nil {
+			r.importArtifact(pkg, key, art)
+		}
+		return
+	}
+	pkg.Syntax, pkg.TypesInfo = files, info
+	act := r.run(pkg)
+	pkg.Syntax, pkg.TypesInfo = nil, nil
+	if root != nil {
+		root.Diagnostics, root.Result, root.Err = act.Diagnostics, act.Result, act.Err
+	}
+	if key != "" && r.facts && act.Err == nil {
+		r.writeFacts(pkg, key)
+	}
+}
+
+// record records an error of pkg.
+func (r *runner) record(pkg *packages.Package, err error) {
+	switch err := err.(type) {
+	case types.Error:
+		pkg.Errors = append(pkg.Errors, packages.Error{Pos: err.Fset.Position(err.Pos).String(), Msg: err.Msg, Kind: packages.TypeError})
+	case scanner.ErrorList:
+		for _, e := range err {
+			pkg.Errors = append(pkg.Errors, packages.Error{Pos: e.Pos.String(), Msg: e.Msg, Kind: packages.ParseError})
+		}
+	default:
+		pkg.Errors = append(pkg.Errors, packages.Error{Msg: err.Error(), Kind: packages.UnknownError})
+	}
+}
+
+// run runs the analyzers on pkg, with its syntax and type information,
+// returning the action of the last one, a.
+func (r *runner) run(pkg *packages.Package) *action {
+	results := make(map[*analysis.Analyzer]any, len(r.analyzers))
+	act := &action{Package: pkg}
+	for _, an := range r.analyzers {
+		act = &action{Package: pkg}
+		pass := &analysis.Pass{
+			Analyzer:     an,
+			Fset:         r.fset,
+			Files:        pkg.Syntax,
+			OtherFiles:   pkg.OtherFiles,
+			IgnoredFiles: pkg.IgnoredFiles,
+			Pkg:          pkg.Types,
+			TypesInfo:    pkg.TypesInfo,
+			TypesSizes:   pkg.TypesSizes,
+			ResultOf:     make(map[*analysis.Analyzer]any, len(an.Requires)),
+			Report:       func(d analysis.Diagnostic) { act.Diagnostics = append(act.Diagnostics, d) },
+			ReadFile:     os.ReadFile,
+
+			ImportObjectFact:  r.importObjectFact,
+			ImportPackageFact: r.importPackageFact,
+			ExportObjectFact: func(obj types.Object, fact analysis.Fact) {
+				if obj.Pkg() != pkg.Types {
+					panic(fmt.Sprintf("%s: fact exported about %s of another package", an.Name, obj))
+				}
+				r.mu.Lock()
+				r.objects[objectFactKey{obj, reflect.TypeOf(fact)}] = fact
+				r.mu.Unlock()
+			},
+			ExportPackageFact: func(fact analysis.Fact) {
+				r.mu.Lock()
+				r.packages[packageFactKey{pkg.Types, reflect.TypeOf(fact)}] = fact
+				r.mu.Unlock()
+			},
+			AllObjectFacts:  func() []analysis.ObjectFact { return r.allObjectFacts(an) },
+			AllPackageFacts: func() []analysis.PackageFact { return r.allPackageFacts(an) },
+		}
+		if pkg.Module != nil {
+			pass.Module = &analysis.Module{Path: pkg.Module.Path, Version: pkg.Module.Version, GoVersion: pkg.Module.GoVersion}
+		}
+		for _, req := range an.Requires {
+			pass.ResultOf[req] = results[req]
+		}
+		act.Result, act.Err = an.Run(pass)
+		if act.Err != nil {
+			return act
+		}
+		results[an] = act.Result
+	}
+	return act
+}
+
+// importObjectFact implements analysis.Pass.ImportObjectFact.
+func (r *runner) importObjectFact(obj types.Object, fact analysis.Fact) bool {
+	r.mu.Lock()
+	stored, ok := r.objects[objectFactKey{obj, reflect.TypeOf(fact)}]
+	r.mu.Unlock()
+	if ok {
+		reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(stored).Elem())
+	}
+	return ok
+}
+
+// importPackageFact implements analysis.Pass.ImportPackageFact.
+func (r *runner) importPackageFact(pkg *types.Package, fact analysis.Fact) bool {
+	r.mu.Lock()
+	stored, ok := r.packages[packageFactKey{pkg, reflect.TypeOf(fact)}]
+	r.mu.Unlock()
+	if ok {
+		reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(stored).Elem())
+	}
+	return ok
+}
+
+// allObjectFacts implements analysis.Pass.AllObjectFacts for an.
+func (r *runner) allObjectFacts(an *analysis.Analyzer) []analysis.ObjectFact {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var facts []analysis.ObjectFact
+	for k, fact := range r.objects {
+		if factOf(an, k.typ) {
+			facts = append(facts, analysis.ObjectFact{Object: k.obj, Fact: fact})
+		}
+	}
+	return facts
+}
+
+// allPackageFacts implements analysis.Pass.AllPackageFacts for an.
+func (r *runner) allPackageFacts(an *analysis.Analyzer) []analysis.PackageFact {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var facts []analysis.PackageFact
+	for k, fact := range r.packages {
+		if factOf(an, k.typ) {
+			facts = append(facts, analysis.PackageFact{Package: k.pkg, Fact: fact})
+		}
+	}
+	return facts
+}
+
+// factOf reports whether facts of type t belong to an.
+func factOf(an *analysis.Analyzer, t reflect.Type) bool {
+	for _, f := range an.FactTypes {
+		if reflect.TypeOf(f) == t {
+			return true
+		}
+	}
+	return false
+}
+
+// factArtifact is the content of a fact artifact: the facts about a
+// package and its objects, as gob.
+type factArtifact struct {
+	Objects  []objectFactEntry
+	Packages []analysis.Fact
+}
+
+// objectFactEntry is a fact about the object of a package at Path.
+type objectFactEntry struct {
+	Path objectpath.Path
+	Fact analysis.Fact
+}
+
+// artifact returns the file of the fact artifact with the given key.
+func (r *runner) artifact(key string) string {
+	return filepath.Join(r.dir, key+".facts")
+}
+
+// key returns the key of the fact artifact of pkg, with the contents srcs
+// of its compiled Go files: a hash of the analyzers, the configuration,
+// the package and its files, and the keys of its imports, so that an
+// artifact is only reused for an identical package and dependencies.
+func (r *runner) key(pkg *packages.Package, srcs [][]byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%d\n", r.id, r.config, pkg.ID, len(srcs))
+	for i, src := range srcs {
+		fmt.Fprintf(h, "%s %d\n", filepath.Base(pkg.CompiledGoFiles[i]), len(src))
+		h.Write(src)
+	}
+	paths := make([]string, 0, len(pkg.Imports))
+	for path := range pkg.Imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	r.mu.Lock()
+	for _, path := range paths {
+		fmt.Fprintf(h, "%s %s\n", path, r.keys[pkg.Imports[path]])
+	}
+	r.mu.Unlock()
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// identity returns a hash of the running executable and of the flags of
+// the analyzers, which determine the facts they compute.
+func (r *runner) identity() (string, error) {
+	h := sha256.New()
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	for _, an := range r.analyzers {
+		fmt.Fprintf(h, "\n%s", an.Name)
+		an.Flags.VisitAll(func(f *flag.Flag) {
+			fmt.Fprintf(h, " -%s=%s", f.Name, f.Value)
+		})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeFacts writes the facts of pkg to the artifact with the given key.
+func (r *runner) writeFacts(pkg *packages.Package, key string) {
+	var art factArtifact
+	r.mu.Lock()
+	for k, fact := range r.objects {
+		if k.obj.Pkg() != pkg.Types {
+			continue
+		}
+		if path, err := objectpath.For(k.obj); err == nil {
+			art.Objects = append(art.Objects, objectFactEntry{path, fact})
+		}
+	}
+	for k, fact := range r.packages {
+		if k.pkg == pkg.Types {
+			art.Packages = append(art.Packages, fact)
+		}
+	}
+	r.mu.Unlock()
+	sort.Slice(art.Objects, func(i, j int) bool {
+		return art.Objects[i].Path < art.Objects[j].Path
+	})
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&art); err != nil {
+		r.fail(fmt.Errorf("encoding facts of %s: %v", pkg.PkgPath, err))
+		return
+	}
+	// Write to a temporary file first, so that concurrent runs sharing the
+	// directory never read a partial artifact.
+	tmp, err := os.CreateTemp(r.dir, key+".*.tmp")
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	_, err = tmp.Write(buf.Bytes())
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), r.artifact(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		r.fail(err)
+	}
+}
+
+// readArtifact returns the fact artifact with the given key, or nil if
+// there is none. An artifact that cannot be decoded, e.g. one left by an
+// interrupted write on a file system without atomic renames, is ignored,
+// and the package analyzed again.
+func (r *runner) readArtifact(key string) *factArtifact {
+	data, err := os.ReadFile(r.artifact(key))
+	if err != nil {
+		return nil
+	}
+	var art factArtifact
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&art); err != nil {
+		return nil
+	}
+	return &art
+}
+
+// importArtifact records the facts of pkg read from the artifact art with
+// the given key.
+func (r *runner) importArtifact(pkg *packages.Package, key string, art *factArtifact) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range art.Objects {
+		obj, err := objectpath.Object(pkg.Types, e.Path)
+		if err != nil {
+			r.setErr(fmt.Errorf("reading facts of %s from %s: %v", pkg.PkgPath, r.artifact(key), err))
+			return
+		}
+		r.objects[objectFactKey{obj, reflect.TypeOf(e.Fact)}] = e.Fact
+	}
+	for _, fact := range art.Packages {
+		r.packages[packageFactKey{pkg.Types, reflect.TypeOf(fact)}] = fact
+	}
+}
+
+// fail records err, if it is the first error of the run.
+func (r *runner) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.setErr(err)
+}
+
+// setErr is fail with r.mu held.
+func (r *runner) setErr(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// requiredAnalyzers returns a and the analyzers it requires, transitively,
+// each after those it requires.
+func requiredAnalyzers(a *analysis.Analyzer) []*analysis.Analyzer {
+	var order []*analysis.Analyzer
+	seen := make(map[*analysis.Analyzer]bool)
+	var visit func(*analysis.Analyzer)
+	visit = func(a *analysis.Analyzer) {
+		if seen[a] {
+			return
+		}
+		seen[a] = true
+		for _, req := range a.Requires {
+			visit(req)
+		}
+		order = append(order, a)
+	}
+	visit(a)
+	return order
+}
+
+// importerFunc implements types.Importer with a function.
+type importerFunc func(path string) (*types.Package, error)
+
+// Import implements types.Importer.
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
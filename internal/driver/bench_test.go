@@ -0,0 +1,114 @@
+package driver_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/YuitoSato/gounion/gounion"
+	"github.com/YuitoSato/gounion/internal/driver"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// BenchmarkRun compares the peak heap of a run of the driver, which
+// type-checks one package at a time, with that of loading the same
+// packages with packages.LoadAllSyntax and analyzing them with
+// checker.Analyze, which holds the syntax and type information of every
+// dependency at once.
+func BenchmarkRun(b *testing.B) {
+	dir := writeBenchModule(b, 20)
+
+	b.Run("driver", func(b *testing.B) {
+		measurePeakHeap(b, func() {
+			if _, err := driver.Run(gounion.Analyzer, []string{"./..."}, driver.Options{Dir: dir}); err != nil {
+				b.Fatal(err)
+			}
+		})
+	})
+	b.Run("checker", func(b *testing.B) {
+		measurePeakHeap(b, func() {
+			pkgs, err := packages.Load(&packages.Config{Mode: packages.LoadAllSyntax | packages.NeedModule, Dir: dir}, "./...")
+			if err != nil {
+				b.Fatal(err)
+			}
+			if packages.PrintErrors(pkgs) > 0 {
+				b.Fatal("failed to load benchmark packages")
+			}
+			if _, err := checker.Analyze([]*analysis.Analyzer{gounion.Analyzer}, pkgs, nil); err != nil {
+				b.Fatal(err)
+			}
+		})
+	})
+}
+
+// measurePeakHeap runs f b.N times, reporting the peak heap in use during
+// a run.
+func measurePeakHeap(b *testing.B, f func()) {
+	b.Helper()
+	var peak uint64
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+		done := make(chan struct{})
+		sampled := make(chan uint64)
+		go func() {
+			sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+			var high uint64
+			tick := time.NewTicker(time.Millisecond)
+			defer tick.Stop()
+			for {
+				metrics.Read(sample)
+				high = max(high, sample[0].Value.Uint64())
+				select {
+				case <-done:
+					sampled <- high
+					return
+				case <-tick.C:
+				}
+			}
+		}()
+		f()
+		close(done)
+		peak = max(peak, <-sampled)
+	}
+	b.ReportMetric(float64(peak), "peak-heap-B")
+}
+
+// writeBenchModule writes a module of n packages, each importing a few
+// large standard library packages and switching on a union of the
+// previous one, and returns its directory.
+func writeBenchModule(b *testing.B, n int) string {
+	b.Helper()
+	dir := b.TempDir()
+	write := func(name, src string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/bench\n\ngo 1.24\n")
+	for i := range n {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "package p%d\n\nimport (\n\t\"go/types\"\n\t\"net/http\"\n\t\"text/template\"\n", i)
+		if i > 0 {
+			fmt.Fprintf(&sb, "\n\t\"example.com/bench/p%d\"\n", i-1)
+		}
+		sb.WriteString(")\n\nvar (\n\t_ = http.ListenAndServe\n\t_ = template.New\n\t_ = types.NewPackage\n)\n")
+		sb.WriteString("\ntype Shape interface{ isShape() }\n\ntype Circle struct{}\ntype Square struct{}\n\nfunc (*Circle) isShape() {}\nfunc (*Square) isShape() {}\n")
+		if i > 0 {
+			fmt.Fprintf(&sb, "\nfunc Name(s p%d.Shape) string {\n\tswitch s.(type) {\n\tcase *p%d.Circle:\n\t\treturn \"circle\"\n\t}\n\treturn \"\"\n}\n", i-1, i-1)
+		}
+		write(fmt.Sprintf("p%d/p%d.go", i, i), sb.String())
+	}
+	return dir
+}
@@ -0,0 +1,63 @@
+package driver
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// checkstyleReport is the root element of a checkstyle XML report.
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+// checkstyleFile lists the diagnostics of a file.
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+// checkstyleError is a diagnostic in a checkstyle report.
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// PrintCheckstyle writes diagnostics as a checkstyle XML report, grouped
+// by file, for tools that consume checkstyle reports of other linters.
+func PrintCheckstyle(w io.Writer, diags []Diagnostic, configs []Config) error {
+	report := checkstyleReport{Version: "5.0"}
+	for _, d := range diags {
+		name := relPath(d.Posn.Filename)
+		if n := len(report.Files); n == 0 || report.Files[n-1].Name != name {
+			report.Files = append(report.Files, checkstyleFile{Name: name})
+		}
+		severity := "error"
+		if d.Info {
+			severity = "info"
+		}
+		f := &report.Files[len(report.Files)-1]
+		f.Errors = append(f.Errors, checkstyleError{
+			Line:     d.Posn.Line,
+			Column:   d.Posn.Column,
+			Severity: severity,
+			Message:  d.Message + configSuffix(d, configs),
+			Source:   "gounion",
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,179 @@
+package driver
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// maxDiffCells bounds the size of the table used to diff the changed
+// region of a file. Larger regions are shown as a single replacement.
+const maxDiffCells = 1 << 22
+
+// opKind is the kind of a line in a diff.
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+// diffLine is a line of a diff, with its 0-based line numbers in the old
+// and new files.
+type diffLine struct {
+	kind     opKind
+	text     string
+	old, new int
+}
+
+// UnifiedDiff returns a unified diff from old to new, with the given
+// file names in its header, or "" if they are equal.
+func UnifiedDiff(oldName, newName string, old, new []byte) string {
+	if bytes.Equal(old, new) {
+		return ""
+	}
+	lines := diffLines(splitLines(old), splitLines(new))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for i := 0; i < len(lines); {
+		if lines[i].kind == opEqual {
+			i++
+			continue
+		}
+		// Extend the hunk while changes are separated by at most
+		// 2*diffContext unchanged lines.
+		start := max(i-diffContext, 0)
+		end := i
+		for j := i; j < len(lines); j++ {
+			if lines[j].kind != opEqual {
+				end = j + 1
+			} else if j-end >= 2*diffContext {
+				break
+			}
+		}
+		end = min(end+diffContext, len(lines))
+		writeHunk(&b, lines[start:end])
+		i = end
+	}
+	return b.String()
+}
+
+// writeHunk writes a hunk of a unified diff.
+func writeHunk(b *strings.Builder, lines []diffLine) {
+	var oldCount, newCount int
+	for _, l := range lines {
+		if l.kind != opInsert {
+			oldCount++
+		}
+		if l.kind != opDelete {
+			newCount++
+		}
+	}
+	oldStart, newStart := lines[0].old+1, lines[0].new+1
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, l := range lines {
+		b.WriteByte(byte(l.kind))
+		b.WriteString(l.text)
+		if !strings.HasSuffix(l.text, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// splitLines splits src into lines, keeping their line terminators.
+func splitLines(src []byte) []string {
+	var lines []string
+	for len(src) > 0 {
+		i := bytes.IndexByte(src, '\n') + 1
+		if i == 0 {
+			i = len(src)
+		}
+		lines = append(lines, string(src[:i]))
+		src = src[i:]
+	}
+	return lines
+}
+
+// diffLines returns a line diff of x and y, computed as a longest common
+// subsequence of the region between their common prefix and suffix.
+func diffLines(x, y []string) []diffLine {
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+	mx, my := x[prefix:len(x)-suffix], y[prefix:len(y)-suffix]
+
+	var lines []diffLine
+	i, j := 0, 0
+	emit := func(kind opKind, text string) {
+		lines = append(lines, diffLine{kind, text, i, j})
+		if kind != opInsert {
+			i++
+		}
+		if kind != opDelete {
+			j++
+		}
+	}
+
+	for _, l := range x[:prefix] {
+		emit(opEqual, l)
+	}
+	if (len(mx)+1)*(len(my)+1) > maxDiffCells {
+		for _, l := range mx {
+			emit(opDelete, l)
+		}
+		for _, l := range my {
+			emit(opInsert, l)
+		}
+	} else {
+		// lcs[a][b] is the length of the longest common subsequence of
+		// mx[a:] and my[b:].
+		lcs := make([][]int, len(mx)+1)
+		for a := range lcs {
+			lcs[a] = make([]int, len(my)+1)
+		}
+		for a := len(mx) - 1; a >= 0; a-- {
+			for b := len(my) - 1; b >= 0; b-- {
+				if mx[a] == my[b] {
+					lcs[a][b] = lcs[a+1][b+1] + 1
+				} else {
+					lcs[a][b] = max(lcs[a+1][b], lcs[a][b+1])
+				}
+			}
+		}
+		a, b := 0, 0
+		for a < len(mx) || b < len(my) {
+			switch {
+			case a < len(mx) && b < len(my) && mx[a] == my[b]:
+				emit(opEqual, mx[a])
+				a++
+				b++
+			case b == len(my) || (a < len(mx) && lcs[a+1][b] >= lcs[a][b+1]):
+				emit(opDelete, mx[a])
+				a++
+			default:
+				emit(opInsert, my[b])
+				b++
+			}
+		}
+	}
+	for _, l := range x[len(x)-suffix:] {
+		emit(opEqual, l)
+	}
+	return lines
+}
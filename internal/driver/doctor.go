@@ -0,0 +1,79 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Doctor returns the steps deciding whether the type named by target, a
+// package pattern and a type name joined by a dot (e.g. "./shape.Shape",
+// "example.com/shape.Shape", or ".Shape" for the package in the current
+// directory), is a union for the analyzer a under its current flags: its
+// marker methods, the types of its package that are members and those
+// rejected, and the options applying to it. It runs the analyzer with its
+// -explain flag set to the type for the duration of the call.
+func Doctor(a *analysis.Analyzer, target string, opts Options) ([]string, error) {
+	i := strings.LastIndex(target, ".")
+	if i <= strings.LastIndex(target, "/") || i == len(target)-1 {
+		return nil, fmt.Errorf("invalid type %q: want <package>.<type>, e.g. ./shape.Shape", target)
+	}
+	pattern, name := target[:i], target[i+1:]
+	if pattern == "" {
+		pattern = "." // as in ".Shape"
+	}
+
+	explain := a.Flags.Lookup("explain")
+	if explain == nil {
+		return nil, errors.New(a.Name + " has no -explain flag")
+	}
+	resolve := opts
+	resolve.Tests = false
+	paths, err := packagePaths([]string{pattern}, resolve)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) != 1 {
+		return nil, fmt.Errorf("%s matches %d packages, want 1", pattern, len(paths))
+	}
+	var path string
+	for p := range paths {
+		path = p
+	}
+
+	defer explain.Value.Set(explain.Value.String())
+	if err := explain.Value.Set(path + "." + name); err != nil {
+		return nil, err
+	}
+	report, err := Check(a, []string{pattern}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if report.Explanation == nil {
+		return nil, fmt.Errorf("package %s declares no type %s", path, name)
+	}
+	return report.Explanation, nil
+}
+
+// PrintDoctor writes the steps returned by Doctor for target, numbered,
+// under a header naming it:
+//
+//	./shape.Shape:
+//		1. Shape is declared at shape.go:5:6
+//		2. method isShape is a marker: it is unexported and has no parameters or results
+//		3. Circle is a member: it has isShape
+//		4. Shape is a union of Circle: type switches on it are checked
+func PrintDoctor(w io.Writer, target string, steps []string) error {
+	if _, err := fmt.Fprintf(w, "%s:\n", target); err != nil {
+		return err
+	}
+	for i, step := range steps {
+		if _, err := fmt.Fprintf(w, "\t%d. %s\n", i+1, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,1039 @@
+// Package driver implements the standalone gounion command: it loads
+// packages with go/packages, runs the analyzer on them, and prints the
+// resulting diagnostics.
+//
+// Unlike singlechecker, it can load and check the same packages under
+// several build configurations in one run, merging their diagnostics.
+package driver
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"hash/fnv"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/YuitoSato/gounion/gounion"
+	"github.com/YuitoSato/gounion/internal/refactor"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/unitchecker"
+	"golang.org/x/tools/go/packages"
+)
+
+// Config is a build configuration to load packages under.
+// Empty fields default to the host's GOOS and GOARCH.
+type Config struct {
+	GOOS   string
+	GOARCH string
+}
+
+// String returns the configuration in GOOS/GOARCH form.
+func (c Config) String() string {
+	return c.GOOS + "/" + c.GOARCH
+}
+
+// ParseConfigs parses a comma-separated list of GOOS/GOARCH pairs,
+// e.g. "linux/amd64,windows/amd64".
+func ParseConfigs(s string) ([]Config, error) {
+	var configs []Config
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		goos, goarch, ok := strings.Cut(item, "/")
+		if !ok || goos == "" || goarch == "" {
+			return nil, fmt.Errorf("invalid build configuration %q: want GOOS/GOARCH", item)
+		}
+		configs = append(configs, Config{GOOS: goos, GOARCH: goarch})
+	}
+	return configs, nil
+}
+
+// Shard selects the packages analyzed by one of Count workers.
+// The zero value selects all packages.
+type Shard struct {
+	Index int // 0 <= Index < Count
+	Count int
+}
+
+// ParseShard parses a shard in "i/n" form, e.g. "0/4".
+func ParseShard(s string) (Shard, error) {
+	if s == "" {
+		return Shard{}, nil
+	}
+	i, n, ok := strings.Cut(s, "/")
+	index, err1 := strconv.Atoi(i)
+	count, err2 := strconv.Atoi(n)
+	if !ok || err1 != nil || err2 != nil || count < 1 || index < 0 || index >= count {
+		return Shard{}, fmt.Errorf("invalid shard %q: want i/n with 0 <= i < n", s)
+	}
+	return Shard{Index: index, Count: count}, nil
+}
+
+// includes reports whether the shard analyzes pkg. Packages are assigned by
+// a hash of their path, so the assignment is deterministic and does not
+// depend on which other packages are loaded; all variants of a package
+// (with and without tests) are assigned to the same shard.
+func (s Shard) includes(pkg *packages.Package) bool {
+	if s.Count <= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(strings.TrimSuffix(pkg.PkgPath, "_test")))
+	return int(h.Sum32()%uint32(s.Count)) == s.Index
+}
+
+// Options controls a run of the driver.
+type Options struct {
+	Configs []Config // build configurations to check; nil means the host configuration
+	Tests   bool     // also check test packages
+	Dir     string   // directory to load packages from; empty means the current directory
+
+	// PackagesDriver is the go/packages driver program loading the
+	// packages, as for GOPACKAGESDRIVER, so that packages can be loaded
+	// from a build system such as Bazel instead of the go command ("off"
+	// selects the go command). Empty means GOPACKAGESDRIVER, or a
+	// gopackagesdriver program on the PATH if it is not set. The driver
+	// is passed GOOS and GOARCH of the configurations in its environment.
+	PackagesDriver string
+
+	// NoFacts reports that the analyzer needs no facts from dependencies
+	// (as with gounion's -lazy-facts). The analyzer then runs on the
+	// requested packages only, and dependencies are type-checked from
+	// source without their function bodies and without recording type
+	// information, their syntax dropped as soon as they are checked.
+	NoFacts bool
+
+	// Shard restricts the run to a deterministic subset of the requested
+	// packages. Each shard type-checks the requested packages of its own
+	// and their dependencies, and derives the facts of dependencies itself
+	// unless FactsDir holds them, e.g. written by a shard analyzing them.
+	Shard Shard
+
+	// FactsDir is a directory of fact artifacts: the facts of every package
+	// analyzed are written to it, and those of dependencies are read from
+	// it instead of analyzing them again if their sources, dependencies,
+	// analyzer and flags are unchanged. Shards and successive runs sharing
+	// it thus exchange facts. Empty means no artifacts.
+	FactsDir string
+
+	// MemberSites additionally reports, at the declaration of each union
+	// member missing from type switches of the analyzed packages, an
+	// informational diagnostic listing those switches, so that the author
+	// of a new member sees which switches need updating. Members declared
+	// outside the analyzed packages are not reported.
+	MemberSites bool
+
+	// OnPackage, if set, is called with the new diagnostics of each package
+	// as soon as its analysis finishes, possibly concurrently with the
+	// analysis of other packages but never concurrently with itself. It is
+	// only used when checking a single configuration, as merging
+	// configurations requires all of their results.
+	OnPackage func([]Diagnostic)
+
+	// Dependents are patterns of packages loaded and analyzed along with
+	// the requested ones, such as those of other modules of a go.work
+	// workspace, in which only the switches, errors.As chains and other uses of the unions
+	// declared in the requested packages are reported, so that a change
+	// to a union reports every place it breaks in the loaded graph. Their
+	// own unions and other diagnostics are left out. OnPackage is not
+	// called for them.
+	Dependents []string
+}
+
+// Diagnostic is a diagnostic reported in one or more build configurations.
+type Diagnostic struct {
+	Posn     token.Position
+	Message  string
+	Category string   // category of the analyzer's diagnostic, if any
+	Configs  []Config // configurations reporting the diagnostic, in Options.Configs order
+	Edits    []Edit   // edits of the diagnostic's first suggested fix, if any
+	Fix      string   // message of the first suggested fix, if any
+	Info     bool     // informational, as reported for Options.MemberSites
+	Related  []Related
+
+	// Union, UnionPkg and Missing are the union or enum the diagnostic is
+	// about, the path of the package declaring it, and the members it
+	// reports missing, as in gounion.Finding.
+	Union    string
+	UnionPkg string
+	Missing  []string
+}
+
+// Related is related information of a diagnostic, e.g. the members left
+// out of a truncated list of missing members.
+type Related struct {
+	Posn    token.Position
+	Message string
+}
+
+// Edit is a text edit of a suggested fix, replacing the bytes
+// [Start, End) of a file with NewText.
+type Edit struct {
+	Filename   string
+	Start, End int // byte offsets
+	NewText    string
+}
+
+// Switch is a type switch on a union checked by the analyzer, as listed
+// in a gounion.Result.
+type Switch struct {
+	Package  string // package path
+	Posn     token.Position
+	Union    string
+	UnionPkg string   // path of the package declaring the union
+	Func     string   // enclosing function, if any
+	Handled  []string // members with a case
+	Missing  []string // members without a case
+	Default  bool     // whether the switch has a default case
+
+	// DefaultAccepted reports that the default case handles the missing
+	// members, as in gounion.CheckedSwitch.
+	DefaultAccepted bool
+}
+
+// Use is code other than a type switch depending on the members of a
+// union, as listed in a gounion.Result.
+type Use struct {
+	Package  string // package path
+	Posn     token.Position
+	Kind     string // as in gounion.UnionUse, e.g. "literal"
+	Union    string
+	UnionPkg string // path of the package declaring the union
+	Func     string // enclosing function, if any
+}
+
+// Union is a union declared in a checked package, as listed in a
+// gounion.Result.
+type Union struct {
+	Package string // package path
+	Name    string
+	Members []string // unqualified, e.g. "*Circle"
+}
+
+// Report is the outcome of a run of the driver.
+type Report struct {
+	Diagnostics []Diagnostic
+	Switches    []Switch // checked switches, if the analyzer lists them
+	Uses        []Use    // other uses of unions, if the analyzer lists them
+	Unions      []Union  // unions declared in the checked packages, if the analyzer lists them
+	Configs     []Config // configurations checked, as in Options.Configs
+
+	// Explanation holds the steps deciding whether the type named by the
+	// analyzer's -explain flag is a union, if a checked package declares it.
+	Explanation []string
+}
+
+// Run loads the packages matching patterns under each configuration, runs
+// the analyzer on them and returns the merged diagnostics sorted by
+// position. Diagnostics reported identically in several configurations
+// (or in several variants of a package) are reported once.
+func Run(a *analysis.Analyzer, patterns []string, opts Options) ([]Diagnostic, error) {
+	r, err := Check(a, patterns, opts)
+	if err != nil {
+		return nil, err
+	}
+	return r.Diagnostics, nil
+}
+
+// Check is like Run, but also returns the switches checked by the
+// analyzer, merged and sorted like the diagnostics.
+func Check(a *analysis.Analyzer, patterns []string, opts Options) (*Report, error) {
+	configs := opts.Configs
+	if len(configs) == 0 {
+		configs = []Config{{}}
+	}
+
+	index := make(map[diagKey]int)
+	var diags []Diagnostic
+	seen := make(map[token.Position]bool)
+	var switches []Switch
+	seenUnions := make(map[string]bool)
+	var unions []Union
+	seenUses := make(map[useKey]bool)
+	var uses []Use
+	var explanation []string
+	sites := make(map[token.Position]*memberSite)
+
+	loaded := patterns
+	var own map[string]bool // paths of the requested packages, if dependents are loaded too
+	if len(opts.Dependents) > 0 {
+		var err error
+		if own, err = packagePaths(patterns, opts); err != nil {
+			return nil, err
+		}
+		loaded = append(slices.Clip(patterns), opts.Dependents...)
+	}
+
+	for _, c := range configs {
+		pkgs, err := load(loaded, c, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		analyzer := a
+		if opts.NoFacts {
+			factless := *a
+			factless.FactTypes = nil
+			analyzer = &factless
+		}
+		if opts.OnPackage != nil && len(configs) == 1 && own == nil {
+			s := &streamer{
+				roots:     make(map[string]bool, len(pkgs)),
+				onPackage: opts.OnPackage,
+				seen:      make(map[diagKey]bool),
+			}
+			for _, pkg := range pkgs {
+				s.roots[pkg.PkgPath] = true
+			}
+			analyzer = s.wrap(analyzer)
+		}
+
+		roots, err := analyze(analyzer, pkgs, c, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		rootFiles := make(map[string]bool)
+		for _, pkg := range pkgs {
+			for _, name := range pkg.CompiledGoFiles {
+				rootFiles[name] = true
+			}
+		}
+
+		for _, act := range roots {
+			if act.Err != nil {
+				return nil, fmt.Errorf("%s: %v", act.Package.PkgPath, act.Err)
+			}
+			result, _ := act.Result.(*gounion.Result)
+			var kept map[token.Position]bool // positions reported in a dependent
+			if own != nil && !own[act.Package.PkgPath] {
+				kept = dependentSites(act.Package.Fset, result, own)
+			}
+			for _, d := range act.Diagnostics {
+				k := diagKey{act.Package.Fset.Position(d.Pos), d.Message}
+				if kept != nil && !kept[k.posn] {
+					continue
+				}
+				i, ok := index[k]
+				if !ok {
+					i = len(diags)
+					index[k] = i
+					diags = append(diags, Diagnostic{Posn: k.posn, Message: k.message, Category: d.Category, Edits: fixEdits(act.Package.Fset, d)})
+					if len(d.SuggestedFixes) > 0 {
+						diags[i].Fix = d.SuggestedFixes[0].Message
+					}
+					if f := findingOf(result, d); f != nil {
+						diags[i].Union, diags[i].UnionPkg, diags[i].Missing = f.Union, f.UnionPkg, f.Missing
+					}
+					for _, r := range d.Related {
+						diags[i].Related = append(diags[i].Related, Related{Posn: act.Package.Fset.Position(r.Pos), Message: r.Message})
+					}
+				}
+				if n := len(diags[i].Configs); n == 0 || diags[i].Configs[n-1] != c {
+					diags[i].Configs = append(diags[i].Configs, c)
+				}
+			}
+			if result != nil {
+				if explanation == nil && kept == nil {
+					explanation = result.Explanation
+				}
+				for _, u := range result.Unions {
+					// Test variants and other configurations declare the unions again.
+					key := act.Package.PkgPath + "." + u.Name
+					if kept == nil && !seenUnions[key] {
+						seenUnions[key] = true
+						unions = append(unions, Union{Package: act.Package.PkgPath, Name: u.Name, Members: u.Members})
+					}
+				}
+				for _, u := range result.Uses {
+					k := useKey{act.Package.Fset.Position(u.Pos), u.Kind, u.Union}
+					if kept != nil && !own[u.UnionPkg] {
+						continue
+					}
+					if !seenUses[k] {
+						seenUses[k] = true
+						uses = append(uses, Use{
+							Package:  act.Package.PkgPath,
+							Posn:     k.posn,
+							Kind:     u.Kind,
+							Union:    u.Union,
+							UnionPkg: u.UnionPkg,
+							Func:     u.Func,
+						})
+					}
+				}
+				for _, sw := range result.Switches {
+					posn := act.Package.Fset.Position(sw.Pos)
+					if kept != nil && !own[sw.UnionPkg] {
+						continue
+					}
+					if !seen[posn] {
+						seen[posn] = true
+						switches = append(switches, Switch{
+							Package:  act.Package.PkgPath,
+							Posn:     posn,
+							Union:    sw.Union,
+							UnionPkg: sw.UnionPkg,
+							Func:     sw.Func,
+							Handled:  sw.Handled,
+							Missing:  sw.Missing,
+							Default:  sw.Default,
+
+							DefaultAccepted: sw.DefaultAccepted,
+						})
+					}
+					if !opts.MemberSites || sw.DefaultAccepted {
+						continue
+					}
+					for i, pos := range sw.MissingPos {
+						decl := act.Package.Fset.Position(pos)
+						if !pos.IsValid() || !rootFiles[decl.Filename] {
+							continue
+						}
+						site := sites[decl]
+						if site == nil {
+							site = &memberSite{member: sw.Missing[i], union: sw.Union, switches: make(map[token.Position]bool)}
+							sites[decl] = site
+						}
+						site.switches[posn] = true
+						if n := len(site.configs); n == 0 || site.configs[n-1] != c {
+							site.configs = append(site.configs, c)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for decl, site := range sites {
+		diags = append(diags, site.diagnostic(decl))
+	}
+
+	sortDiagnostics(diags)
+	sort.SliceStable(switches, func(i, j int) bool {
+		return positionLess(switches[i].Posn, switches[j].Posn)
+	})
+
+	sort.SliceStable(uses, func(i, j int) bool {
+		return positionLess(uses[i].Posn, uses[j].Posn)
+	})
+
+	sort.Slice(unions, func(i, j int) bool {
+		if unions[i].Package != unions[j].Package {
+			return unions[i].Package < unions[j].Package
+		}
+		return unions[i].Name < unions[j].Name
+	})
+
+	return &Report{Diagnostics: diags, Switches: switches, Uses: uses, Unions: unions, Configs: opts.Configs, Explanation: explanation}, nil
+}
+
+// packagePaths returns the paths of the packages matching patterns, with
+// their test packages if opts.Tests is set.
+func packagePaths(patterns []string, opts Options) (map[string]bool, error) {
+	cfg := &packages.Config{
+		Mode:  packages.NeedName,
+		Tests: opts.Tests,
+		Dir:   opts.Dir,
+		Env:   os.Environ(),
+	}
+	if opts.PackagesDriver != "" {
+		cfg.Env = append(cfg.Env, "GOPACKAGESDRIVER="+opts.PackagesDriver)
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		paths[pkg.PkgPath] = true
+	}
+	return paths, nil
+}
+
+// dependentSites returns the positions of the switches, other uses and
+// findings, such as errors.As chains, in the analysis result of a
+// dependent package, of the unions declared in the packages own, whose
+// diagnostics are reported.
+func dependentSites(fset *token.FileSet, result *gounion.Result, own map[string]bool) map[token.Position]bool {
+	sites := make(map[token.Position]bool)
+	if result == nil {
+		return sites
+	}
+	for _, sw := range result.Switches {
+		if own[sw.UnionPkg] {
+			sites[fset.Position(sw.Pos)] = true
+		}
+	}
+	for _, u := range result.Uses {
+		if own[u.UnionPkg] {
+			sites[fset.Position(u.Pos)] = true
+		}
+	}
+	for _, f := range result.Findings {
+		if own[f.UnionPkg] {
+			sites[fset.Position(f.Pos)] = true
+		}
+	}
+	return sites
+}
+
+// useKey identifies a use of a union across packages and configurations.
+type useKey struct {
+	posn  token.Position
+	kind  string
+	union string
+}
+
+// memberSite collects the switches missing a union member, for
+// Options.MemberSites.
+type memberSite struct {
+	member   string // qualified, as in diagnostics
+	union    string
+	switches map[token.Position]bool
+	configs  []Config
+}
+
+// diagnostic returns the informational diagnostic reported at the
+// member's declaration decl.
+func (s *memberSite) diagnostic(decl token.Position) Diagnostic {
+	positions := make([]token.Position, 0, len(s.switches))
+	for posn := range s.switches {
+		positions = append(positions, posn)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positionLess(positions[i], positions[j])
+	})
+	names := make([]string, len(positions))
+	for i, posn := range positions {
+		names[i] = fmt.Sprintf("%s:%d:%d", relPath(posn.Filename), posn.Line, posn.Column)
+	}
+
+	noun := "type switch"
+	if len(names) > 1 {
+		noun = "type switches"
+	}
+	return Diagnostic{
+		Posn:    decl,
+		Message: fmt.Sprintf("%s is missing from %d %s on %s: %s", s.member, len(names), noun, s.union, strings.Join(names, ", ")),
+		Configs: s.configs,
+		Info:    true,
+	}
+}
+
+// findingOf returns the finding of result recording d, or nil.
+func findingOf(result *gounion.Result, d analysis.Diagnostic) *gounion.Finding {
+	if result == nil {
+		return nil
+	}
+	for i, f := range result.Findings {
+		if f.Pos == d.Pos && f.Message == d.Message {
+			return &result.Findings[i]
+		}
+	}
+	return nil
+}
+
+// fixEdits returns the edits of the first suggested fix of d, in the files
+// they edit, ignoring //line directives.
+func fixEdits(fset *token.FileSet, d analysis.Diagnostic) []Edit {
+	if len(d.SuggestedFixes) == 0 {
+		return nil
+	}
+	var edits []Edit
+	for _, e := range d.SuggestedFixes[0].TextEdits {
+		start := fset.PositionFor(e.Pos, false)
+		end := start
+		if e.End.IsValid() {
+			end = fset.PositionFor(e.End, false)
+		}
+		edits = append(edits, Edit{Filename: start.Filename, Start: start.Offset, End: end.Offset, NewText: string(e.NewText)})
+	}
+	return edits
+}
+
+// load loads the packages matching patterns under the configuration c.
+func load(patterns []string, c Config, opts Options) ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode:  loadMode,
+		Tests: opts.Tests,
+		Dir:   opts.Dir,
+		Env:   loadEnv(c, opts),
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if n := packages.PrintErrors(pkgs); n > 0 {
+		return nil, loadError(n, c, opts)
+	}
+
+	if opts.Shard.Count > 1 {
+		var selected []*packages.Package
+		for _, pkg := range pkgs {
+			if opts.Shard.includes(pkg) {
+				selected = append(selected, pkg)
+			}
+		}
+		pkgs = selected
+	}
+	return pkgs, nil
+}
+
+// loadEnv returns the environment of the go/packages driver loading the
+// packages under the configuration c.
+func loadEnv(c Config, opts Options) []string {
+	env := os.Environ()
+	if c.GOOS != "" {
+		env = append(env, "GOOS="+c.GOOS)
+	}
+	if c.GOARCH != "" {
+		env = append(env, "GOARCH="+c.GOARCH)
+	}
+	if opts.PackagesDriver != "" {
+		env = append(env, "GOPACKAGESDRIVER="+opts.PackagesDriver)
+	}
+	return env
+}
+
+// loadError returns the error of n errors, printed, loading or
+// type-checking the packages under the configuration c.
+func loadError(n int, c Config, opts Options) error {
+	if driver := packagesDriver(loadEnv(c, opts)); driver != "" {
+		return fmt.Errorf("%d errors loading packages with GOPACKAGESDRIVER=%s", n, driver)
+	}
+	return fmt.Errorf("%d errors loading packages", n)
+}
+
+// loadMode is the go/packages load mode of the driver: the package graph
+// with the files of each package, its sizes and module information for
+// pass.Module, but neither syntax nor types, which analyze derives one
+// package at a time (type-checking from source avoids depending on the
+// export data format of the go command).
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+	packages.NeedImports | packages.NeedDeps | packages.NeedTypesSizes | packages.NeedModule
+
+// packagesDriver returns the go/packages driver program selected by env,
+// or "" for the go command. Unlike go/packages, it does not look for a
+// gopackagesdriver program on the PATH.
+func packagesDriver(env []string) string {
+	driver := ""
+	for _, kv := range env {
+		if v, ok := strings.CutPrefix(kv, "GOPACKAGESDRIVER="); ok {
+			driver = v
+		}
+	}
+	if driver == "off" {
+		return ""
+	}
+	return driver
+}
+
+// Main is the main function of the standalone command for analyzer a.
+// It exits with status 3 if diagnostics were reported and 1 on errors,
+// like singlechecker. Informational diagnostics, as reported for
+// Options.MemberSites, do not change the exit status.
+//
+// Run as "<command> fix [-flag] [package]", it applies the suggested fixes
+// instead, with one diagnostic per missing member unless -per-member is
+// set explicitly, so that missing cases are fixed too. Run as "<command>
+// list [-flag] [package]", it lists the checked type switches with their
+// handled and missing members; its -union flag, selecting the union to
+// list, replaces the analyzer's. Run as "<command> graph [-flag]
+// [package]", it prints the unions, their members and the functions
+// switching on them as a Graphviz DOT graph. Run as "<command> impact
+// -union=U -member=M [-flag] [package]", it lists the code to update if the
+// member M is added to the union U. Run as "<command> diff -base=REV
+// [-flag] [package]", it prints the members added to and removed from each
+// union since the git revision REV, with the switches on the changed
+// unions. Run as "<command> doctor [-flag] package.Type", it explains step
+// by step whether the type is a union under the flags and why, e.g. which
+// of its methods are markers and why other types of its package are not
+// members. Run as "<command> untag -type=T [-flag]
+// [package]" or "<command> seal -interface=I -types=T,... [-flag]
+// [package]", it applies the fixes of the codemod refactor.Untag or
+// refactor.Seal instead of those of a, and reports the code left to rewrite
+// by hand.
+//
+// Packages are named by patterns, by Go files, or by lists of them read
+// from stdin for the argument "-" and from a file for "@file", also
+// accepted by -files (see ExpandPatterns).
+func Main(a *analysis.Analyzer) {
+	var command string
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "fix", "list", "graph", "impact", "diff", "doctor", "seal", "untag":
+			command = os.Args[1]
+		}
+	}
+	var (
+		dryRun     *bool
+		categories *string
+		union      *string
+		member     *string
+		base       *string
+	)
+	checked := a
+	switch command {
+	case "seal", "untag":
+		checked = codemods[command]
+		dryRun = flag.Bool("dry-run", false, "print the rewrite as a unified diff instead of applying it")
+	case "fix":
+		dryRun = flag.Bool("dry-run", false, "print the fixes as a unified diff instead of applying them")
+		categories = flag.String("categories", "", "comma-separated categories of the diagnostics to fix, e.g. switch,enum (default all)")
+	case "list":
+		union = flag.String("union", "", "list only the switches on the union with this name, e.g. Shape")
+	case "impact":
+		union = flag.String("union", "", "name of the union the member is added to, e.g. Shape, or example.com/shape.Shape if several unions have that name")
+		member = flag.String("member", "", "the member to add, as written in case clauses, e.g. *Pentagon")
+	case "diff":
+		base = flag.String("base", "", "git revision to compare union members with, e.g. origin/main")
+	}
+	var (
+		files      = flag.String("files", "", "comma-separated Go files or packages to check, or @file to read them from a file, one per line")
+		configs    = flag.String("configs", "", "comma-separated GOOS/GOARCH build configurations to check, e.g. linux/amd64,windows/amd64")
+		dependents = flag.String("dependents", "", "comma-separated patterns of packages, e.g. of other workspace modules, also checked for switches on the unions of the packages")
+		pkgDriver  = flag.String("packages-driver", "", "go/packages driver program loading the packages, e.g. from Bazel (default $GOPACKAGESDRIVER; off selects the go command)")
+		shard      = flag.String("shard", "", "analyze only shard i of n of the packages, e.g. 0/4")
+		factsDir   = flag.String("facts-dir", "", "directory of fact artifacts to reuse and write, e.g. shared by shards")
+		tests      = flag.Bool("test", true, "also check test packages")
+		formatName = flag.String("format", "text", "output format: "+strings.Join(formatNames(), ", "))
+		jsonOut    = flag.Bool("json", false, "print diagnostics as JSON (same as -format=json)")
+		diff       = flag.Bool("diff", false, "print the suggested fixes as a unified diff instead of the diagnostics they fix")
+		fix        = flag.Bool("fix", false, "apply the suggested fixes")
+		sites      = flag.Bool("member-sites", false, "also report, at each member missing from switches, the switches missing it")
+		cpuprofile = flag.String("cpuprofile", "", "write CPU profile to this file")
+		memprofile = flag.String("memprofile", "", "write memory profile to this file")
+		traceFile  = flag.String("trace", "", "write trace log to this file")
+	)
+	flag.Var(versionFlag{}, "V", "print version and exit")
+	flag.Var(flagsFlag{}, "flags", "print analyzer flags in JSON")
+	checked.Flags.VisitAll(func(f *flag.Flag) {
+		// Subcommand flags, such as -union of list, shadow analyzer flags.
+		if flag.Lookup(f.Name) == nil {
+			flag.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+
+	flag.Usage = func() {
+		paras := strings.Split(a.Doc, "\n\n")
+		fmt.Fprintf(os.Stderr, "%s: %s\n\n", a.Name, paras[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [-flag] [package]\n", a.Name)
+		fmt.Fprintf(os.Stderr, "       %s fix [-flag] [package]\n", a.Name)
+		fmt.Fprintf(os.Stderr, "       %s list [-flag] [package]\n", a.Name)
+		fmt.Fprintf(os.Stderr, "       %s graph [-flag] [package]\n", a.Name)
+		fmt.Fprintf(os.Stderr, "       %s impact -union=U -member=M [-flag] [package]\n", a.Name)
+		fmt.Fprintf(os.Stderr, "       %s diff -base=REV [-flag] [package]\n", a.Name)
+		fmt.Fprintf(os.Stderr, "       %s doctor [-flag] package.Type\n", a.Name)
+		fmt.Fprintf(os.Stderr, "       %s seal -interface=I -types=T,... [-flag] [package]\n", a.Name)
+		fmt.Fprintf(os.Stderr, "       %s untag -type=T [-flag] [package]\n\n", a.Name)
+		if len(paras) > 1 {
+			fmt.Fprintln(os.Stderr, strings.Join(paras[1:], "\n\n"))
+		}
+		fmt.Fprintln(os.Stderr, "\nFlags:")
+		flag.PrintDefaults()
+	}
+	if command != "" {
+		flag.CommandLine.Parse(os.Args[2:])
+	} else {
+		flag.Parse()
+	}
+
+	args := append(flag.Args(), splitList(*files)...)
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// Invoked by go vet -vettool.
+	if len(args) == 1 && strings.HasSuffix(args[0], ".cfg") {
+		unitchecker.Run(args[0], []*analysis.Analyzer{a})
+		panic("unreachable")
+	}
+
+	if *jsonOut {
+		*formatName = "json"
+	}
+	f, ok := formats[*formatName]
+	if !ok {
+		fatalf("unknown format %q: want one of %s", *formatName, strings.Join(formatNames(), ", "))
+	}
+
+	args, err := ExpandPatterns(args, os.Stdin)
+	if err != nil {
+		fatalf("%s: %v", a.Name, err)
+	}
+	if len(args) == 0 {
+		os.Exit(0) // An empty list, as of a change touching no Go files
+	}
+
+	opts := Options{Tests: *tests, MemberSites: *sites, PackagesDriver: *pkgDriver, Dependents: splitList(*dependents), FactsDir: *factsDir}
+	if f := checked.Flags.Lookup("lazy-facts"); f != nil && f.Value.String() == "true" {
+		opts.NoFacts = true
+	}
+	if opts.Configs, err = ParseConfigs(*configs); err != nil {
+		fatalf("%v", err)
+	}
+	if opts.Shard, err = ParseShard(*shard); err != nil {
+		fatalf("%v", err)
+	}
+
+	if command == "impact" && (*union == "" || *member == "") {
+		fatalf("%s impact: -union and -member are required", a.Name)
+	}
+	if command == "diff" && *base == "" {
+		fatalf("%s diff: -base is required", a.Name)
+	}
+	if command == "doctor" && len(args) != 1 {
+		fatalf("%s doctor: want one type, e.g. ./shape.Shape", a.Name)
+	}
+
+	if *cpuprofile != "" {
+		out, err := os.Create(*cpuprofile)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		if err := pprof.StartCPUProfile(out); err != nil {
+			fatalf("%v", err)
+		}
+	}
+	if *traceFile != "" {
+		out, err := os.Create(*traceFile)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		if err := trace.Start(out); err != nil {
+			fatalf("%v", err)
+		}
+	}
+
+	// Every command returns here, rather than exiting, so that the
+	// profiles and trace are written.
+	var code int
+	switch command {
+	case "doctor":
+		code = doctor(a, args[0], opts)
+	case "list", "graph", "impact", "diff":
+		code = inspect(a, command, args, opts, union, member, base)
+	default:
+		mode := fixMode{diff: *diff, apply: *fix}
+		if command == "fix" {
+			mode = fixMode{diff: *dryRun, apply: !*dryRun, categories: splitList(*categories)}
+			if !flagSet("per-member") {
+				if f := a.Flags.Lookup("per-member"); f != nil {
+					f.Value.Set("true")
+				}
+			}
+		}
+		if codemods[command] != nil {
+			mode = fixMode{diff: *dryRun, apply: !*dryRun}
+		}
+		code = run(checked, args, opts, f, mode)
+	}
+
+	pprof.StopCPUProfile()
+	trace.Stop()
+	if *memprofile != "" {
+		out, err := os.Create(*memprofile)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(out); err != nil {
+			fatalf("%v", err)
+		}
+		out.Close()
+	}
+	os.Exit(code)
+}
+
+// doctor runs the doctor subcommand of Main on the type named typ,
+// returning the exit status.
+func doctor(a *analysis.Analyzer, typ string, opts Options) int {
+	steps, err := Doctor(a, typ, opts)
+	if err == nil {
+		err = PrintDoctor(os.Stdout, typ, steps)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s doctor: %v\n", a.Name, err)
+		return 1
+	}
+	return 0
+}
+
+// inspect runs the list, graph, impact or diff subcommand of Main, with
+// the flags of that subcommand, returning the exit status.
+func inspect(a *analysis.Analyzer, command string, patterns []string, opts Options, union, member, base *string) int {
+	report, err := Check(a, patterns, opts)
+	if err == nil {
+		switch command {
+		case "list":
+			err = PrintList(os.Stdout, report.Switches, *union)
+		case "graph":
+			err = PrintGraph(os.Stdout, report)
+		case "impact":
+			err = PrintImpact(os.Stdout, report, *union, *member)
+		case "diff":
+			var old *Report
+			if old, err = CheckRevision(a, patterns, opts, *base); err == nil {
+				err = PrintUnionDiff(os.Stdout, DiffUnions(old, report))
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", a.Name, err)
+		return 1
+	}
+	return 0
+}
+
+// codemods are the analyzers run by the codemod subcommands of Main.
+var codemods = map[string]*analysis.Analyzer{
+	"seal":  refactor.Seal,
+	"untag": refactor.Untag,
+}
+
+// fixMode selects what is done with suggested fixes.
+type fixMode struct {
+	diff       bool     // print them as a unified diff
+	apply      bool     // write them to the files
+	categories []string // categories of the diagnostics to fix; empty means all
+}
+
+// flagSet reports whether the named flag was set on the command line.
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// failing reports whether diags has diagnostics failing a run: those that
+// are not informational.
+func failing(diags []Diagnostic) bool {
+	return slices.ContainsFunc(diags, func(d Diagnostic) bool { return !d.Info })
+}
+
+// run runs the driver and prints its diagnostics in format f, returning
+// the exit code. Diagnostics of a single configuration are printed as each
+// package completes if the format allows it and fixes are not requested.
+//
+// With fixes requested, only the diagnostics without an applicable fix
+// are printed. The exit code is then 3 if the diff is not empty or, when
+// applying fixes, if diagnostics other than informational ones remain
+// unfixed.
+func run(a *analysis.Analyzer, patterns []string, opts Options, f format, fix fixMode) int {
+	w := f.writer()
+	fixing := fix.diff || fix.apply
+	streaming := f.stream && len(opts.Configs) <= 1 && !fixing && len(opts.Dependents) == 0
+	if streaming {
+		opts.OnPackage = func(diags []Diagnostic) {
+			f.print(w, &Report{Diagnostics: diags})
+		}
+	}
+
+	report, err := Check(a, patterns, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", a.Name, err)
+		return 1
+	}
+	diags := report.Diagnostics
+	if streaming && opts.MemberSites {
+		// Member sites are only known once all packages are done.
+		var info []Diagnostic
+		for _, d := range diags {
+			if d.Info {
+				info = append(info, d)
+			}
+		}
+		f.print(w, &Report{Diagnostics: info})
+	}
+
+	failed := failing(diags) && !f.zeroExit
+	if fixing {
+		fixed, err := ApplyFixes(diags, fix.categories...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", a.Name, err)
+			return 1
+		}
+		if err := writeFixes(fixed, fix); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", a.Name, err)
+			return 1
+		}
+		report.Diagnostics = fixed.Unfixed
+		failed = failing(fixed.Unfixed) || (fix.diff && !fix.apply && len(fixed.Files) > 0)
+	}
+
+	if !streaming {
+		if err := f.print(w, report); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", a.Name, err)
+			return 1
+		}
+	}
+	if failed {
+		return 3
+	}
+	return 0
+}
+
+// writeFixes prints the fixed files as a unified diff to stdout and
+// writes them, as selected by fix. Diff file names are relative to the
+// current directory, so that the output applies with patch -p1 or
+// git apply from there.
+func writeFixes(fixed *Fixed, fix fixMode) error {
+	names := make([]string, 0, len(fixed.Files))
+	for name := range fixed.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if fix.diff {
+			old, err := os.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			rel := relPath(name)
+			fmt.Print(UnifiedDiff("a/"+rel, "b/"+rel, old, fixed.Files[name]))
+		}
+		if fix.apply {
+			info, err := os.Stat(name)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(name, fixed.Files[name], info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fatalf prints an error message and exits with status 1.
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
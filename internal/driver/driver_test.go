@@ -0,0 +1,1171 @@
+package driver_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/YuitoSato/gounion/gounion"
+	"github.com/YuitoSato/gounion/internal/driver"
+	"golang.org/x/tools/go/packages"
+)
+
+// driverLog is the environment variable making the test binary act as a
+// go/packages driver, appending the patterns of each request to the named
+// file.
+const driverLog = "GOUNION_TEST_DRIVER_LOG"
+
+// runMain is the environment variable making the test binary act as the
+// standalone gounion command, run by driver.Main.
+const runMain = "GOUNION_TEST_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMain) != "" {
+		driver.Main(gounion.Analyzer)
+	}
+	if log := os.Getenv(driverLog); log != "" {
+		if err := packagesDriver(log, os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// packagesDriver answers the go/packages driver request on stdin with the
+// packages loaded by the go command.
+func packagesDriver(log string, patterns []string) error {
+	var req packages.DriverRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(log, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(f, strings.Join(patterns, " "))
+	f.Close()
+
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedDeps,
+		Env:   append(req.Env, "GOPACKAGESDRIVER=off"),
+		Tests: req.Tests,
+	}, patterns...)
+	if err != nil {
+		return err
+	}
+	resp := packages.DriverResponse{Compiler: "gc", Arch: runtime.GOARCH}
+	for _, pkg := range pkgs {
+		resp.Roots = append(resp.Roots, pkg.ID)
+	}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		resp.Packages = append(resp.Packages, pkg)
+	})
+	return json.NewEncoder(os.Stdout).Encode(resp)
+}
+
+// command runs the standalone gounion command with args in dir, returning
+// its standard output and exit status.
+func command(t *testing.T, dir string, args ...string) (string, int) {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), runMain+"=1")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err = cmd.Run()
+	var exit *exec.ExitError
+	if err != nil && !errors.As(err, &exit) {
+		t.Fatal(err)
+	}
+	if code := cmd.ProcessState.ExitCode(); code != 0 && code != 3 {
+		t.Fatalf("gounion %s exited with status %d:\n%s", strings.Join(args, " "), code, stderr.Bytes())
+	}
+	return stdout.String(), cmd.ProcessState.ExitCode()
+}
+
+// writeModule writes a module example.com/<name> with the given files to
+// a temporary directory and returns it.
+func writeModule(t *testing.T, name string, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/" + name + "\n\ngo 1.24\n"
+	for file, src := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestParseConfigs(t *testing.T) {
+	got, err := driver.ParseConfigs("linux/amd64, windows/arm64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []driver.Config{{GOOS: "linux", GOARCH: "amd64"}, {GOOS: "windows", GOARCH: "arm64"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("ParseConfigs = %v, want %v", got, want)
+	}
+
+	if _, err := driver.ParseConfigs("linux"); err == nil {
+		t.Error("ParseConfigs(\"linux\") succeeded, want error")
+	}
+}
+
+func TestRunConfigs(t *testing.T) {
+	configs := []driver.Config{{GOOS: "linux", GOARCH: "amd64"}, {GOOS: "windows", GOARCH: "amd64"}}
+	diags, err := driver.Run(gounion.Analyzer, []string{"."}, driver.Options{
+		Configs: configs,
+		Dir:     "testdata/platform",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	driver.Print(&buf, diags, configs)
+
+	// The Pipe member only exists on windows, and the exhaustive switch on
+	// Memory alone is reported by both configurations, once.
+	want := "backend.go:12:2: type switch on Backend has no default case for members unavailable under this file's build constraints: platform.*Pipe [windows/amd64]\n" +
+		"backend.go:20:2: missing cases in type switch on Backend: platform.*Memory\n"
+	if got := stripDir(buf.String()); got != want {
+		t.Errorf("got diagnostics:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// stripDir removes the directory part of the file names in driver output.
+func stripDir(s string) string {
+	var out []byte
+	for _, line := range bytes.SplitAfter([]byte(s), []byte("\n")) {
+		if i := bytes.Index(line, []byte("backend.go")); i >= 0 {
+			line = line[i:]
+		}
+		out = append(out, line...)
+	}
+	return string(out)
+}
+
+func TestRunOnPackage(t *testing.T) {
+	var streamed []driver.Diagnostic
+	diags, err := driver.Run(gounion.Analyzer, []string{"."}, driver.Options{
+		Dir:   "testdata/platform",
+		Tests: true,
+		OnPackage: func(diags []driver.Diagnostic) {
+			streamed = append(streamed, diags...)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Diagnostics are streamed once, even though the package is analyzed
+	// both with and without its tests.
+	if len(streamed) != len(diags) || len(diags) == 0 {
+		t.Fatalf("streamed %d diagnostics, want %d", len(streamed), len(diags))
+	}
+	for i := range diags {
+		if streamed[i].Posn != diags[i].Posn || streamed[i].Message != diags[i].Message {
+			t.Errorf("streamed[%d] = %v, want %v", i, streamed[i], diags[i])
+		}
+	}
+}
+
+func TestRunNoFacts(t *testing.T) {
+	if err := gounion.Analyzer.Flags.Set("lazy-facts", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("lazy-facts", "false")
+
+	diags, err := driver.Run(gounion.Analyzer, []string{"."}, driver.Options{
+		Dir:     "testdata/platform",
+		NoFacts: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	driver.Print(&buf, diags, nil)
+
+	want := "backend.go:20:2: missing cases in type switch on Backend: platform.*Memory\n"
+	if got := stripDir(buf.String()); got != want {
+		t.Errorf("got diagnostics:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestParseShard(t *testing.T) {
+	got, err := driver.ParseShard("1/4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (driver.Shard{Index: 1, Count: 4}); got != want {
+		t.Errorf("ParseShard = %v, want %v", got, want)
+	}
+
+	for _, s := range []string{"4/4", "-1/2", "1", "a/b", "0/0"} {
+		if _, err := driver.ParseShard(s); err == nil {
+			t.Errorf("ParseShard(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestRunShards(t *testing.T) {
+	run := func(shard driver.Shard) []driver.Diagnostic {
+		diags, err := driver.Run(gounion.Analyzer, []string{"./..."}, driver.Options{
+			Dir:   "testdata/platform",
+			Shard: shard,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return diags
+	}
+
+	all := run(driver.Shard{})
+	var sharded []driver.Diagnostic
+	for i := range 3 {
+		sharded = append(sharded, run(driver.Shard{Index: i, Count: 3})...)
+	}
+
+	// Every package is analyzed by exactly one shard.
+	if len(sharded) != len(all) {
+		t.Fatalf("shards reported %d diagnostics, want %d", len(sharded), len(all))
+	}
+	seen := make(map[string]bool)
+	for _, d := range sharded {
+		seen[d.Posn.String()+d.Message] = true
+	}
+	for _, d := range all {
+		if !seen[d.Posn.String()+d.Message] {
+			t.Errorf("no shard reported %s: %s", d.Posn, d.Message)
+		}
+	}
+}
+
+func TestRunFactsDir(t *testing.T) {
+	dir := writeModule(t, "facts", map[string]string{
+		"shape/shape.go": `package shape
+
+type Shape interface{ isShape() }
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+`,
+		"app/app.go": `package app
+
+import "example.com/facts/shape"
+
+func Name(s shape.Shape) string {
+	switch s.(type) {
+	case *shape.Circle:
+		return "circle"
+	}
+	return ""
+}
+`,
+	})
+	factsDir := t.TempDir()
+	run := func(patterns []string, shard driver.Shard) []driver.Diagnostic {
+		t.Helper()
+		diags, err := driver.Run(gounion.Analyzer, patterns, driver.Options{Dir: dir, Shard: shard, FactsDir: factsDir})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return diags
+	}
+	print := func(diags []driver.Diagnostic) string {
+		var buf strings.Builder
+		for _, d := range diags {
+			fmt.Fprintf(&buf, "%s:%d: %s\n", filepath.Base(d.Posn.Filename), d.Posn.Line, d.Message)
+		}
+		return buf.String()
+	}
+
+	const want = "app.go:6: missing cases in type switch on Shape: shape.*Square\n"
+	var sharded []driver.Diagnostic
+	for i := range 2 {
+		sharded = append(sharded, run([]string{"./..."}, driver.Shard{Index: i, Count: 2})...)
+	}
+	if got := print(sharded); got != want {
+		t.Errorf("shards reported:\n%s\nwant:\n%s", got, want)
+	}
+
+	artifacts, err := filepath.Glob(filepath.Join(factsDir, "*.facts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(artifacts) != 2 {
+		t.Fatalf("wrote %d artifacts, want one per package", len(artifacts))
+	}
+	old := time.Now().Add(-time.Hour)
+	for _, name := range artifacts {
+		if err := os.Chtimes(name, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The facts of shape are read from its artifact, which is left alone,
+	// rather than derived again; only app, analyzed, writes its own.
+	if got := print(run([]string{"./app"}, driver.Shard{})); got != want {
+		t.Errorf("got diagnostics:\n%s\nwant:\n%s", got, want)
+	}
+	rewritten := 0
+	for _, name := range artifacts {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.ModTime().After(old) {
+			rewritten++
+		}
+	}
+	if rewritten != 1 {
+		t.Errorf("rewrote %d artifacts, want 1", rewritten)
+	}
+}
+
+func TestPrintGitHub(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs := []driver.Config{{GOOS: "linux", GOARCH: "amd64"}, {GOOS: "windows", GOARCH: "amd64"}}
+	diags := []driver.Diagnostic{{
+		Posn:    token.Position{Filename: filepath.Join(wd, "testdata", "a,b.go"), Line: 3, Column: 2},
+		Message: "missing cases: 100%\nsee docs",
+		Configs: configs[1:],
+	}}
+
+	var buf bytes.Buffer
+	if err := driver.PrintGitHub(&buf, diags, configs); err != nil {
+		t.Fatal(err)
+	}
+	want := "::error file=testdata/a%2Cb.go,line=3,col=2,title=gounion::missing cases: 100%25%0Asee docs [windows/amd64]\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	if err := gounion.Analyzer.Flags.Set("per-member", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("per-member", "false")
+
+	diags, err := driver.Run(gounion.Analyzer, []string{"."}, driver.Options{
+		Dir:   "testdata/platform",
+		Tests: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixed, err := driver.ApplyFixes(diags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixed.Unfixed) != 0 || len(fixed.Files) != 1 {
+		t.Fatalf("fixed %d files with %d unfixed diagnostics, want 1 and 0", len(fixed.Files), len(fixed.Unfixed))
+	}
+
+	for name, src := range fixed.Files {
+		old, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "--- a/backend.go\n+++ b/backend.go\n" +
+			"@@ -18,6 +18,7 @@\n" +
+			" \n" +
+			" func describeNone(b Backend) string {\n" +
+			" \tswitch b.(type) {\n" +
+			"+\tcase *Memory:\n" +
+			" \t}\n" +
+			" \treturn \"\"\n" +
+			" }\n"
+		if got := driver.UnifiedDiff("a/backend.go", "b/backend.go", old, src); got != want {
+			t.Errorf("got diff:\n%s\nwant:\n%s", got, want)
+		}
+	}
+}
+
+func TestApplyFixesCategories(t *testing.T) {
+	if err := gounion.Analyzer.Flags.Set("per-member", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer gounion.Analyzer.Flags.Set("per-member", "false")
+
+	diags, err := driver.Run(gounion.Analyzer, []string{"."}, driver.Options{
+		Dir:   "testdata/platform",
+		Tests: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range diags {
+		if d.Category != "switch" {
+			t.Errorf("%s: category %q, want switch", d.Message, d.Category)
+		}
+	}
+
+	fixed, err := driver.ApplyFixes(diags, "enum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixed.Files) != 0 || len(fixed.Unfixed) != len(diags) {
+		t.Errorf("fixed %d files with %d unfixed diagnostics, want 0 and %d", len(fixed.Files), len(fixed.Unfixed), len(diags))
+	}
+
+	fixed, err = driver.ApplyFixes(diags, "enum", "switch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixed.Files) != 1 || len(fixed.Unfixed) != 0 {
+		t.Errorf("fixed %d files with %d unfixed diagnostics, want 1 and 0", len(fixed.Files), len(fixed.Unfixed))
+	}
+}
+
+func TestPrintJUnit(t *testing.T) {
+	report, err := driver.Check(gounion.Analyzer, []string{"."}, driver.Options{
+		Dir:   "testdata/platform",
+		Tests: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := driver.PrintJUnit(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each switch is one test case, even though the package is analyzed
+	// both with and without its tests.
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="gounion" tests="2" failures="1">
+	<testsuite name="example.com/platform" tests="2" failures="1">
+		<testcase classname="example.com/platform" name="type switch on Backend at backend.go:12" file="testdata/platform/backend.go" line="12"></testcase>
+		<testcase classname="example.com/platform" name="type switch on Backend at backend.go:20" file="testdata/platform/backend.go" line="20">
+			<failure message="missing cases in type switch on Backend: platform.*Memory" type="gounion">missing cases in type switch on Backend: platform.*Memory</failure>
+		</testcase>
+	</testsuite>
+</testsuites>
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrintList(t *testing.T) {
+	report, err := driver.Check(gounion.Analyzer, []string{"./..."}, driver.Options{
+		Dir:   "testdata/platform",
+		Tests: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	switches := append(report.Switches, driver.Switch{
+		Posn:    token.Position{Filename: filepath.Join(wd, "testdata", "default.go"), Line: 7, Column: 2},
+		Union:   "Backend",
+		Default: true,
+	})
+
+	var buf bytes.Buffer
+	if err := driver.PrintList(&buf, switches, "Backend"); err != nil {
+		t.Fatal(err)
+	}
+	want := "testdata/platform/backend.go:12:2: Backend: handled platform.*Memory\n" +
+		"testdata/platform/backend.go:20:2: Backend: handled none; missing platform.*Memory\n" +
+		"testdata/default.go:7:2: Backend: handled none; default\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCheckDefaultMissing(t *testing.T) {
+	dir := writeModule(t, "list", map[string]string{"shape.go": `package list
+
+type Shape interface{ isShape() }
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+func name(s Shape) string {
+	switch s.(type) {
+	case *Circle:
+		return "circle"
+	default:
+		return "other"
+	}
+}
+`})
+	report, err := driver.Check(gounion.Analyzer, []string{"."}, driver.Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Diagnostics) != 0 {
+		t.Errorf("got diagnostics %v, want none", report.Diagnostics)
+	}
+
+	// The members left to the default case are listed anyway.
+	var buf bytes.Buffer
+	if err := driver.PrintList(&buf, report.Switches, ""); err != nil {
+		t.Fatal(err)
+	}
+	want := "shape.go:12:2: Shape: handled list.*Circle; missing list.*Square; default\n"
+	if got := buf.String(); !strings.HasSuffix(got, want) {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if len(report.Switches) != 1 || !report.Switches[0].DefaultAccepted {
+		t.Errorf("got switches %+v, want one whose default case is accepted", report.Switches)
+	}
+}
+
+func TestPrintGraph(t *testing.T) {
+	report, err := driver.Check(gounion.Analyzer, []string{"."}, driver.Options{
+		Dir:   "testdata/platform",
+		Tests: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := driver.PrintGraph(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph gounion {
+	rankdir=LR;
+	"example.com/platform.Backend" [label="platform.Backend", shape=box];
+	"example.com/platform.*Memory" [label="platform.*Memory"];
+	"example.com/platform.*Memory" -> "example.com/platform.Backend";
+	"example.com/platform.describe" [label="platform.describe", shape=note];
+	"example.com/platform.describeNone" [label="platform.describeNone", shape=note];
+	"example.com/platform.describe" -> "example.com/platform.Backend" [style=dashed];
+	"example.com/platform.describeNone" -> "example.com/platform.Backend" [style=dashed, color=red];
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrintImpact(t *testing.T) {
+	report, err := driver.Check(gounion.Analyzer, []string{"."}, driver.Options{
+		Dir: "testdata/impact",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := driver.PrintImpact(&buf, report, "Shape", "*Triangle"); err != nil {
+		t.Fatal(err)
+	}
+	want := `testdata/impact/gounion_gen.go:3:1: generated by gounion-gen: regenerate
+testdata/impact/shape.go:17:2: type switch in area: add a case for *Triangle
+testdata/impact/shape.go:27:2: type switch in name: add a case for *Triangle, unless the default case handles it
+testdata/impact/shape.go:36:9: gounionrt.Match2 in perimeter: add a function for *Triangle
+testdata/impact/shape.go:43:15: all-members literal: add a *Triangle element
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := driver.PrintImpact(&buf, report, "Shape", "Square"); err == nil {
+		t.Error("PrintImpact with an existing member: got nil error")
+	}
+	if err := driver.PrintImpact(&buf, report, "Figure", "*Triangle"); err == nil {
+		t.Error("PrintImpact with an unknown union: got nil error")
+	}
+}
+
+func TestPrintImpactQualified(t *testing.T) {
+	shape := func(pkg string) string {
+		return "package " + pkg + `
+
+type Shape interface{ isShape() }
+
+type Circle struct{}
+
+func (*Circle) isShape() {}
+
+func name(s Shape) string {
+	switch s.(type) {
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
+`
+	}
+	dir := writeModule(t, "shapes", map[string]string{"a/a.go": shape("a"), "b/b.go": shape("b")})
+	report, err := driver.Check(gounion.Analyzer, []string{"./..."}, driver.Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both packages declare a Shape, so its name alone is ambiguous.
+	var buf bytes.Buffer
+	if err := driver.PrintImpact(&buf, report, "Shape", "*Square"); err == nil || !strings.Contains(err.Error(), "example.com/shapes/a.Shape or example.com/shapes/b.Shape") {
+		t.Errorf("PrintImpact with an ambiguous union: got error %v", err)
+	}
+	if err := driver.PrintImpact(&buf, report, "example.com/shapes/b.Shape", "*Square"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "b/b.go:10:2: type switch in name: add a case for *Square\n"; !strings.HasSuffix(got, want) || strings.Count(got, "\n") != 1 {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrintDoctor(t *testing.T) {
+	opts := driver.Options{Dir: "testdata/doctor"}
+	steps, err := driver.Doctor(gounion.Analyzer, "..Shape", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := driver.PrintDoctor(&buf, "..Shape", steps); err != nil {
+		t.Fatal(err)
+	}
+	want := `..Shape:
+	1. Shape is declared at shape.go:3:6
+	2. method Area is not a marker: it has parameters or results
+	3. method isShape is a marker: it is unexported and has no parameters or results
+	4. Circle is a member: it has isShape
+	5. Labeled is a member: it has isShape through an embedded field (see -exclude-embedded)
+	6. Solid is not a member: it is an interface
+	7. Square is a member as *Square: it has isShape on its pointer receiver only
+	8. Shape is a union of *Square, Circle, Labeled: type switches on it are checked
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := gounion.Analyzer.Flags.Lookup("explain").Value.String(); got != "" {
+		t.Errorf("-explain after Doctor: got %q, want it reset", got)
+	}
+	gounion.Analyzer.Flags.Set("exclude-embedded", "true")
+	defer gounion.Analyzer.Flags.Set("exclude-embedded", "false")
+	if steps, err := driver.Doctor(gounion.Analyzer, "..Shape", opts); err != nil {
+		t.Error(err)
+	} else if !slices.Contains(steps, "Labeled is not a member: it only has isShape through an embedded field (-exclude-embedded)") {
+		t.Errorf("Doctor(Shape) with -exclude-embedded: got %q", steps)
+	}
+	if steps, err := driver.Doctor(gounion.Analyzer, "..Token", opts); err != nil {
+		t.Error(err)
+	} else if last := steps[len(steps)-1]; last != "Token is not a union: marker method candidate IsToken is exported" {
+		t.Errorf("Doctor(Token): got last step %q", last)
+	}
+	if _, err := driver.Doctor(gounion.Analyzer, "..Triangle", opts); err == nil {
+		t.Error("Doctor with an undeclared type: got nil error")
+	}
+}
+
+func TestPrintCheckstyle(t *testing.T) {
+	diags := []driver.Diagnostic{
+		{Posn: token.Position{Filename: "a.go", Line: 3, Column: 2}, Message: "missing cases in type switch on Shape: shape.*Square"},
+		{Posn: token.Position{Filename: "a.go", Line: 9, Column: 2}, Message: `x < "y"`},
+		{Posn: token.Position{Filename: "b.go", Line: 1, Column: 1}, Message: "z"},
+	}
+
+	var buf bytes.Buffer
+	if err := driver.PrintCheckstyle(&buf, diags, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="5.0">
+	<file name="a.go">
+		<error line="3" column="2" severity="error" message="missing cases in type switch on Shape: shape.*Square" source="gounion"></error>
+		<error line="9" column="2" severity="error" message="x &lt; &#34;y&#34;" source="gounion"></error>
+	</file>
+	<file name="b.go">
+		<error line="1" column="1" severity="error" message="z" source="gounion"></error>
+	</file>
+</checkstyle>
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRunMemberSites(t *testing.T) {
+	diags, err := driver.Run(gounion.Analyzer, []string{"."}, driver.Options{
+		Dir:         "testdata/platform",
+		Tests:       true,
+		MemberSites: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	driver.Print(&buf, diags, nil)
+
+	want := "backend.go:7:6: platform.*Memory is missing from 1 type switch on Backend: testdata/platform/backend.go:20:2\n" +
+		"backend.go:20:2: missing cases in type switch on Backend: platform.*Memory\n"
+	if got := stripDir(buf.String()); got != want {
+		t.Errorf("got diagnostics:\n%s\nwant:\n%s", got, want)
+	}
+	if !diags[0].Info || diags[1].Info {
+		t.Errorf("Info = %v, %v; want true, false", diags[0].Info, diags[1].Info)
+	}
+}
+
+func TestMainMemberSitesExitStatus(t *testing.T) {
+	dir := writeModule(t, "sites", map[string]string{"shape.go": `package sites
+
+type Shape interface{ isShape() }
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+func name(s Shape) string {
+	switch s.(type) {
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
+`})
+
+	if _, code := command(t, dir, "-member-sites", "."); code != 3 {
+		t.Errorf("gounion -member-sites exited with status %d, want 3", code)
+	}
+	// The informational diagnostic at Square has no fix, so it is left
+	// unfixed, which must not fail the run.
+	out, code := command(t, dir, "fix", "-member-sites", ".")
+	if code != 0 {
+		t.Errorf("gounion fix -member-sites exited with status %d, want 0; output:\n%s", code, out)
+	}
+}
+
+func TestMainProfiles(t *testing.T) {
+	dir := writeModule(t, "profiles", map[string]string{"shape.go": `package profiles
+
+type Shape interface{ isShape() }
+
+type Circle struct{}
+
+func (*Circle) isShape() {}
+`})
+
+	// The subcommands write the profiles and trace too.
+	for _, args := range [][]string{{"."}, {"list", "."}, {"graph", "."}, {"doctor", "./.Shape"}} {
+		out := t.TempDir()
+		files := []string{filepath.Join(out, "cpu"), filepath.Join(out, "mem"), filepath.Join(out, "trace")}
+		flags := []string{"-cpuprofile=" + files[0], "-memprofile=" + files[1], "-trace=" + files[2]}
+		// Flags follow the subcommand, if any.
+		i := len(args) - 1
+		command(t, dir, slices.Concat(args[:i], flags, args[i:])...)
+		for _, name := range files {
+			if info, err := os.Stat(name); err != nil || info.Size() == 0 {
+				t.Errorf("gounion %s: %s not written (%v)", strings.Join(args, " "), filepath.Base(name), err)
+			}
+		}
+	}
+}
+
+func TestMainFix(t *testing.T) {
+	const src = `package fix
+
+type Shape interface{ isShape() }
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+func name(s Shape) string {
+	switch s.(type) {
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
+`
+
+	// Without -per-member, the diagnostic of a switch with cases has no
+	// fix, so it is left unfixed.
+	dir := writeModule(t, "fix", map[string]string{"shape.go": src})
+	if _, code := command(t, dir, "fix", "-per-member=false", "."); code != 3 {
+		t.Errorf("gounion fix -per-member=false exited with status %d, want 3", code)
+	}
+	if got, err := os.ReadFile(filepath.Join(dir, "shape.go")); err != nil || string(got) != src {
+		t.Errorf("gounion fix -per-member=false changed shape.go (%v):\n%s", err, got)
+	}
+
+	// fix reports one diagnostic per missing member by default, whose fix
+	// adds the missing case.
+	out, code := command(t, dir, "fix", ".")
+	if code != 0 {
+		t.Errorf("gounion fix exited with status %d, want 0; output:\n%s", code, out)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "shape.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(got, []byte("\tcase *Square:\n")) {
+		t.Errorf("gounion fix did not add the case of Square:\n%s", got)
+	}
+	if _, code := command(t, dir, "."); code != 0 {
+		t.Errorf("gounion exited with status %d after fixing, want 0", code)
+	}
+}
+
+func TestPrintJSONRelated(t *testing.T) {
+	diags := []driver.Diagnostic{{
+		Posn:    token.Position{Filename: "a.go", Line: 3, Column: 2},
+		Message: "missing cases in type switch on Op: op.Div, +1 more",
+		Related: []driver.Related{
+			{Posn: token.Position{Filename: "op.go", Line: 9, Column: 6}, Message: "op.Div"},
+			{Posn: token.Position{Filename: "op.go", Line: 10, Column: 6}, Message: "op.Mod"},
+		},
+	}}
+
+	var buf bytes.Buffer
+	if err := driver.PrintJSON(&buf, diags, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `[
+	{
+		"posn": "a.go:3:2",
+		"message": "missing cases in type switch on Op: op.Div, +1 more",
+		"related": [
+			{
+				"posn": "op.go:9:6",
+				"message": "op.Div"
+			},
+			{
+				"posn": "op.go:10:6",
+				"message": "op.Mod"
+			}
+		]
+	}
+]
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExpandPatterns(t *testing.T) {
+	list := filepath.Join(t.TempDir(), "list.txt")
+	if err := os.WriteFile(list, []byte("# changed files\n./extra\n\nbackend.go\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := driver.ExpandPatterns([]string{"-", "@" + list, "./..."}, strings.NewReader("a/pipe_windows.go\n  ./cmd  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"file=a/pipe_windows.go", "./cmd", "./extra", "file=backend.go", "./..."}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandPatterns = %q, want %q", got, want)
+	}
+
+	if _, err := driver.ExpandPatterns([]string{"@" + list + ".missing"}, nil); err == nil {
+		t.Error("ExpandPatterns of a missing list succeeded, want error")
+	}
+}
+
+func TestRunOnFiles(t *testing.T) {
+	backend, err := filepath.Abs("testdata/platform/backend.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	patterns, err := driver.ExpandPatterns([]string{backend}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diags, err := driver.Run(gounion.Analyzer, patterns, driver.Options{Dir: "testdata/platform"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	driver.Print(&buf, diags, nil)
+	want := "backend.go:20:2: missing cases in type switch on Backend: platform.*Memory\n"
+	if got := stripDir(buf.String()); got != want {
+		t.Errorf("got diagnostics:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRunPackagesDriver(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "driver.log")
+	t.Setenv(driverLog, log)
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags, err := driver.Run(gounion.Analyzer, []string{"."}, driver.Options{
+		Dir:            "testdata/platform",
+		PackagesDriver: exe,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("the driver was not run: %v", err)
+	}
+	if got := string(requests); got != ".\n" {
+		t.Errorf("driver requests = %q, want %q", got, ".\n")
+	}
+
+	var buf bytes.Buffer
+	driver.Print(&buf, diags, nil)
+	want := "backend.go:20:2: missing cases in type switch on Backend: platform.*Memory\n"
+	if got := stripDir(buf.String()); got != want {
+		t.Errorf("got diagnostics:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestApplyFixesImports(t *testing.T) {
+	for name, value := range map[string]string{
+		"require-default": "true",
+		"default-body":    `return "", fmt.Errorf("unhandled %T", {{.Var}})`,
+	} {
+		if err := gounion.Analyzer.Flags.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func() {
+		gounion.Analyzer.Flags.Set("require-default", "false")
+		gounion.Analyzer.Flags.Set("default-body", "")
+	}()
+
+	diags, err := driver.Run(gounion.Analyzer, []string{"."}, driver.Options{Dir: "testdata/fiximports"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed, err := driver.ApplyFixes(diags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixed.Unfixed) != 0 || len(fixed.Files) != 1 {
+		t.Fatalf("fixed %d files with %d unfixed diagnostics, want 1 and 0", len(fixed.Files), len(fixed.Unfixed))
+	}
+
+	for _, src := range fixed.Files {
+		// The default case refers to fmt, which the file did not import.
+		if !bytes.Contains(src, []byte("import \"fmt\"\n")) {
+			t.Errorf("fixed file does not import fmt:\n%s", src)
+		}
+		if formatted, err := format.Source(src); err != nil || !bytes.Equal(formatted, src) {
+			t.Errorf("fixed file is not gofmt-clean (%v):\n%s", err, src)
+		}
+	}
+}
+
+func TestApplyFixesLineDirective(t *testing.T) {
+	diags, err := driver.Run(gounion.Analyzer, []string{"."}, driver.Options{Dir: "testdata/linedirective"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 || filepath.Base(diags[0].Posn.Filename) != "shapes.templ" {
+		t.Fatalf("got diagnostics %v, want one in shapes.templ", diags)
+	}
+	fixed, err := driver.ApplyFixes(diags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixed.Unfixed) != 0 || len(fixed.Files) != 1 {
+		t.Fatalf("fixed %d files with %d unfixed diagnostics, want 1 and 0", len(fixed.Files), len(fixed.Unfixed))
+	}
+
+	// The edits apply to the generated Go file, not to the file named by
+	// its //line directive.
+	for name, src := range fixed.Files {
+		if filepath.Base(name) != "shape.go" {
+			t.Errorf("fixed %s, want shape.go", name)
+		}
+		if !bytes.Contains(src, []byte("case *Circle:")) || !bytes.Contains(src, []byte("case *Square:")) {
+			t.Errorf("fixed file lacks the cases of the members:\n%s", src)
+		}
+		if _, err := format.Source(src); err != nil {
+			t.Errorf("fixed file does not parse (%v):\n%s", err, src)
+		}
+	}
+}
+
+func TestDiffUnions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dir := t.TempDir()
+	write := func(name, src string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	write("go.mod", "module example.com/shape\n\ngo 1.24\n")
+	write("shape.go", `package shape
+
+type Shape interface{ isShape() }
+
+type Circle struct{}
+type Triangle struct{}
+
+func (*Circle) isShape()   {}
+func (*Triangle) isShape() {}
+
+type Color interface{ isColor() }
+
+type Red struct{}
+
+func (Red) isColor() {}
+`)
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "base")
+
+	write("shape.go", `package shape
+
+type Shape interface{ isShape() }
+
+type Circle struct{}
+type Pentagon struct{}
+
+func (*Circle) isShape()   {}
+func (*Pentagon) isShape() {}
+
+type Event interface{ isEvent() }
+
+type Click struct{}
+
+func (Click) isEvent() {}
+
+func area(s Shape) float64 {
+	switch s.(type) {
+	case *Circle:
+		return 1
+	}
+	return 0
+}
+
+func name(s Shape) string {
+	switch s.(type) {
+	case *Circle:
+		return "circle"
+	default:
+		return "other"
+	}
+}
+`)
+
+	t.Chdir(dir)
+	head, err := driver.Check(gounion.Analyzer, []string{"."}, driver.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, err := driver.CheckRevision(gounion.Analyzer, []string{"."}, driver.Options{}, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := driver.PrintUnionDiff(&buf, driver.DiffUnions(base, head)); err != nil {
+		t.Fatal(err)
+	}
+	want := `example.com/shape.Color: union removed
+example.com/shape.Event: new union of Click
+example.com/shape.Shape: added *Pentagon; removed *Triangle
+	shape.go:18:2: type switch in area: missing shape.*Pentagon
+	shape.go:26:2: type switch in name: missing shape.*Pentagon; default
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := driver.CheckRevision(gounion.Analyzer, []string{"."}, driver.Options{}, "no-such-revision"); err == nil {
+		t.Error("CheckRevision with an unknown revision: got nil error")
+	}
+}
+
+func TestRunDependents(t *testing.T) {
+	for name, value := range map[string]string{"per-member": "true", "errors-as": "true"} {
+		if err := gounion.Analyzer.Flags.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer gounion.Analyzer.Flags.Set("per-member", "false")
+	defer gounion.Analyzer.Flags.Set("errors-as", "false")
+
+	t.Setenv("GOFLAGS", "") // -mod=mod is rejected in workspace mode
+	report, err := driver.Check(gounion.Analyzer, []string{"./..."}, driver.Options{
+		Dir:        "testdata/workspace/shape",
+		Dependents: []string{"example.com/app/...", "example.com/ui/..."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, d := range report.Diagnostics {
+		got = append(got, fmt.Sprintf("%s:%d: %s", filepath.Base(d.Posn.Filename), d.Posn.Line, d.Message))
+	}
+	// The switch on the dependent's own union Event is not reported; ui
+	// reaches Shape only through the aliases of app.
+	want := []string{
+		"app.go:14: missing case in type switch on Shape: shape.*Square",
+		"status.go:19: missing errors.As branches on err for StoreError: shape.*TimeoutError",
+		"shape.go:12: missing case in type switch on Shape: shape.*Square",
+		"ui.go:6: missing case in type switch on Shape: shape.*Square",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for _, u := range report.Unions {
+		if u.Package != "example.com/shape" {
+			t.Errorf("union %s.%s of a dependent listed", u.Package, u.Name)
+		}
+	}
+}
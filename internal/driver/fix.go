@@ -0,0 +1,131 @@
+package driver
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/imports"
+)
+
+// Fixed is the result of applying suggested fixes.
+type Fixed struct {
+	Files   map[string][]byte // new content of each changed file
+	Unfixed []Diagnostic      // diagnostics without a fix, or whose fix conflicts with another
+}
+
+// ApplyFixes applies the suggested fixes of diags to the files they edit,
+// in diagnostic order, without writing them. A fix that overlaps an
+// already accepted fix is skipped, and its diagnostic is reported as
+// unfixed. Insertions at the same offset are applied in diagnostic order,
+// so that several fixes may add cases before the same closing brace.
+//
+// The fixed Go files are formatted as by goimports, adding the imports of
+// the packages the inserted code refers to, such as those of a
+// -default-body template, and removing imports left unused, so that the
+// fixed files compile and are gofmt-clean.
+//
+// If categories are given, only the fixes of diagnostics in one of them are
+// applied, and the other diagnostics are reported as unfixed.
+func ApplyFixes(diags []Diagnostic, categories ...string) (*Fixed, error) {
+	edits := make(map[string][]Edit)
+	fixed := &Fixed{Files: make(map[string][]byte)}
+
+	for _, d := range diags {
+		if len(d.Edits) == 0 || !inCategories(d, categories) || !compatible(edits, d.Edits) {
+			fixed.Unfixed = append(fixed.Unfixed, d)
+			continue
+		}
+		for _, e := range d.Edits {
+			if !contains(edits[e.Filename], e) {
+				edits[e.Filename] = append(edits[e.Filename], e)
+			}
+		}
+	}
+
+	for name, es := range edits {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		out, err := applyEdits(src, es)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		if strings.HasSuffix(name, ".go") {
+			if out, err = imports.Process(name, out, &imports.Options{Comments: true, TabIndent: true, TabWidth: 8}); err != nil {
+				return nil, fmt.Errorf("%s: fixed file does not parse: %v", name, err)
+			}
+		}
+		fixed.Files[name] = out
+	}
+	return fixed, nil
+}
+
+// inCategories reports whether d is in one of categories, or categories
+// is empty.
+func inCategories(d Diagnostic, categories []string) bool {
+	if len(categories) == 0 {
+		return true
+	}
+	for _, c := range categories {
+		if d.Category == c {
+			return true
+		}
+	}
+	return false
+}
+
+// compatible reports whether the edits of one fix can be applied together
+// with the accepted edits.
+func compatible(accepted map[string][]Edit, fix []Edit) bool {
+	for _, e := range fix {
+		for _, a := range accepted[e.Filename] {
+			if a == e {
+				continue
+			}
+			if a.Start < e.End && e.Start < a.End {
+				return false // overlapping replacements
+			}
+			if (e.Start == e.End && a.Start < e.Start && e.Start < a.End) ||
+				(a.Start == a.End && e.Start < a.Start && a.Start < e.End) {
+				return false // insertion inside a replacement
+			}
+		}
+	}
+	return true
+}
+
+// contains reports whether edits contains e.
+func contains(edits []Edit, e Edit) bool {
+	for _, x := range edits {
+		if x == e {
+			return true
+		}
+	}
+	return false
+}
+
+// applyEdits applies non-overlapping edits to src. Edits at the same
+// offset are applied in their order in edits.
+func applyEdits(src []byte, edits []Edit) ([]byte, error) {
+	sorted := make([]Edit, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	var out []byte
+	last := 0
+	for _, e := range sorted {
+		if e.Start < last || e.End < e.Start || e.End > len(src) {
+			return nil, fmt.Errorf("invalid edit at offset %d", e.Start)
+		}
+		out = append(out, src[last:e.Start]...)
+		out = append(out, e.NewText...)
+		last = e.End
+	}
+	out = append(out, src[last:]...)
+	return out, nil
+}
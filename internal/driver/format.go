@@ -0,0 +1,159 @@
+package driver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// format is an output format of the standalone command.
+type format struct {
+	print    func(w io.Writer, r *Report) error
+	stdout   bool // print to stdout rather than stderr
+	stream   bool // diagnostics can be printed package by package
+	zeroExit bool // exit with status 0 even if there are diagnostics
+}
+
+// formats are the output formats selectable with -format.
+var formats = map[string]format{
+	"text":       {print: diagnostics(Print), stream: true},
+	"json":       {print: diagnostics(PrintJSON), stdout: true, zeroExit: true},
+	"github":     {print: diagnostics(PrintGitHub), stdout: true, stream: true},
+	"junit":      {print: PrintJUnit, stdout: true},
+	"checkstyle": {print: diagnostics(PrintCheckstyle), stdout: true},
+}
+
+// diagnostics adapts a printer of diagnostics to a format printer.
+func diagnostics(print func(w io.Writer, diags []Diagnostic, configs []Config) error) func(io.Writer, *Report) error {
+	return func(w io.Writer, r *Report) error {
+		return print(w, r.Diagnostics, r.Configs)
+	}
+}
+
+// formatNames returns the names of the output formats, sorted.
+func formatNames() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writer returns the writer the format prints to.
+func (f format) writer() io.Writer {
+	if f.stdout {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+// Print writes diagnostics as "file:line:col: message" lines. When several
+// configurations were checked, diagnostics not reported by all of them are
+// annotated with the configurations that did report them.
+func Print(w io.Writer, diags []Diagnostic, configs []Config) error {
+	for _, d := range diags {
+		msg := d.Message + configSuffix(d, configs)
+		if _, err := fmt.Fprintf(w, "%s: %s\n", d.Posn, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configSuffix returns the suffix annotating a diagnostic with the
+// configurations reporting it, if only some of configs did.
+func configSuffix(d Diagnostic, configs []Config) string {
+	if len(configs) <= 1 || len(d.Configs) == len(configs) {
+		return ""
+	}
+	names := make([]string, len(d.Configs))
+	for i, c := range d.Configs {
+		names[i] = c.String()
+	}
+	return " [" + strings.Join(names, ", ") + "]"
+}
+
+// jsonDiagnostic is the JSON form of a Diagnostic.
+type jsonDiagnostic struct {
+	Posn    string        `json:"posn"`
+	Message string        `json:"message"`
+	Configs []string      `json:"configs,omitempty"`
+	Info    bool          `json:"info,omitempty"`
+	Related []jsonRelated `json:"related,omitempty"`
+}
+
+// jsonRelated is the JSON form of a Related.
+type jsonRelated struct {
+	Posn    string `json:"posn"`
+	Message string `json:"message"`
+}
+
+// PrintJSON writes diagnostics as a JSON array.
+func PrintJSON(w io.Writer, diags []Diagnostic, configs []Config) error {
+	out := make([]jsonDiagnostic, len(diags))
+	for i, d := range diags {
+		out[i] = jsonDiagnostic{Posn: d.Posn.String(), Message: d.Message, Info: d.Info}
+		for _, r := range d.Related {
+			out[i].Related = append(out[i].Related, jsonRelated{Posn: r.Posn.String(), Message: r.Message})
+		}
+		if len(configs) > 1 {
+			for _, c := range d.Configs {
+				out[i].Configs = append(out[i].Configs, c.String())
+			}
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(out)
+}
+
+// PrintGitHub writes diagnostics as GitHub Actions workflow commands, so
+// that they are shown as annotations on pull requests. File names are made
+// relative to the current directory, which is the repository root in
+// typical workflows.
+func PrintGitHub(w io.Writer, diags []Diagnostic, configs []Config) error {
+	for _, d := range diags {
+		file := relPath(d.Posn.Filename)
+
+		msg := d.Message + configSuffix(d, configs)
+		command := "error"
+		if d.Info {
+			command = "notice"
+		}
+		_, err := fmt.Fprintf(w, "::%s file=%s,line=%d,col=%d,title=gounion::%s\n",
+			command, escapeProperty(file), d.Posn.Line, d.Posn.Column, escapeData(msg))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relPath returns name relative to the current directory, with forward
+// slashes, if it is inside it.
+func relPath(name string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return name
+	}
+	if rel, err := filepath.Rel(wd, name); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return name
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
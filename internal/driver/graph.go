@@ -0,0 +1,81 @@
+package driver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+)
+
+// PrintGraph writes the unions of a report as a Graphviz DOT graph: each
+// union declared in the checked packages, as a box, with an edge from each
+// of its members, and an edge to the unions from each function (or package,
+// outside of functions) switching on them. Edges of functions with a
+// non-exhaustive switch are red.
+func PrintGraph(w io.Writer, r *Report) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph gounion {\n")
+	fmt.Fprintf(bw, "\trankdir=LR;\n")
+
+	nodes := make(map[string]bool)
+	node := func(id, label, attrs string) {
+		if nodes[id] {
+			return
+		}
+		nodes[id] = true
+		fmt.Fprintf(bw, "\t%s [label=%s%s];\n", strconv.Quote(id), strconv.Quote(label), attrs)
+	}
+
+	for _, u := range r.Unions {
+		union := u.Package + "." + u.Name
+		node(union, path.Base(u.Package)+"."+u.Name, ", shape=box")
+		for _, m := range u.Members {
+			member := u.Package + "." + m
+			node(member, path.Base(u.Package)+"."+m, "")
+			fmt.Fprintf(bw, "\t%s -> %s;\n", strconv.Quote(member), strconv.Quote(union))
+		}
+	}
+
+	// Switches of one function on one union share an edge.
+	type edge struct{ from, to string }
+	var edges []edge
+	count := make(map[edge]int)
+	missing := make(map[edge]bool)
+	for _, sw := range r.Switches {
+		union := sw.Union
+		if sw.UnionPkg != "" {
+			union = sw.UnionPkg + "." + sw.Union
+			node(union, path.Base(sw.UnionPkg)+"."+sw.Union, ", shape=box")
+		} else {
+			node(union, sw.Union, ", shape=box")
+		}
+		from, label := sw.Package, path.Base(sw.Package)
+		if sw.Func != "" {
+			from, label = sw.Package+"."+sw.Func, label+"."+sw.Func
+		}
+		node(from, label, ", shape=note")
+
+		e := edge{from, union}
+		if count[e] == 0 {
+			edges = append(edges, e)
+		}
+		count[e]++
+		if len(sw.Missing) > 0 && !sw.DefaultAccepted {
+			missing[e] = true
+		}
+	}
+	for _, e := range edges {
+		attrs := "style=dashed"
+		if count[e] > 1 {
+			attrs += fmt.Sprintf(", label=\"%d switches\"", count[e])
+		}
+		if missing[e] {
+			attrs += ", color=red"
+		}
+		fmt.Fprintf(bw, "\t%s -> %s [%s];\n", strconv.Quote(e.from), strconv.Quote(e.to), attrs)
+	}
+
+	fmt.Fprintf(bw, "}\n")
+	return bw.Flush()
+}
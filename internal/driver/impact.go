@@ -0,0 +1,113 @@
+package driver
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+)
+
+// PrintImpact writes the code that must be updated if member, e.g.
+// "*Pentagon", is added to the named union, one place per line in position
+// order, so that the change can be planned before it is written:
+//
+//	shape/area.go:12:2: type switch in area: add a case for *Pentagon
+//	shape/draw.go:20:9: gounionrt.Match2 in draw: add a function for *Pentagon
+//	shape/gounion_gen.go:3:1: generated by gounion-gen: regenerate
+//
+// The union is named by its name, e.g. "Shape", or, if unions of several
+// checked packages have that name, qualified with the path of its package,
+// e.g. "example.com/shape.Shape". Switches and calls in files generated by
+// gounion-gen are covered by the regeneration of the file. A switch with a
+// default case may not need a case, if its default case handles new
+// members. It returns an error if no checked package declares the union,
+// or if member is already a member of it.
+func PrintImpact(w io.Writer, r *Report, union, member string) error {
+	u, err := findUnion(r.Unions, union)
+	if err != nil {
+		return err
+	}
+	for _, m := range u.Members {
+		if m == member {
+			return fmt.Errorf("%s is already a member of %s", member, union)
+		}
+	}
+	of := func(pkg, name string) bool { return pkg == u.Package && name == u.Name }
+
+	type place struct {
+		posn token.Position
+		text string
+	}
+	var places []place
+	generated := make(map[string]bool) // files generated by gounion-gen
+	for _, u := range r.Uses {
+		if of(u.UnionPkg, u.Union) && u.Kind == "generated" {
+			generated[u.Posn.Filename] = true
+			places = append(places, place{u.Posn, "generated by gounion-gen: regenerate"})
+		}
+	}
+	where := func(kind, fn string) string {
+		if fn == "" {
+			return kind
+		}
+		return kind + " in " + fn
+	}
+
+	for _, sw := range r.Switches {
+		if !of(sw.UnionPkg, sw.Union) || generated[sw.Posn.Filename] {
+			continue
+		}
+		text := where("type switch", sw.Func) + ": add a case for " + member
+		if sw.Default {
+			text += ", unless the default case handles it"
+		}
+		places = append(places, place{sw.Posn, text})
+	}
+	for _, u := range r.Uses {
+		if !of(u.UnionPkg, u.Union) || u.Kind == "generated" || generated[u.Posn.Filename] {
+			continue
+		}
+		kind, action := u.Kind, "add a function for "+member // gounionrt.MatchN
+		switch u.Kind {
+		case "gounionrt.NewDispatcher":
+			action = "add a handler for " + member
+		case "literal":
+			kind, action = "all-members literal", "add a "+member+" element"
+		}
+		places = append(places, place{u.Posn, where(kind, u.Func) + ": " + action})
+	}
+
+	sort.SliceStable(places, func(i, j int) bool {
+		return positionLess(places[i].posn, places[j].posn)
+	})
+	for _, p := range places {
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s\n", relPath(p.posn.Filename), p.posn.Line, p.posn.Column, p.text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findUnion returns the union of unions named by name, either its name or
+// its package path and name joined by a dot. It returns an error if no
+// union or several unions have that name.
+func findUnion(unions []Union, name string) (Union, error) {
+	var found []Union
+	for _, u := range unions {
+		if name == u.Name || name == u.Package+"."+u.Name {
+			found = append(found, u)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Union{}, fmt.Errorf("no checked package declares a union %s", name)
+	case 1:
+		return found[0], nil
+	}
+	qualified := make([]string, len(found))
+	for i, u := range found {
+		qualified[i] = u.Package + "." + u.Name
+	}
+	return Union{}, fmt.Errorf("several unions are named %s: qualify it with the path of its package, as in %s", name, strings.Join(qualified, " or "))
+}
@@ -0,0 +1,139 @@
+package driver
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// junitSuites is the root element of a JUnit XML report.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+// junitSuite is a JUnit test suite, one per package.
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+// junitCase is a JUnit test case: a checked switch, or a diagnostic
+// reported elsewhere.
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure"`
+}
+
+// junitFailure is the failure of a test case.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitName names the report, the type of its failures and the suite of
+// diagnostics not reported on a switch.
+const junitName = "gounion"
+
+// PrintJUnit writes a report as JUnit XML, so that CI systems that only
+// understand test reports can show its results. Each checked switch is a
+// test case of the suite of its package, failing with the diagnostics
+// reported on it. Other diagnostics, e.g. on gounionrt calls, are failing
+// test cases of a separate suite.
+func PrintJUnit(w io.Writer, r *Report) error {
+	atSwitch := make(map[string][]Diagnostic) // position of a switch -> its diagnostics
+	for _, sw := range r.Switches {
+		atSwitch[sw.Posn.String()] = nil
+	}
+	var others []Diagnostic
+	for _, d := range r.Diagnostics {
+		if d.Info {
+			continue // not a failure
+		}
+		k := d.Posn.String()
+		if _, ok := atSwitch[k]; ok {
+			atSwitch[k] = append(atSwitch[k], d)
+		} else {
+			others = append(others, d)
+		}
+	}
+
+	root := junitSuites{Name: junitName}
+	suites := make(map[string]int) // package -> index in root.Suites
+	add := func(suite string, c junitCase) {
+		i, ok := suites[suite]
+		if !ok {
+			i = len(root.Suites)
+			suites[suite] = i
+			root.Suites = append(root.Suites, junitSuite{Name: suite})
+		}
+		s := &root.Suites[i]
+		s.Cases = append(s.Cases, c)
+		s.Tests++
+		root.Tests++
+		if c.Failure != nil {
+			s.Failures++
+			root.Failures++
+		}
+	}
+
+	for _, sw := range r.Switches {
+		file := relPath(sw.Posn.Filename)
+		add(sw.Package, junitCase{
+			ClassName: sw.Package,
+			Name:      fmt.Sprintf("type switch on %s at %s:%d", sw.Union, filepath.Base(file), sw.Posn.Line),
+			File:      file,
+			Line:      sw.Posn.Line,
+			Failure:   junitFailureOf(atSwitch[sw.Posn.String()], r.Configs),
+		})
+	}
+	for _, d := range others {
+		file := relPath(d.Posn.Filename)
+		add(junitName, junitCase{
+			ClassName: junitName,
+			Name:      fmt.Sprintf("%s:%d:%d", file, d.Posn.Line, d.Posn.Column),
+			File:      file,
+			Line:      d.Posn.Line,
+			Failure:   junitFailureOf([]Diagnostic{d}, r.Configs),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitFailureOf returns the failure reporting diags, or nil if there are
+// none.
+func junitFailureOf(diags []Diagnostic, configs []Config) *junitFailure {
+	if len(diags) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(diags))
+	for i, d := range diags {
+		msgs[i] = d.Message + configSuffix(d, configs)
+	}
+	return &junitFailure{
+		Message: msgs[0],
+		Type:    junitName,
+		Text:    strings.Join(msgs, "\n"),
+	}
+}
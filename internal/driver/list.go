@@ -0,0 +1,38 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PrintList writes the checked switches on the named union, or on all
+// unions if union is empty, one per line with its handled and missing
+// members, so that dispatch sites can be audited, e.g.
+//
+//	shape/area.go:12:2: Shape: handled shape.*Circle, shape.Point; missing shape.*Rectangle
+//
+// A switch with a default case ends with "; default", whether or not the
+// default case handles its missing members.
+func PrintList(w io.Writer, switches []Switch, union string) error {
+	for _, sw := range switches {
+		if union != "" && sw.Union != union {
+			continue
+		}
+		handled := "none"
+		if len(sw.Handled) > 0 {
+			handled = strings.Join(sw.Handled, ", ")
+		}
+		line := fmt.Sprintf("%s:%d:%d: %s: handled %s", relPath(sw.Posn.Filename), sw.Posn.Line, sw.Posn.Column, sw.Union, handled)
+		if len(sw.Missing) > 0 {
+			line += "; missing " + strings.Join(sw.Missing, ", ")
+		}
+		if sw.Default {
+			line += "; default"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
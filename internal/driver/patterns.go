@@ -0,0 +1,78 @@
+package driver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ExpandPatterns returns the package patterns of the command-line
+// arguments args. An argument "-" is replaced by the entries listed on
+// stdin, and an argument "@file" by the entries listed in file, so that
+// build systems can pass long lists without constructing pattern
+// arguments. Lists hold one entry per line; blank lines and lines starting
+// with # are skipped.
+//
+// Entries naming Go files, listed or not, are replaced by the query for
+// the package containing them, "file=name", rather than being checked as a
+// package of their own.
+func ExpandPatterns(args []string, stdin io.Reader) ([]string, error) {
+	var patterns []string
+	for _, arg := range args {
+		var (
+			entries []string
+			err     error
+		)
+		switch {
+		case arg == "-":
+			entries, err = readList(stdin)
+			if err != nil {
+				err = fmt.Errorf("reading patterns from stdin: %v", err)
+			}
+		case strings.HasPrefix(arg, "@"):
+			entries, err = readListFile(arg[1:])
+		default:
+			entries = []string{arg}
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if strings.HasSuffix(entry, ".go") && !strings.Contains(entry, "=") {
+				entry = "file=" + entry
+			}
+			patterns = append(patterns, entry)
+		}
+	}
+	return patterns, nil
+}
+
+// readListFile returns the entries listed in the named file.
+func readListFile(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := readList(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %v", name, err)
+	}
+	return entries, nil
+}
+
+// readList returns the entries listed by r, one per line.
+func readList(r io.Reader) ([]string, error) {
+	var entries []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries, sc.Err()
+}
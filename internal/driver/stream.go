@@ -0,0 +1,89 @@
+package driver
+
+import (
+	"go/token"
+	"sort"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// streamer passes the diagnostics of each root package to a callback as soon
+// as the package's analysis finishes, instead of after the whole run.
+type streamer struct {
+	roots     map[string]bool // paths of the root packages
+	onPackage func([]Diagnostic)
+
+	mu   sync.Mutex
+	seen map[diagKey]bool // diagnostics already passed, across package variants
+}
+
+// diagKey identifies a diagnostic for de-duplication.
+type diagKey struct {
+	posn    token.Position
+	message string
+}
+
+// wrap returns a copy of a whose Run additionally streams the diagnostics
+// reported on root packages. Facts and results are unaffected.
+func (s *streamer) wrap(a *analysis.Analyzer) *analysis.Analyzer {
+	wrapped := *a
+	wrapped.Run = func(pass *analysis.Pass) (any, error) {
+		if !s.roots[pass.Pkg.Path()] {
+			return a.Run(pass)
+		}
+
+		var diags []Diagnostic
+		report := pass.Report
+		pass.Report = func(d analysis.Diagnostic) {
+			report(d)
+			diags = append(diags, Diagnostic{Posn: pass.Fset.Position(d.Pos), Message: d.Message, Category: d.Category})
+		}
+
+		result, err := a.Run(pass)
+		if err == nil {
+			s.emit(diags)
+		}
+		return result, err
+	}
+	return &wrapped
+}
+
+// emit passes the diagnostics not seen before, sorted by position.
+func (s *streamer) emit(diags []Diagnostic) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var fresh []Diagnostic
+	for _, d := range diags {
+		k := diagKey{d.Posn, d.Message}
+		if !s.seen[k] {
+			s.seen[k] = true
+			fresh = append(fresh, d)
+		}
+	}
+	if len(fresh) == 0 {
+		return
+	}
+
+	sortDiagnostics(fresh)
+	s.onPackage(fresh)
+}
+
+// sortDiagnostics sorts diagnostics by position.
+func sortDiagnostics(diags []Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		return positionLess(diags[i].Posn, diags[j].Posn)
+	})
+}
+
+// positionLess reports whether position x is before y.
+func positionLess(x, y token.Position) bool {
+	if x.Filename != y.Filename {
+		return x.Filename < y.Filename
+	}
+	if x.Line != y.Line {
+		return x.Line < y.Line
+	}
+	return x.Column < y.Column
+}
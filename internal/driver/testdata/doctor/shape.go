@@ -0,0 +1,33 @@
+package doctor
+
+type Shape interface {
+	isShape()
+	Area() float64
+}
+
+type Circle struct{ R float64 }
+
+type Square struct{ S float64 }
+
+// Labeled is a member through its embedded Circle.
+type Labeled struct {
+	Circle
+	Label string
+}
+
+// Solid also has the marker, but is an interface.
+type Solid interface {
+	isShape()
+	Volume() float64
+}
+
+func (Circle) isShape()        {}
+func (c Circle) Area() float64 { return 3 * c.R * c.R }
+
+func (*Square) isShape()        {}
+func (s *Square) Area() float64 { return s.S * s.S }
+
+// Token is not a union: its marker is exported.
+type Token interface {
+	IsToken()
+}
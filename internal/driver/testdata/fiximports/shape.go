@@ -0,0 +1,19 @@
+package fiximports
+
+type Shape interface{ isShape() }
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+func name(s Shape) (string, error) {
+	switch s.(type) {
+	case *Circle:
+		return "circle", nil
+	case *Square:
+		return "square", nil
+	}
+	return "", nil
+}
@@ -0,0 +1,7 @@
+module example.com/impact
+
+go 1.24.0
+
+require github.com/YuitoSato/gounion v0.0.0
+
+replace github.com/YuitoSato/gounion => ../../../..
@@ -0,0 +1,43 @@
+package impact
+
+import "github.com/YuitoSato/gounion/gounionrt"
+
+type Shape interface {
+	isShape()
+}
+
+type Circle struct{ R float64 }
+
+type Square struct{ S float64 }
+
+func (Circle) isShape() {}
+func (Square) isShape() {}
+
+func area(s Shape) float64 {
+	switch s := s.(type) {
+	case Circle:
+		return 3 * s.R * s.R
+	case Square:
+		return s.S * s.S
+	}
+	return 0
+}
+
+func name(s Shape) string {
+	switch s.(type) {
+	case Circle:
+		return "circle"
+	default:
+		return "shape"
+	}
+}
+
+func perimeter(s Shape) float64 {
+	return gounionrt.Match2(s,
+		func(c Circle) float64 { return 6 * c.R },
+		func(q Square) float64 { return 4 * q.S },
+	)
+}
+
+//gounion:all-members
+var samples = []Shape{Circle{1}, Square{1}}
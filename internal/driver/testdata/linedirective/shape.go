@@ -0,0 +1,17 @@
+package linedirective
+
+type Shape interface{ isShape() }
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+// name is generated from shapes.templ.
+func name(s Shape) string {
+//line shapes.templ:11
+	switch s.(type) {
+	}
+	return ""
+}
@@ -0,0 +1,23 @@
+package platform
+
+type Backend interface {
+	isBackend()
+}
+
+type Memory struct{}
+
+func (*Memory) isBackend() {}
+
+func describe(b Backend) string {
+	switch b.(type) {
+	case *Memory:
+		return "memory"
+	}
+	return ""
+}
+
+func describeNone(b Backend) string {
+	switch b.(type) {
+	}
+	return ""
+}
@@ -0,0 +1,9 @@
+package platform
+
+import "testing"
+
+func TestDescribe(t *testing.T) {
+	if describe(&Memory{}) != "memory" {
+		t.Fail()
+	}
+}
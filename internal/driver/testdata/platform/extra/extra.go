@@ -0,0 +1,21 @@
+package extra
+
+type Mode interface {
+	isMode()
+}
+
+type Read struct{}
+
+func (Read) isMode() {}
+
+type Write struct{}
+
+func (Write) isMode() {}
+
+func name(m Mode) string {
+	switch m.(type) {
+	case Read:
+		return "read"
+	}
+	return ""
+}
@@ -0,0 +1,5 @@
+package platform
+
+type Pipe struct{}
+
+func (*Pipe) isBackend() {}
@@ -0,0 +1,25 @@
+package app
+
+import "example.com/shape"
+
+type Event interface{ isEvent() }
+
+type Click struct{}
+type Key struct{}
+
+func (Click) isEvent() {}
+func (Key) isEvent()   {}
+
+func area(s shape.Shape) float64 {
+	switch s.(type) {
+	case *shape.Circle:
+		return 1
+	}
+	return 0
+}
+
+func handle(e Event) {
+	switch e.(type) {
+	case Click:
+	}
+}
@@ -0,0 +1,25 @@
+package app
+
+import (
+	"errors"
+
+	"example.com/shape"
+)
+
+// Shape and Circle let dependents of app switch on shapes without
+// importing shape.
+type (
+	Shape  = shape.Shape
+	Circle = shape.Circle
+)
+
+func status(err error) int {
+	var notFound *shape.NotFoundError
+	var conflict *shape.ConflictError
+	if errors.As(err, &notFound) {
+		return 404
+	} else if errors.As(err, &conflict) {
+		return 409
+	}
+	return 500
+}
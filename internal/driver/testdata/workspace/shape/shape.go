@@ -0,0 +1,35 @@
+package shape
+
+type Shape interface{ isShape() }
+
+type Circle struct{}
+type Square struct{}
+
+func (*Circle) isShape() {}
+func (*Square) isShape() {}
+
+func name(s Shape) string {
+	switch s.(type) {
+	case *Circle:
+		return "circle"
+	}
+	return ""
+}
+
+// StoreError is an error union.
+type StoreError interface {
+	error
+	isStoreError()
+}
+
+type NotFoundError struct{}
+type ConflictError struct{}
+type TimeoutError struct{}
+
+func (*NotFoundError) Error() string { return "not found" }
+func (*ConflictError) Error() string { return "conflict" }
+func (*TimeoutError) Error() string  { return "timeout" }
+
+func (*NotFoundError) isStoreError() {}
+func (*ConflictError) isStoreError() {}
+func (*TimeoutError) isStoreError()  {}
@@ -0,0 +1,11 @@
+package ui
+
+import "example.com/app"
+
+func icon(s app.Shape) string {
+	switch s.(type) {
+	case *app.Circle:
+		return "○"
+	}
+	return ""
+}
@@ -0,0 +1,254 @@
+package driver
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// UnionChange is a change of the members of a union between two
+// revisions, with the switches on the union in the newer one.
+type UnionChange struct {
+	Package  string // package path
+	Name     string
+	Added    []string // members added, unqualified, e.g. "*Pentagon"
+	Removed  []string // members removed
+	New      bool     // whether the union is declared in the newer revision only
+	Deleted  bool     // whether the union is declared in the older revision only
+	Switches []Switch // switches on the union in the newer revision
+}
+
+// DiffUnions returns the changes of the unions declared in the packages of
+// old and new, ordered by package path and name. Unions whose members are
+// unchanged are omitted.
+func DiffUnions(old, new *Report) []UnionChange {
+	type key struct{ pkg, name string }
+	members := func(r *Report) map[key][]string {
+		m := make(map[key][]string)
+		for _, u := range r.Unions {
+			m[key{u.Package, u.Name}] = u.Members
+		}
+		return m
+	}
+	before, after := members(old), members(new)
+
+	var changes []UnionChange
+	for k, members := range after {
+		prev, ok := before[k]
+		c := UnionChange{Package: k.pkg, Name: k.name, New: !ok}
+		c.Added, c.Removed = memberDelta(prev, members)
+		if c.New || len(c.Added) > 0 || len(c.Removed) > 0 {
+			changes = append(changes, c)
+		}
+	}
+	for k, members := range before {
+		if _, ok := after[k]; !ok {
+			changes = append(changes, UnionChange{Package: k.pkg, Name: k.name, Removed: members, Deleted: true})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Package != changes[j].Package {
+			return changes[i].Package < changes[j].Package
+		}
+		return changes[i].Name < changes[j].Name
+	})
+
+	for i := range changes {
+		c := &changes[i]
+		for _, sw := range new.Switches {
+			if sw.UnionPkg == c.Package && sw.Union == c.Name {
+				c.Switches = append(c.Switches, sw)
+			}
+		}
+	}
+	return changes
+}
+
+// memberDelta returns the members of new not in old, and of old not in
+// new, in their order of declaration.
+func memberDelta(old, new []string) (added, removed []string) {
+	in := func(list []string, m string) bool {
+		for _, x := range list {
+			if x == m {
+				return true
+			}
+		}
+		return false
+	}
+	for _, m := range new {
+		if !in(old, m) {
+			added = append(added, m)
+		}
+	}
+	for _, m := range old {
+		if !in(new, m) {
+			removed = append(removed, m)
+		}
+	}
+	return added, removed
+}
+
+// PrintUnionDiff writes the changes, one union per line followed by the
+// switches on it in the newer revision, indented, so that reviewers see
+// the sum-type changes of a branch and the code they touch, e.g.
+//
+//	example.com/shape.Shape: added *Pentagon; removed *Triangle
+//		shape/area.go:12:2: type switch in area: missing shape.*Pentagon
+//		shape/name.go:27:2: type switch in name: missing shape.*Pentagon; default
+func PrintUnionDiff(w io.Writer, changes []UnionChange) error {
+	for _, c := range changes {
+		var line string
+		switch {
+		case c.New:
+			line = "new union of " + strings.Join(c.Added, ", ")
+		case c.Deleted:
+			line = "union removed"
+		default:
+			var parts []string
+			if len(c.Added) > 0 {
+				parts = append(parts, "added "+strings.Join(c.Added, ", "))
+			}
+			if len(c.Removed) > 0 {
+				parts = append(parts, "removed "+strings.Join(c.Removed, ", "))
+			}
+			line = strings.Join(parts, "; ")
+		}
+		if _, err := fmt.Fprintf(w, "%s.%s: %s\n", c.Package, c.Name, line); err != nil {
+			return err
+		}
+
+		for _, sw := range c.Switches {
+			line := fmt.Sprintf("\t%s:%d:%d: type switch", relPath(sw.Posn.Filename), sw.Posn.Line, sw.Posn.Column)
+			if sw.Func != "" {
+				line += " in " + sw.Func
+			}
+			if len(sw.Missing) > 0 {
+				line += ": missing " + strings.Join(sw.Missing, ", ")
+			}
+			if sw.Default {
+				line += "; default"
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CheckRevision is like Check, but loads the packages from the git
+// revision rev, e.g. "origin/main", of the repository containing
+// opts.Dir. The files of the revision are extracted to a temporary
+// directory, so the working tree is left untouched; positions in the
+// report refer to the removed copies.
+func CheckRevision(a *analysis.Analyzer, patterns []string, opts Options, rev string) (*Report, error) {
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	top, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := git(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "gounion-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "archive", "--format=tar", rev)
+	cmd.Dir = top
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	extractErr := extractTar(out, tmp)
+	io.Copy(io.Discard, out)
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("git archive %s: %v: %s", rev, err, strings.TrimSpace(stderr.String()))
+	}
+	if extractErr != nil {
+		return nil, fmt.Errorf("extracting %s: %v", rev, extractErr)
+	}
+
+	opts.Dir = filepath.Join(tmp, filepath.FromSlash(prefix))
+	return Check(a, patterns, opts)
+}
+
+// git runs git with args in dir and returns its output, trimmed.
+func git(dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// extractTar extracts the directories, regular files and symbolic links
+// of the tar archive read from r into dir.
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !filepath.IsLocal(hdr.Name) {
+			return errors.New("invalid file name " + hdr.Name)
+		}
+		name := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(name, 0o755)
+		case tar.TypeReg:
+			err = writeTarFile(name, tr, hdr.FileInfo().Mode().Perm())
+		case tar.TypeSymlink:
+			err = os.Symlink(hdr.Linkname, name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// writeTarFile writes the contents of the current file of tr to name.
+func writeTarFile(name string, tr *tar.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, tr); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,204 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"reflect"
+)
+
+// genAvro emits <Union>AvroSchema, a constant holding the Avro schema of
+// the union: a union of one record per member, named after the member in
+// the namespace of the package. Marshal<Union>Avro and
+// Unmarshal<Union>Avro encode and decode union values in the Avro binary
+// encoding with hamba/avro. Record fields are named by their avro tag, as
+// hamba/avro does; recursive unions and fields of interface types are not
+// supported.
+func genAvro(f *File, u Union) error {
+	a := &avroBuilder{namespace: f.pkg.Name(), union: u, defined: make(map[*types.TypeName]bool)}
+	var records []any
+	for _, m := range u.Members {
+		if m.Obj == nil {
+			return fmt.Errorf("%s is not a named type", m.Name)
+		}
+		record, err := a.record(m.Obj)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.Name, err)
+		}
+		records = append(records, record)
+	}
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	if bytes.IndexByte(data, '`') >= 0 {
+		return fmt.Errorf("schema contains a backquote")
+	}
+
+	f.Import("encoding/binary")
+	f.Import("errors")
+	f.Import("fmt")
+	f.Import("github.com/hamba/avro/v2")
+
+	name := u.Name + "AvroSchema"
+	branches := lowerFirst(u.Name) + "AvroBranches"
+	f.Printf("\n// %s is the Avro schema of the %s union, with a record per member.\n", name, u.Name)
+	f.Printf("const %s = `%s`\n", name, data)
+
+	f.Printf("\n// %s are the parsed record schemas of the %s members, by union index.\n", branches, u.Name)
+	f.Printf("var %s = avro.MustParse(%s).(*avro.UnionSchema).Types()\n", branches, name)
+
+	f.Printf("\n// Marshal%sAvro encodes u in the Avro binary encoding of %s.\n", u.Name, name)
+	f.Printf("func Marshal%sAvro(u %s) ([]byte, error) {\n", u.Name, u.Name)
+	f.Printf("\tvar index int\n")
+	f.Printf("\tswitch u.(type) {\n")
+	f.Printf("\tcase nil:\n\t\treturn nil, errors.New(\"nil %s\")\n", u.Name)
+	for i, m := range u.Members {
+		f.Printf("\tcase %s:\n\t\tindex = %d\n", m.TypeExpr(), i)
+	}
+	f.Printf("\tdefault:\n\t\treturn nil, fmt.Errorf(\"%%T is not a member of %s\", u)\n\t}\n", u.Name)
+	f.Printf("\tdata, err := avro.Marshal(%s[index], u)\n", branches)
+	f.Printf("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	f.Printf("\treturn append(binary.AppendVarint(nil, int64(index)), data...), nil\n}\n")
+
+	f.Printf("\n// Unmarshal%sAvro decodes a %s from its Avro binary encoding.\n", u.Name, u.Name)
+	f.Printf("func Unmarshal%sAvro(data []byte) (%s, error) {\n", u.Name, u.Name)
+	f.Printf("\tindex, n := binary.Varint(data)\n")
+	f.Printf("\tif n <= 0 {\n\t\treturn nil, errors.New(\"invalid %s union index\")\n\t}\n", u.Name)
+	f.Printf("\tdata = data[n:]\n")
+	f.Printf("\tswitch index {\n")
+	for i, m := range u.Members {
+		f.Printf("\tcase %d:\n", i)
+		f.Printf("\t\tvar m %s\n", m.Name)
+		f.Printf("\t\tif err := avro.Unmarshal(%s[%d], data, &m); err != nil {\n\t\t\treturn nil, err\n\t\t}\n", branches, i)
+		if m.Pointer {
+			f.Printf("\t\treturn &m, nil\n")
+		} else {
+			f.Printf("\t\treturn m, nil\n")
+		}
+	}
+	f.Printf("\t}\n")
+	f.Printf("\treturn nil, fmt.Errorf(\"unknown %s union index %%d\", index)\n}\n", u.Name)
+
+	return nil
+}
+
+// avroRecord is an Avro record schema.
+type avroRecord struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Fields    []avroField `json:"fields"`
+}
+
+// avroField is a field of an Avro record schema.
+type avroField struct {
+	Name string `json:"name"`
+	Type any    `json:"type"`
+}
+
+// avroComplex is an Avro array, map or logical type schema.
+type avroComplex struct {
+	Type        string `json:"type"`
+	Items       any    `json:"items,omitempty"`
+	Values      any    `json:"values,omitempty"`
+	LogicalType string `json:"logicalType,omitempty"`
+}
+
+// avroBuilder derives the Avro schemas of Go types. Named records are
+// defined once and referred to by name afterwards.
+type avroBuilder struct {
+	namespace string
+	union     Union
+	defined   map[*types.TypeName]bool
+}
+
+// record returns the record schema of the named struct type obj, or its
+// name if it was already defined.
+func (a *avroBuilder) record(obj *types.TypeName) (any, error) {
+	if a.defined[obj] {
+		return a.namespace + "." + obj.Name(), nil
+	}
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a struct", obj.Name())
+	}
+	a.defined[obj] = true
+
+	record := avroRecord{Type: "record", Name: obj.Name(), Namespace: a.namespace, Fields: []avroField{}}
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		name, ok := reflect.StructTag(st.Tag(i)).Lookup("avro")
+		if name == "-" || !field.Exported() && !ok {
+			continue
+		}
+		if name == "" {
+			name = field.Name()
+		}
+		typ, err := a.schemaOf(field.Type())
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name(), err)
+		}
+		record.Fields = append(record.Fields, avroField{Name: name, Type: typ})
+	}
+	return record, nil
+}
+
+// schemaOf returns the Avro schema of values of typ as hamba/avro encodes
+// them.
+func (a *avroBuilder) schemaOf(typ types.Type) (any, error) {
+	typ = types.Unalias(typ)
+	if named, ok := typ.(*types.Named); ok {
+		obj := named.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+			return avroComplex{Type: "long", LogicalType: "timestamp-micros"}, nil
+		}
+		if _, ok := named.Underlying().(*types.Struct); ok && obj.Pkg() == a.union.Obj.Pkg() {
+			return a.record(obj)
+		}
+	}
+
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		switch t.Kind() {
+		case types.Bool:
+			return "boolean", nil
+		case types.Int8, types.Int16, types.Int32:
+			return "int", nil
+		case types.Int, types.Int64:
+			return "long", nil
+		case types.Float32:
+			return "float", nil
+		case types.Float64:
+			return "double", nil
+		case types.String:
+			return "string", nil
+		}
+	case *types.Pointer:
+		elem, err := a.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return []any{"null", elem}, nil
+	case *types.Slice:
+		if b, ok := t.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return "bytes", nil
+		}
+		items, err := a.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return avroComplex{Type: "array", Items: items}, nil
+	case *types.Map:
+		if b, ok := t.Key().Underlying().(*types.Basic); ok && b.Kind() == types.String {
+			values, err := a.schemaOf(t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			return avroComplex{Type: "map", Values: values}, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported type %s", types.TypeString(typ, types.RelativeTo(a.union.Obj.Pkg())))
+}
@@ -0,0 +1,76 @@
+package gen
+
+// genBSON emits Register<Union>BSON, which registers a bsoncodec encoder
+// and decoder for the union with a mongo-driver registry. Members are
+// encoded as documents with their name in a "type" field as discriminator,
+// so that union fields of stored documents round-trip.
+func genBSON(f *File, u Union) error {
+	f.Import("fmt")
+	f.Import("reflect")
+	f.Import("go.mongodb.org/mongo-driver/bson")
+	f.Import("go.mongodb.org/mongo-driver/bson/bsoncodec")
+	f.Import("go.mongodb.org/mongo-driver/bson/bsonrw")
+	f.Import("go.mongodb.org/mongo-driver/bson/bsontype")
+
+	f.Helper("bsonDiscriminator", func() {
+		f.Printf("\n// gounionBSONDiscriminator is the field naming the member of a BSON encoded union.\n")
+		f.Printf("const gounionBSONDiscriminator = \"type\"\n")
+	})
+
+	typeVar := lowerFirst(u.Name) + "BSONType"
+	encode := "encode" + u.Name + "BSON"
+	decode := "decode" + u.Name + "BSON"
+
+	f.Printf("\n// %s is the type of %s union values.\n", typeVar, u.Name)
+	f.Printf("var %s = reflect.TypeFor[%s]()\n", typeVar, u.Name)
+
+	f.Printf("\n// Register%sBSON registers the BSON encoding of %s values with r.\n", u.Name, u.Name)
+	f.Printf("func Register%sBSON(r *bsoncodec.Registry) {\n", u.Name)
+	f.Printf("\tr.RegisterTypeEncoder(%s, bsoncodec.ValueEncoderFunc(%s))\n", typeVar, encode)
+	f.Printf("\tr.RegisterTypeDecoder(%s, bsoncodec.ValueDecoderFunc(%s))\n}\n", typeVar, decode)
+
+	f.Printf("\n// %s encodes the member held by val as a document starting\n", encode)
+	f.Printf("// with the discriminator, or as null if val is nil.\n")
+	f.Printf("func %s(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {\n", encode)
+	f.Printf("\tif val.IsNil() {\n\t\treturn vw.WriteNull()\n\t}\n")
+	f.Printf("\tvar name string\n")
+	f.Printf("\tswitch val.Interface().(type) {\n")
+	for _, m := range u.Members {
+		f.Printf("\tcase %s:\n\t\tname = %q\n", m.TypeExpr(), m.Discriminator)
+	}
+	f.Printf("\tdefault:\n\t\treturn fmt.Errorf(\"%%s is not a member of %s\", val.Elem().Type())\n\t}\n", u.Name)
+	f.Printf("\traw, err := bson.MarshalWithRegistry(ec.Registry, val.Interface())\n")
+	f.Printf("\tif err != nil {\n\t\treturn err\n\t}\n")
+	f.Printf("\tvar fields bson.D\n")
+	f.Printf("\tif err := bson.UnmarshalWithRegistry(ec.Registry, raw, &fields); err != nil {\n\t\treturn err\n\t}\n")
+	f.Printf("\tdoc := append(bson.D{{Key: gounionBSONDiscriminator, Value: name}}, fields...)\n")
+	f.Printf("\tenc, err := ec.LookupEncoder(reflect.TypeOf(doc))\n")
+	f.Printf("\tif err != nil {\n\t\treturn err\n\t}\n")
+	f.Printf("\treturn enc.EncodeValue(ec, vw, reflect.ValueOf(doc))\n}\n")
+
+	f.Printf("\n// %s decodes the member named by the discriminator of a document.\n", decode)
+	f.Printf("func %s(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {\n", decode)
+	f.Printf("\tif vr.Type() == bsontype.Null {\n")
+	f.Printf("\t\tval.Set(reflect.Zero(val.Type()))\n\t\treturn vr.ReadNull()\n\t}\n")
+	f.Printf("\traw, err := bsonrw.Copier{}.CopyDocumentToBytes(vr)\n")
+	f.Printf("\tif err != nil {\n\t\treturn err\n\t}\n")
+	f.Printf("\tname, ok := bson.Raw(raw).Lookup(gounionBSONDiscriminator).StringValueOK()\n")
+	f.Printf("\tif !ok {\n\t\treturn fmt.Errorf(\"missing discriminator field %%q for %s\", gounionBSONDiscriminator)\n\t}\n", u.Name)
+	f.Printf("\tvar u %s\n", u.Name)
+	f.Printf("\tswitch name {\n")
+	for _, m := range u.Members {
+		f.Printf("\tcase %q:\n", m.Discriminator)
+		f.Printf("\t\tvar m %s\n", m.Name)
+		f.Printf("\t\tif err := bson.UnmarshalWithRegistry(dc.Registry, raw, &m); err != nil {\n\t\t\treturn err\n\t\t}\n")
+		if m.Pointer {
+			f.Printf("\t\tu = &m\n")
+		} else {
+			f.Printf("\t\tu = m\n")
+		}
+	}
+	f.Printf("\tdefault:\n\t\treturn fmt.Errorf(\"unknown %s member %%q\", name)\n\t}\n", u.Name)
+	f.Printf("\tval.Set(reflect.ValueOf(&u).Elem())\n")
+	f.Printf("\treturn nil\n}\n")
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package gen
+
+import "fmt"
+
+// genBuilder emits a fluent builder per struct member with exported
+// fields: New<Member>Builder() returns a <Member>Builder with a setter per
+// exported field, and Build returns the member as the union, e.g.
+// NewCircleBuilder().Radius(2).Build(). A member shared by several unions
+// gets one builder, whose Build returns the first of them.
+func genBuilder(f *File, u Union) error {
+	for _, m := range u.Members {
+		st := structOf(m)
+		if st == nil {
+			continue
+		}
+		var fields []int
+		for i := 0; i < st.NumFields(); i++ {
+			if field := st.Field(i); field.Exported() {
+				if field.Name() == "Build" {
+					return fmt.Errorf("%s has a field named Build", m.Name)
+				}
+				fields = append(fields, i)
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		f.Helper("builder"+m.Name, func() {
+			builder := m.Name + "Builder"
+			f.Printf("\n// %s builds a %s field by field.\n", builder, m.TypeExpr())
+			f.Printf("type %s struct {\n\tm %s\n}\n", builder, m.Name)
+
+			f.Printf("\n// New%s returns a builder of a zero %s.\n", builder, m.TypeExpr())
+			f.Printf("func New%s() *%s {\n\treturn new(%s)\n}\n", builder, builder, builder)
+
+			for _, i := range fields {
+				field := st.Field(i)
+				f.Printf("\n// %s sets the %s field.\n", field.Name(), field.Name())
+				f.Printf("func (b *%s) %s(v %s) *%s {\n", builder, field.Name(), f.TypeString(field.Type()), builder)
+				f.Printf("\tb.m.%s = v\n\treturn b\n}\n", field.Name())
+			}
+
+			f.Printf("\n// Build returns the built %s as a %s. The builder can be reused.\n", m.TypeExpr(), u.Name)
+			f.Printf("func (b *%s) Build() %s {\n", builder, u.Name)
+			if m.Pointer {
+				f.Printf("\tm := b.m\n\treturn &m\n}\n")
+			} else {
+				f.Printf("\treturn b.m\n}\n")
+			}
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package gen
+
+import (
+	"reflect"
+	"strings"
+)
+
+// genMap emits <Union>ToMap and <Union>FromMap, converting union values to
+// and from the generic map[string]any form of their JSON encoding, with
+// the member name in a "type" field, for dynamic APIs and document stores.
+// Member fields of unions generated for in the same file are converted
+// recursively, so that they keep their own "type" field; fields of other
+// types, including slices and maps of unions, are converted by
+// encoding/json alone.
+func genMap(f *File, u Union) error {
+	f.Helper("map"+u.Name, func() {
+		f.Import("encoding/json")
+		f.Import("errors")
+		f.Import("fmt")
+
+		fields := make([][]mapField, len(u.Members))
+		nested := false
+		for i, m := range u.Members {
+			fields[i] = mapFields(f, m)
+			nested = nested || len(fields[i]) > 0
+		}
+		if nested {
+			genMapFieldHelpers(f)
+		}
+
+		f.Printf("\n// %sToMap returns the JSON object form of u, with the member name in\n", u.Name)
+		f.Printf("// a \"type\" field.\n")
+		f.Printf("func %sToMap(u %s) (map[string]any, error) {\n", u.Name, u.Name)
+		f.Printf("\tvar name string\n")
+		f.Printf("\tswitch u.(type) {\n")
+		for _, m := range u.Members {
+			f.Printf("\tcase %s:\n\t\tname = %q\n", m.TypeExpr(), m.Discriminator)
+		}
+		f.Printf("\tdefault:\n\t\treturn nil, fmt.Errorf(\"%%T is not a member of %s\", u)\n\t}\n", u.Name)
+		f.Printf("\tdata, err := json.Marshal(u)\n")
+		f.Printf("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+		f.Printf("\tm := make(map[string]any)\n")
+		f.Printf("\tif err := json.Unmarshal(data, &m); err != nil {\n")
+		f.Printf("\t\treturn nil, fmt.Errorf(\"%%T does not encode as a JSON object: %%w\", u, err)\n\t}\n")
+		f.Printf("\tm[\"type\"] = name\n")
+		if nested {
+			f.Printf("\tswitch v := u.(type) {\n")
+			for i, m := range u.Members {
+				if len(fields[i]) == 0 {
+					continue
+				}
+				f.Printf("\tcase %s:\n", m.TypeExpr())
+				for _, field := range fields[i] {
+					f.Printf("\t\tif err := gounionToMapField(m, %q, v.%s, %sToMap); err != nil {\n\t\t\treturn nil, err\n\t\t}\n",
+						field.key, field.name, field.union.Name)
+				}
+			}
+			f.Printf("\t}\n")
+		}
+		f.Printf("\treturn m, nil\n}\n")
+
+		f.Printf("\n// %sFromMap returns the %s member named by the \"type\" field of m,\n", u.Name, u.Name)
+		f.Printf("// decoded from the other fields.\n")
+		f.Printf("func %sFromMap(m map[string]any) (%s, error) {\n", u.Name, u.Name)
+		f.Printf("\tname, ok := m[\"type\"].(string)\n")
+		f.Printf("\tif !ok {\n\t\treturn nil, errors.New(`missing discriminator field \"type\" for %s`)\n\t}\n", u.Name)
+		f.Printf("\tswitch name {\n")
+		for i, m := range u.Members {
+			f.Printf("\tcase %q:\n", m.Discriminator)
+			f.Printf("\t\tvar v %s\n", m.Name)
+			if len(fields[i]) == 0 {
+				f.Printf("\t\tif err := gounionFromMap(m, &v); err != nil {\n\t\t\treturn nil, err\n\t\t}\n")
+			} else {
+				keys := make([]string, len(fields[i]))
+				for j, field := range fields[i] {
+					keys[j] = field.key
+				}
+				f.Printf("\t\tif err := gounionFromMap(m, &v, %s); err != nil {\n\t\t\treturn nil, err\n\t\t}\n", quoteList(keys))
+				for _, field := range fields[i] {
+					f.Printf("\t\tif err := gounionFromMapField(m, %q, &v.%s, %sFromMap); err != nil {\n\t\t\treturn nil, err\n\t\t}\n",
+						field.key, field.name, field.union.Name)
+				}
+			}
+			if m.Pointer {
+				f.Printf("\t\treturn &v, nil\n")
+			} else {
+				f.Printf("\t\treturn v, nil\n")
+			}
+		}
+		f.Printf("\t}\n")
+		f.Printf("\treturn nil, fmt.Errorf(\"unknown %s member %%q\", name)\n}\n", u.Name)
+	})
+
+	f.Helper("mapDecode", func() {
+		f.Import("encoding/json")
+		f.Printf("\n// gounionFromMap decodes the fields of m into *v with encoding/json,\n")
+		f.Printf("// leaving out the fields with the given keys.\n")
+		f.Printf("func gounionFromMap[T any](m map[string]any, v *T, skip ...string) error {\n")
+		f.Printf("\tif len(skip) > 0 {\n")
+		f.Printf("\t\trest := make(map[string]any, len(m))\n")
+		f.Printf("\t\tfor key, value := range m {\n\t\t\trest[key] = value\n\t\t}\n")
+		f.Printf("\t\tfor _, key := range skip {\n\t\t\tdelete(rest, key)\n\t\t}\n")
+		f.Printf("\t\tm = rest\n\t}\n")
+		f.Printf("\tdata, err := json.Marshal(m)\n")
+		f.Printf("\tif err != nil {\n\t\treturn err\n\t}\n")
+		f.Printf("\treturn json.Unmarshal(data, v)\n}\n")
+	})
+
+	return nil
+}
+
+// mapField is a member field of a union generated for in the same file,
+// converted to and from maps recursively.
+type mapField struct {
+	name  string // Go field name
+	key   string // JSON object key
+	union Union
+}
+
+// mapFields returns the fields of m converted recursively by the map
+// target: its exported fields of unions generated for in f, unless
+// encoding/json leaves them out.
+func mapFields(f *File, m Member) []mapField {
+	st := structOf(m)
+	if st == nil {
+		return nil
+	}
+	var fields []mapField
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		fu, ok := f.UnionOf(field.Type())
+		if !ok || !field.Exported() {
+			continue
+		}
+		key, opts, _ := strings.Cut(reflect.StructTag(st.Tag(i)).Get("json"), ",")
+		if key == "-" && opts == "" {
+			continue
+		}
+		if key == "" {
+			key = field.Name()
+		}
+		fields = append(fields, mapField{name: field.Name(), key: key, union: fu})
+	}
+	return fields
+}
+
+// genMapFieldHelpers emits the helpers converting union fields of members
+// to and from maps.
+func genMapFieldHelpers(f *File) {
+	f.Helper("mapField", func() {
+		f.Printf("\n// gounionToMapField replaces the JSON encoding of the union field u under\n")
+		f.Printf("// key in m by its map form returned by toMap, if it is encoded.\n")
+		f.Printf("func gounionToMapField[U comparable](m map[string]any, key string, u U, toMap func(U) (map[string]any, error)) error {\n")
+		f.Printf("\tvar zero U\n")
+		f.Printf("\tif _, ok := m[key]; !ok || u == zero {\n\t\treturn nil\n\t}\n")
+		f.Printf("\tv, err := toMap(u)\n")
+		f.Printf("\tif err != nil {\n\t\treturn fmt.Errorf(\"%%s: %%w\", key, err)\n\t}\n")
+		f.Printf("\tm[key] = v\n")
+		f.Printf("\treturn nil\n}\n")
+
+		f.Printf("\n// gounionFromMapField sets *p to the union decoded by fromMap from the map\n")
+		f.Printf("// form under key in m, leaving it unchanged if the key is missing or null.\n")
+		f.Printf("func gounionFromMapField[U any](m map[string]any, key string, p *U, fromMap func(map[string]any) (U, error)) error {\n")
+		f.Printf("\tswitch v := m[key].(type) {\n")
+		f.Printf("\tcase nil:\n\t\treturn nil\n")
+		f.Printf("\tcase map[string]any:\n")
+		f.Printf("\t\tu, err := fromMap(v)\n")
+		f.Printf("\t\tif err != nil {\n\t\t\treturn fmt.Errorf(\"%%s: %%w\", key, err)\n\t\t}\n")
+		f.Printf("\t\t*p = u\n")
+		f.Printf("\t\treturn nil\n")
+		f.Printf("\tdefault:\n\t\treturn fmt.Errorf(\"%%s: %%T is not a JSON object\", key, v)\n\t}\n}\n")
+	})
+}
+
+// genStructpb emits <Union>ToStruct and <Union>FromStruct, converting
+// union values to and from protobuf Struct values, in the form of the map
+// target (whose functions are generated as well).
+func genStructpb(f *File, u Union) error {
+	if err := genMap(f, u); err != nil {
+		return err
+	}
+	f.Import("google.golang.org/protobuf/types/known/structpb")
+
+	f.Printf("\n// %sToStruct returns u as a google.protobuf.Struct, in the form of %sToMap.\n", u.Name, u.Name)
+	f.Printf("func %sToStruct(u %s) (*structpb.Struct, error) {\n", u.Name, u.Name)
+	f.Printf("\tm, err := %sToMap(u)\n", u.Name)
+	f.Printf("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	f.Printf("\treturn structpb.NewStruct(m)\n}\n")
+
+	f.Printf("\n// %sFromStruct returns the %s held by s, as %sFromMap does.\n", u.Name, u.Name, u.Name)
+	f.Printf("func %sFromStruct(s *structpb.Struct) (%s, error) {\n", u.Name, u.Name)
+	f.Printf("\treturn %sFromMap(s.AsMap())\n}\n", u.Name)
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package gen
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// genEqual emits Equal<Union>(a, b), reporting whether a and b hold the
+// same member with equal fields, for comparing decoded values in
+// round-trip tests (see uniontest.AssertRoundTrips). Fields of unions
+// generated for in the same file, e.g. Left Expr, are compared
+// recursively; other fields are compared with == if that cannot panic,
+// and with reflect.DeepEqual otherwise.
+func genEqual(f *File, u Union) error {
+	fn := "Equal" + u.Name
+
+	f.Printf("\n// %s reports whether a and b hold the same member of the %s union\n", fn, u.Name)
+	f.Printf("// with equal fields. Two nil values are equal.\n")
+	f.Printf("func %s(a, b %s) bool {\n", fn, u.Name)
+	f.Printf("\tswitch a := a.(type) {\n")
+	for _, m := range u.Members {
+		f.Printf("\tcase %s:\n", m.TypeExpr())
+		f.Printf("\t\tb, ok := b.(%s)\n", m.TypeExpr())
+		eq := equalExpr(f, m, "a", "b")
+		switch {
+		case m.Pointer && eq == "":
+			f.Printf("\t\treturn ok && (a == b || a != nil && b != nil)\n")
+		case m.Pointer:
+			f.Printf("\t\treturn ok && (a == b || a != nil && b != nil && %s)\n", eq)
+		case eq == "":
+			f.Printf("\t\treturn ok\n")
+		default:
+			f.Printf("\t\treturn ok && %s\n", eq)
+		}
+	}
+	f.Printf("\t}\n")
+	f.Printf("\treturn a == nil && b == nil\n}\n")
+
+	return nil
+}
+
+// equalExpr returns the expression comparing the non-nil members a and b
+// of type m, field by field if m is a struct, or "" if a struct member has
+// no fields to compare.
+func equalExpr(f *File, m Member, a, b string) string {
+	var st *types.Struct
+	if m.Obj != nil {
+		st, _ = m.Obj.Type().Underlying().(*types.Struct)
+	}
+	if st == nil {
+		if m.Pointer {
+			a, b = "*"+a, "*"+b
+		}
+		if m.Obj == nil {
+			f.Import("reflect")
+			return fmt.Sprintf("reflect.DeepEqual(%s, %s)", a, b)
+		}
+		return valueEqual(f, m.Obj.Type(), a, b)
+	}
+
+	var parts []string
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if field.Name() == "_" {
+			continue
+		}
+		parts = append(parts, valueEqual(f, field.Type(), a+"."+field.Name(), b+"."+field.Name()))
+	}
+	return strings.Join(parts, " && ")
+}
+
+// valueEqual returns the expression comparing the values a and b of type
+// typ.
+func valueEqual(f *File, typ types.Type, a, b string) string {
+	if u, ok := f.UnionOf(typ); ok {
+		return fmt.Sprintf("Equal%s(%s, %s)", u.Name, a, b)
+	}
+	if strictlyComparable(typ) {
+		return a + " == " + b
+	}
+	f.Import("reflect")
+	return fmt.Sprintf("reflect.DeepEqual(%s, %s)", a, b)
+}
+
+// strictlyComparable reports whether values of typ can be compared with ==
+// without panicking, which rules out interfaces and type parameters, whose
+// dynamic values may not be comparable.
+func strictlyComparable(typ types.Type) bool {
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		return t.Kind() != types.UntypedNil
+	case *types.Pointer, *types.Chan:
+		return true
+	case *types.Array:
+		return strictlyComparable(t.Elem())
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if !strictlyComparable(t.Field(i).Type()) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
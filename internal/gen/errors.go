@@ -0,0 +1,88 @@
+package gen
+
+import (
+	"fmt"
+	"go/types"
+	"slices"
+	"strings"
+)
+
+// genErrors emits, for an error union, a sentinel per member and one for
+// the union, named Err<name> after the type name without its Error suffix,
+// e.g. ErrNotFound for NotFoundError and ErrStore for StoreError. Each
+// member gets an Is method matching its own sentinel and the union's, so
+// that errors.Is(err, ErrStore) finds any member in the chain of err, and
+// As<Union>(err) finds the member itself, as by errors.As. Sentinels whose
+// names collide or are taken in the package, and members that already
+// have an Is method, are an error. Unions that are not error unions are
+// skipped, unless none of the unions generated for is one.
+func genErrors(f *File, u Union) error {
+	if !isErrorUnion(u) {
+		if slices.ContainsFunc(f.unions, isErrorUnion) {
+			return nil
+		}
+		return fmt.Errorf("not all members implement error")
+	}
+
+	scope := f.pkg.Scope()
+	union := sentinelName(u.Name)
+	sentinels := make([]string, len(u.Members))
+	for i, m := range u.Members {
+		sentinels[i] = sentinelName(m.Name)
+		if sel := types.NewMethodSet(types.NewPointer(m.Obj.Type())).Lookup(f.pkg, "Is"); sel != nil {
+			return fmt.Errorf("member %s already has an Is method", m.Name)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, name := range append([]string{union}, sentinels...) {
+		if seen[name] {
+			return fmt.Errorf("several sentinels are named %s", name)
+		}
+		seen[name] = true
+		if scope.Lookup(name) != nil {
+			return fmt.Errorf("sentinel %s is already declared in package %s", name, f.pkg.Name())
+		}
+	}
+
+	f.Import("errors")
+
+	f.Printf("\n// Sentinels of the %s union: errors.Is(err, %s) reports whether the\n", u.Name, union)
+	f.Printf("// chain of err has a %s member, and the sentinel of a member whether\n", u.Name)
+	f.Printf("// it has that member.\n")
+	f.Printf("var (\n")
+	f.Printf("\t%s = errors.New(%q)\n", union, sentinelMessage(u.Name))
+	for i, m := range u.Members {
+		f.Printf("\t%s = errors.New(%q)\n", sentinels[i], sentinelMessage(m.Name))
+	}
+	f.Printf(")\n")
+
+	for i, m := range u.Members {
+		f.Printf("\n// Is reports whether target is %s or %s.\n", sentinels[i], union)
+		f.Printf("func (%s) Is(target error) bool {\n", m.TypeExpr())
+		f.Printf("\treturn target == %s || target == %s\n}\n", sentinels[i], union)
+	}
+
+	fn := "As" + u.Name
+	f.Printf("\n// %s returns the first %s member in the chain of err, as by errors.As.\n", fn, u.Name)
+	f.Printf("func %s(err error) (%s, bool) {\n", fn, u.Name)
+	f.Printf("\tvar u %s\n", u.Name)
+	f.Printf("\tok := errors.As(err, &u)\n")
+	f.Printf("\treturn u, ok\n}\n")
+
+	return nil
+}
+
+// sentinelName returns the name of the sentinel error of an error type,
+// e.g. ErrNotFound for NotFoundError.
+func sentinelName(name string) string {
+	if trimmed := strings.TrimSuffix(name, "Error"); trimmed != "" {
+		name = trimmed
+	}
+	return "Err" + name
+}
+
+// sentinelMessage returns the message of the sentinel error of an error
+// type: its name in lower case words, e.g. "not found error".
+func sentinelMessage(name string) string {
+	return strings.ReplaceAll(snakeCase(name), "_", " ")
+}
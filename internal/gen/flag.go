@@ -0,0 +1,65 @@
+package gen
+
+import "strings"
+
+// genFlag emits <Union>Flag, a flag.Value (and a pflag.Value, as used by
+// cobra) selecting a member by name, for unions of modes or strategies.
+// Names match case-insensitively; members are created with their zero
+// value unless the flag configures a constructor for them.
+func genFlag(f *File, u Union) error {
+	f.Import("fmt")
+	f.Import("strings")
+
+	flagType := u.Name + "Flag"
+	names := make([]string, len(u.Members))
+	for i, m := range u.Members {
+		names[i] = m.Name
+	}
+
+	f.Printf("\n// %s is a flag.Value selecting a %s member by name, case-insensitively.\n", flagType, u.Name)
+	f.Printf("// It also implements the pflag.Value interface used by cobra.\n")
+	f.Printf("type %s struct {\n", flagType)
+	f.Printf("\t%s %s // the selected member, nil if none\n\n", u.Name, u.Name)
+	f.Printf("\t// Constructors overrides, by member name, how selected members are\n")
+	f.Printf("\t// created, e.g. to configure them. Other members are zero values.\n")
+	f.Printf("\tConstructors map[string]func() %s\n}\n", u.Name)
+
+	f.Printf("\n// %sValues returns the names accepted by %s.\n", flagType, flagType)
+	f.Printf("func %sValues() []string {\n", flagType)
+	f.Printf("\treturn []string{%s}\n}\n", quoteList(names))
+
+	f.Printf("\n// String implements flag.Value.\n")
+	f.Printf("func (f *%s) String() string {\n", flagType)
+	f.Printf("\tif f == nil {\n\t\treturn \"\"\n\t}\n")
+	f.Printf("\tswitch f.%s.(type) {\n", u.Name)
+	for _, m := range u.Members {
+		f.Printf("\tcase %s:\n\t\treturn %q\n", m.TypeExpr(), m.Name)
+	}
+	f.Printf("\t}\n\treturn \"\"\n}\n")
+
+	f.Printf("\n// Set implements flag.Value.\n")
+	f.Printf("func (f *%s) Set(s string) error {\n", flagType)
+	f.Printf("\tfor _, name := range %sValues() {\n", flagType)
+	f.Printf("\t\tif !strings.EqualFold(s, name) {\n\t\t\tcontinue\n\t\t}\n")
+	f.Printf("\t\tif c, ok := f.Constructors[name]; ok {\n\t\t\tf.%s = c()\n\t\t\treturn nil\n\t\t}\n", u.Name)
+	f.Printf("\t\tswitch name {\n")
+	for _, m := range u.Members {
+		f.Printf("\t\tcase %q:\n\t\t\tf.%s = %s\n", m.Name, u.Name, newMemberExpr(m))
+	}
+	f.Printf("\t\t}\n\t\treturn nil\n\t}\n")
+	f.Printf("\treturn fmt.Errorf(\"unknown %s %%q (want one of %%s)\", s, strings.Join(%sValues(), \", \"))\n}\n", u.Name, flagType)
+
+	f.Printf("\n// Type implements pflag.Value.\n")
+	f.Printf("func (f *%s) Type() string {\n\treturn %q\n}\n", flagType, lowerFirst(u.Name))
+
+	return nil
+}
+
+// quoteList returns names as a comma-separated list of Go string literals.
+func quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = `"` + name + `"`
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,331 @@
+// Package gen implements the code generator behind the gounion-gen command.
+//
+// The generator detects union interfaces in a package with the same rules
+// as the gounion analyzer and emits helper code for them. Each kind of
+// helper is a target, selected by name.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+)
+
+// Header is the first line of every generated file.
+const Header = "// Code generated by gounion-gen. DO NOT EDIT."
+
+// Options selects what to generate.
+type Options struct {
+	Types   []string    // names of the unions to generate for; empty means all
+	Targets []string    // names of the targets to generate
+	Files   []*ast.File // syntax of the package, for its //gounion:enum types
+
+	// Lock holds the discriminators of members locked before, which the
+	// targets encoding them must not change. If it is not nil, Generate
+	// records the current discriminators of the unions generated for.
+	Lock registry.DiscriminatorLock
+}
+
+// Union is a union interface for which code is generated.
+type Union struct {
+	Name    string
+	Obj     *types.TypeName
+	Members []Member
+
+	fact *registry.UnionInterface
+}
+
+// Member is a member type of a union.
+type Member struct {
+	Name          string // type name without pointer, e.g. "Circle"
+	Pointer       bool   // whether the member implements the union on the pointer type
+	Obj           *types.TypeName
+	Discriminator string // identifies the member in encoded values; see registry.DiscriminatorTag
+}
+
+// TypeExpr returns the member type as written in a case clause, e.g. "*Circle".
+func (m Member) TypeExpr() string {
+	if m.Pointer {
+		return "*" + m.Name
+	}
+	return m.Name
+}
+
+// target emits the code of one generation target for a union.
+type target func(f *File, u Union) error
+
+// targets maps target names to their implementations.
+var targets = map[string]target{
+	"avro":     genAvro,
+	"bson":     genBSON,
+	"builder":  genBuilder,
+	"equal":    genEqual,
+	"errors":   genErrors,
+	"flag":     genFlag,
+	"grpc":     genGRPC,
+	"iter":     genIter,
+	"json":     genJSON,
+	"kind":     genKind,
+	"labels":   genLabels,
+	"map":      genMap,
+	"pool":     genPool,
+	"prism":    genPrism,
+	"quick":    genQuick,
+	"registry": genRegistry,
+	"sample":   genSample,
+	"schema":   genSchema,
+	"slog":     genSlog,
+	"states":   genStates,
+	"structpb": genStructpb,
+	"value":    genValue,
+	"wire":     genWire,
+	"xml":      genXML,
+}
+
+// discriminated lists the targets encoding members by their discriminator,
+// for which the discriminators must be valid, as they must to be locked.
+var discriminated = map[string]bool{
+	"bson":     true,
+	"json":     true,
+	"map":      true,
+	"schema":   true,
+	"structpb": true,
+	"xml":      true,
+}
+
+// Targets returns the names of all available targets, sorted.
+func Targets() []string {
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Generate generates the requested targets for the unions of pkg and
+// returns the formatted source of the generated file.
+func Generate(pkg *types.Package, opts Options) ([]byte, error) {
+	unions, err := selectUnions(pkg, opts.Types)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Lock != nil || slices.ContainsFunc(opts.Targets, func(name string) bool { return discriminated[name] }) {
+		for _, u := range unions {
+			if errs := registry.CheckDiscriminators(u.Obj, u.fact.Members, opts.Lock); len(errs) > 0 {
+				return nil, fmt.Errorf("%s: %w", u.Name, errs[0])
+			}
+		}
+	}
+
+	f := newFile(pkg, unions)
+	f.enums = registry.FindEnums(pkg, opts.Files)
+	for _, name := range opts.Targets {
+		gen, ok := targets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown target %q (available: %s)", name, strings.Join(Targets(), ", "))
+		}
+		for _, u := range unions {
+			if err := gen(f, u); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", name, u.Name, err)
+			}
+		}
+	}
+
+	if opts.Lock != nil {
+		for _, u := range unions {
+			opts.Lock.Update(u.Obj, u.fact.Members)
+		}
+	}
+	return f.Bytes()
+}
+
+// selectUnions returns the unions of pkg restricted to the given names.
+func selectUnions(pkg *types.Package, names []string) ([]Union, error) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var unions []Union
+	for _, u := range registry.FindUnions(pkg) {
+		if len(names) > 0 && !wanted[u.Obj.Name()] {
+			continue
+		}
+		delete(wanted, u.Obj.Name())
+		unions = append(unions, newUnion(u))
+	}
+
+	for _, name := range names {
+		if wanted[name] {
+			return nil, fmt.Errorf("%s is not a union interface in package %s", name, pkg.Path())
+		}
+	}
+	if len(unions) == 0 {
+		return nil, fmt.Errorf("no union interfaces found in package %s", pkg.Path())
+	}
+
+	return unions, nil
+}
+
+// newUnion converts a detected union into its generator representation.
+func newUnion(u registry.Union) Union {
+	scope := u.Obj.Pkg().Scope()
+
+	union := Union{Name: u.Obj.Name(), Obj: u.Obj, fact: u.Fact}
+	for _, name := range u.Fact.Members {
+		m := Member{Name: strings.TrimPrefix(name, "*"), Pointer: strings.HasPrefix(name, "*")}
+		m.Obj, _ = scope.Lookup(m.Name).(*types.TypeName)
+		m.Discriminator = m.Name
+		if m.Obj != nil {
+			m.Discriminator, _ = registry.Discriminator(m.Obj)
+		}
+		union.Members = append(union.Members, m)
+	}
+	return union
+}
+
+// File accumulates the generated declarations and their imports.
+type File struct {
+	pkg     *types.Package
+	unions  []Union         // all unions generated for in this file
+	enums   []registry.Enum // enums of the package
+	imports map[string]bool
+	helpers map[string]bool
+	body    bytes.Buffer
+}
+
+// newFile creates an empty generated file for the given unions of pkg.
+func newFile(pkg *types.Package, unions []Union) *File {
+	return &File{
+		pkg:     pkg,
+		unions:  unions,
+		imports: make(map[string]bool),
+		helpers: make(map[string]bool),
+	}
+}
+
+// Helper emits a file-level helper declaration once, the first time it is
+// requested under the given name.
+func (f *File) Helper(name string, emit func()) {
+	if f.helpers[name] {
+		return
+	}
+	f.helpers[name] = true
+	emit()
+}
+
+// UnionOf returns the generated union that typ refers to, if any.
+func (f *File) UnionOf(typ types.Type) (Union, bool) {
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return Union{}, false
+	}
+	for _, u := range f.unions {
+		if u.Obj == named.Obj() {
+			return u, true
+		}
+	}
+	return Union{}, false
+}
+
+// Enum returns the enum of the package with the given name, if any.
+func (f *File) Enum(name string) (registry.Enum, bool) {
+	for _, e := range f.enums {
+		if e.Obj.Name() == name {
+			return e, true
+		}
+	}
+	return registry.Enum{}, false
+}
+
+// Import records that the generated code uses the package with the given path.
+func (f *File) Import(path string) {
+	f.imports[path] = true
+}
+
+// TypeString returns the Go syntax of typ in the generated file, importing
+// the packages it refers to.
+func (f *File) TypeString(typ types.Type) string {
+	return types.TypeString(typ, func(pkg *types.Package) string {
+		if pkg == f.pkg {
+			return ""
+		}
+		f.Import(pkg.Path())
+		return pkg.Name()
+	})
+}
+
+// Printf appends formatted code to the file body.
+func (f *File) Printf(format string, args ...any) {
+	fmt.Fprintf(&f.body, format, args...)
+}
+
+// Bytes returns the gofmt-formatted source of the file.
+func (f *File) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n\npackage %s\n", Header, f.pkg.Name())
+
+	if len(f.imports) > 0 {
+		paths := make([]string, 0, len(f.imports))
+		for path := range f.imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		if len(paths) == 1 {
+			fmt.Fprintf(&buf, "\nimport %q\n", paths[0])
+		} else {
+			// Standard library imports come first, as goimports groups them.
+			sort.SliceStable(paths, func(i, j int) bool { return isStdPath(paths[i]) && !isStdPath(paths[j]) })
+			buf.WriteString("\nimport (\n")
+			for i, path := range paths {
+				if i > 0 && isStdPath(paths[i-1]) && !isStdPath(path) {
+					buf.WriteString("\n")
+				}
+				fmt.Fprintf(&buf, "\t%q\n", path)
+			}
+			buf.WriteString(")\n")
+		}
+	}
+	buf.Write(f.body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+// isStdPath reports whether path is the import path of a standard library
+// package, whose first element has no dot.
+func isStdPath(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// structOf returns the struct underlying the member type, or nil if the
+// member is not a struct.
+func structOf(m Member) *types.Struct {
+	if m.Obj == nil {
+		return nil
+	}
+	st, _ := m.Obj.Type().Underlying().(*types.Struct)
+	return st
+}
+
+// lowerFirst returns s with its first letter lowercased, for unexported identifiers.
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,344 @@
+package gen_test
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"github.com/YuitoSato/gounion/internal/gen"
+
+	"golang.org/x/tools/go/packages"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// external lists the targets whose generated code imports packages that
+// are not dependencies of this module, so it can't be type-checked here.
+var external = map[string]bool{
+	"avro":     true,
+	"bson":     true,
+	"grpc":     true,
+	"structpb": true,
+}
+
+// unionsFor restricts the unions generated for by targets that don't
+// support all unions of the test package.
+var unionsFor = map[string][]string{
+	"avro": {"Shape", "StoreError"}, // Expr is recursive
+	"kind": {"Shape"},               // the only union with a kind enum
+}
+
+func TestGenerate(t *testing.T) {
+	pkg := loadTestPackage(t, "shape")
+
+	for _, target := range gen.Targets() {
+		t.Run(target, func(t *testing.T) {
+			got, err := gen.Generate(pkg.Types, gen.Options{Types: unionsFor[target], Targets: []string{target}, Files: pkg.Syntax})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", target+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("generated code differs from %s; run go test -update\n%s", golden, got)
+			}
+
+			if !external[target] {
+				checkCompiles(t, "shape", got)
+			}
+		})
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	pkg := loadTestPackage(t, "shape")
+
+	tests := []struct {
+		name string
+		opts gen.Options
+	}{
+		{name: "unknown target", opts: gen.Options{Targets: []string{"nope"}}},
+		{name: "unknown union", opts: gen.Options{Types: []string{"Circle"}, Targets: []string{"registry"}}},
+		{name: "recursive avro union", opts: gen.Options{Types: []string{"Expr"}, Targets: []string{"avro"}}},
+		{name: "union without kind enum", opts: gen.Options{Types: []string{"Expr"}, Targets: []string{"kind"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Files = pkg.Syntax
+			if _, err := gen.Generate(pkg.Types, tt.opts); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestGenerateEmptyUnion(t *testing.T) {
+	pkg := loadTestPackage(t, "empty")
+
+	for _, target := range []string{"quick", "sample"} {
+		t.Run(target, func(t *testing.T) {
+			_, err := gen.Generate(pkg.Types, gen.Options{Targets: []string{target}})
+			if err == nil || !strings.Contains(err.Error(), "union has no members") {
+				t.Errorf("Generate() error = %v, want a union without members", err)
+			}
+		})
+	}
+}
+
+func TestGenerateLabelCollision(t *testing.T) {
+	pkg := loadTestPackage(t, "labels")
+
+	tests := []struct {
+		union string
+		want  string
+	}{
+		{union: "Failure", want: `have the same label "http_error"`},
+		{union: "Outcome", want: `member None has the label "none" of nil values`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.union, func(t *testing.T) {
+			_, err := gen.Generate(pkg.Types, gen.Options{Types: []string{tt.union}, Targets: []string{"labels"}})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateErrorSentinels(t *testing.T) {
+	pkg := loadTestPackage(t, "errs")
+
+	tests := []struct {
+		union string
+		want  string
+	}{
+		{union: "QueryError", want: "sentinel ErrRejected is already declared in package errs"},
+		{union: "AuthError", want: "member ExpiredError already has an Is method"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.union, func(t *testing.T) {
+			_, err := gen.Generate(pkg.Types, gen.Options{Types: []string{tt.union}, Targets: []string{"errors"}})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateDiscriminators(t *testing.T) {
+	pkg := loadTestPackage(t, "discriminators")
+
+	const event = "github.com/YuitoSato/gounion/internal/gen/testdata/discriminators.Event"
+	lock := registry.DiscriminatorLock{event: {"-Gone": "gone"}}
+	got, err := gen.Generate(pkg.Types, gen.Options{Types: []string{"Event"}, Targets: []string{"json"}, Lock: lock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `gounionjson.Register[Event]("created", func() Event { return new(Created) })`; !strings.Contains(string(got), want) {
+		t.Errorf("generated code does not contain %s:\n%s", want, got)
+	}
+	wantLock := registry.DiscriminatorLock{event: {"-Gone": "gone", "Created": "created", "Deleted": "Deleted"}}
+	if !reflect.DeepEqual(lock, wantLock) {
+		t.Errorf("lock = %v, want %v", lock, wantLock)
+	}
+
+	tests := []struct {
+		name string
+		opts gen.Options
+		want string
+	}{
+		{
+			name: "changed",
+			opts: gen.Options{Types: []string{"Event"}, Lock: registry.DiscriminatorLock{event: {"Created": "Created"}}},
+			want: `discriminator of Created changed from "Created" to "created" since it was locked`,
+		},
+		{
+			name: "renamed",
+			opts: gen.Options{Types: []string{"Event"}, Lock: registry.DiscriminatorLock{event: {"Created": "created", "Removed": "Removed"}}},
+			want: `locked member Removed, with the discriminator "Removed", was renamed or removed; restore it or retire it as "-Removed"`,
+		},
+		{
+			name: "retired",
+			opts: gen.Options{Types: []string{"Event"}, Lock: registry.DiscriminatorLock{event: {"-Removed": "Deleted"}}},
+			want: `Deleted has the discriminator "Deleted" of the retired member Removed`,
+		},
+		{
+			name: "duplicate",
+			opts: gen.Options{Types: []string{"Command"}},
+			want: `Start has the discriminator "run" of Resume`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Targets = []string{"json"}
+			_, err := gen.Generate(pkg.Types, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateKindBijection(t *testing.T) {
+	pkg := loadTestPackage(t, "kinds")
+
+	tests := []struct {
+		union string
+		want  string
+	}{
+		{union: "Event", want: "EventKind has no constant for member Deleted; add DeletedKind"},
+		{union: "Command", want: "CommandKind constant StopKind names no member of Command"},
+		{union: "Job", want: "JobKind constants KindRun and JobKindRun both name member Run"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.union, func(t *testing.T) {
+			_, err := gen.Generate(pkg.Types, gen.Options{Types: []string{tt.union}, Targets: []string{"kind"}, Files: pkg.Syntax})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateKindAffixes(t *testing.T) {
+	pkg := loadTestPackage(t, "kinds")
+
+	got, err := gen.Generate(pkg.Types, gen.Options{Types: []string{"Device"}, Targets: []string{"kind"}, Files: pkg.Syntax})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), "case Kindle:\n\t\treturn KindleKind\n") {
+		t.Errorf("KindleKind is not the kind of Kindle:\n%s", got)
+	}
+}
+
+func TestGenerateMapRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+	pkg := loadTestPackage(t, "shape")
+	got, err := gen.Generate(pkg.Types, gen.Options{Targets: []string{"map"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nested members of recursive unions keep their discriminator, so
+	// that they are decoded as the members they were.
+	test := `package shape
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMapRoundTrip(t *testing.T) {
+	for _, want := range []Expr{
+		&Add{Left: &Lit{Value: 1}, Right: &Neg{Operand: &Lit{Value: 2}}},
+		&Neg{},
+	} {
+		m, err := ExprToMap(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ExprFromMap(m)
+		if err != nil {
+			t.Fatalf("ExprFromMap(%v): %v", m, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ExprFromMap(ExprToMap(%#v)) = %#v", want, got)
+		}
+	}
+}
+`
+	dir, err := filepath.Abs(filepath.Join("testdata", "shape"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp := t.TempDir()
+	replace := make(map[string]string)
+	for name, src := range map[string][]byte{"gounion_gen.go": got, "roundtrip_test.go": []byte(test)} {
+		replace[filepath.Join(dir, name)] = filepath.Join(tmp, name)
+		if err := os.WriteFile(filepath.Join(tmp, name), src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	overlay, err := json.Marshal(map[string]any{"Replace": replace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "overlay.json"), overlay, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("go", "test", "-overlay="+filepath.Join(tmp, "overlay.json"), "-run=TestMapRoundTrip", "./testdata/shape")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("round trip of the generated map code failed: %v\n%s", err, out)
+	}
+}
+
+// checkCompiles type-checks the package in testdata/<name> together with
+// the generated source, which is overlaid as gounion_gen.go.
+func checkCompiles(t *testing.T, name string, generated []byte) {
+	t.Helper()
+
+	dir, err := filepath.Abs(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay := map[string][]byte{filepath.Join(dir, "gounion_gen.go"): generated}
+
+	pkg := load(t, name, overlay)
+	for _, err := range pkg.Errors {
+		t.Errorf("generated code does not compile: %v", err)
+	}
+}
+
+// loadTestPackage loads and type-checks the package in testdata/<name>.
+func loadTestPackage(t *testing.T, name string) *packages.Package {
+	t.Helper()
+
+	pkg := load(t, name, nil)
+	for _, err := range pkg.Errors {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+// load loads the package in testdata/<name> with the given file overlay.
+func load(t *testing.T, name string, overlay map[string][]byte) *packages.Package {
+	t.Helper()
+
+	cfg := &packages.Config{
+		Mode:    packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax | packages.NeedTypes,
+		Overlay: overlay,
+	}
+	pkgs, err := packages.Load(cfg, "./"+filepath.ToSlash(filepath.Join("testdata", name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("loaded %d packages, want 1", len(pkgs))
+	}
+	return pkgs[0]
+}
@@ -0,0 +1,101 @@
+package gen
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// grpcCodes lists the names of the gRPC status codes.
+var grpcCodes = []string{
+	"OK", "Canceled", "Unknown", "InvalidArgument", "DeadlineExceeded",
+	"NotFound", "AlreadyExists", "PermissionDenied", "ResourceExhausted",
+	"FailedPrecondition", "Aborted", "OutOfRange", "Unimplemented",
+	"Internal", "Unavailable", "DataLoss", "Unauthenticated",
+}
+
+// genGRPC emits <Union>ToStatus(err), mapping errors of an error union (a
+// union whose members all implement error) to gRPC statuses, with one
+// case per member. A member's code is set with a grpc struct tag on one of
+// its fields, e.g. a blank field tagged grpc:"NotFound", and is Unknown by
+// default. Unions that are not error unions are skipped, unless none of
+// the unions generated for is one.
+func genGRPC(f *File, u Union) error {
+	if !isErrorUnion(u) {
+		if slices.ContainsFunc(f.unions, isErrorUnion) {
+			return nil
+		}
+		return fmt.Errorf("not all members implement error")
+	}
+
+	codes := make([]string, len(u.Members))
+	for i, m := range u.Members {
+		code, err := grpcCode(m)
+		if err != nil {
+			return err
+		}
+		codes[i] = code
+	}
+
+	f.Import("errors")
+	f.Import("google.golang.org/grpc/codes")
+	f.Import("google.golang.org/grpc/status")
+
+	fn := u.Name + "ToStatus"
+	f.Printf("\n// %s returns the gRPC status of err, by the %s member\n", fn, u.Name)
+	f.Printf("// found in its chain, with the message of err. Errors without a member\n")
+	f.Printf("// map to codes.Unknown, and a nil err to a nil status.\n")
+	f.Printf("func %s(err error) *status.Status {\n", fn)
+	f.Printf("\tif err == nil {\n\t\treturn nil\n\t}\n")
+	f.Printf("\tcode := codes.Unknown\n")
+	f.Printf("\tvar u %s\n", u.Name)
+	f.Printf("\tif errors.As(err, &u) {\n")
+	f.Printf("\t\tswitch u.(type) {\n")
+	for i, m := range u.Members {
+		f.Printf("\t\tcase %s:\n\t\t\tcode = codes.%s\n", m.TypeExpr(), codes[i])
+	}
+	f.Printf("\t\t}\n\t}\n")
+	f.Printf("\treturn status.New(code, err.Error())\n}\n")
+
+	return nil
+}
+
+// isErrorUnion reports whether every member of u implements error.
+func isErrorUnion(u Union) bool {
+	errorType := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	for _, m := range u.Members {
+		if m.Obj == nil {
+			return false
+		}
+		var typ types.Type = m.Obj.Type()
+		if m.Pointer {
+			typ = types.NewPointer(typ)
+		}
+		if !types.Implements(typ, errorType) {
+			return false
+		}
+	}
+	return len(u.Members) > 0
+}
+
+// grpcCode returns the code set by the grpc struct tag of a field of the
+// member, or Unknown.
+func grpcCode(m Member) (string, error) {
+	st := structOf(m)
+	if st == nil {
+		return "Unknown", nil
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		code, ok := reflect.StructTag(st.Tag(i)).Lookup("grpc")
+		if !ok {
+			continue
+		}
+		if !slices.Contains(grpcCodes, code) {
+			return "", fmt.Errorf("%s: unknown gRPC code %q (want one of %s)", m.Name, code, strings.Join(grpcCodes, ", "))
+		}
+		return code, nil
+	}
+	return "Unknown", nil
+}
@@ -0,0 +1,41 @@
+package gen
+
+// genIter emits <Union>Samples, an iter.Seq yielding one canonical sample
+// value per member (a new zero value), and <Union>MemberKinds, an iter.Seq
+// of the members' names and types, in declaration order, for table tests,
+// admin tooling and documentation generators enumerating the variants at
+// run time.
+func genIter(f *File, u Union) error {
+	f.Import("iter")
+	f.Import("reflect")
+
+	fn := u.Name + "Samples"
+	f.Printf("\n// %s returns an iterator over one sample value of each %s member,\n", fn, u.Name)
+	f.Printf("// a new zero value, in declaration order.\n")
+	f.Printf("func %s() iter.Seq[%s] {\n", fn, u.Name)
+	f.Printf("\treturn func(yield func(%s) bool) {\n", u.Name)
+	for _, m := range u.Members {
+		f.Printf("\t\tif !yield(%s) {\n\t\t\treturn\n\t\t}\n", newMemberExpr(m))
+	}
+	f.Printf("\t}\n}\n")
+
+	kind := u.Name + "MemberKind"
+	f.Printf("\n// %s describes a member of the %s union.\n", kind, u.Name)
+	f.Printf("type %s struct {\n", kind)
+	f.Printf("\tName   string       // name of the member type\n")
+	f.Printf("\tType   reflect.Type // type implementing %s\n", u.Name)
+	f.Printf("\tSample func() %s // returns a new zero value of the member\n", u.Name)
+	f.Printf("}\n")
+
+	f.Printf("\n// %ss returns an iterator over the members of the %s union, in\n", kind, u.Name)
+	f.Printf("// declaration order.\n")
+	f.Printf("func %ss() iter.Seq[%s] {\n", kind, kind)
+	f.Printf("\treturn func(yield func(%s) bool) {\n", kind)
+	for _, m := range u.Members {
+		f.Printf("\t\tif !yield(%s{Name: %q, Type: %s, Sample: func() %s { return %s }}) {\n\t\t\treturn\n\t\t}\n",
+			kind, m.Name, reflectTypeExpr(m), u.Name, newMemberExpr(m))
+	}
+	f.Printf("\t}\n}\n")
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package gen
+
+// genJSON emits an init function registering every member of the union
+// with the gounionjson runtime, keyed by its discriminator: the member
+// name, unless set by a discriminator struct tag.
+func genJSON(f *File, u Union) error {
+	f.Import("github.com/YuitoSato/gounion/gounionjson")
+
+	f.Printf("\n// init registers the %s members for JSON decoding by discriminator.\n", u.Name)
+	f.Printf("func init() {\n")
+	for _, m := range u.Members {
+		f.Printf("\tgounionjson.Register[%s](%q, func() %s { return %s })\n", u.Name, m.Discriminator, u.Name, newMemberExpr(m))
+	}
+	f.Printf("}\n")
+
+	return nil
+}
+
+// newMemberExpr returns an expression creating a zero member value: a
+// pointer for pointer members, and a value otherwise, which gounionjson
+// decodes through a pointer.
+func newMemberExpr(m Member) string {
+	if m.Pointer {
+		return "new(" + m.Name + ")"
+	}
+	return m.Name + "{}"
+}
@@ -0,0 +1,76 @@
+package gen
+
+import (
+	"fmt"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+)
+
+// genKind emits <Union>FromKind and <Union>KindOf, converting between the
+// members of a union and the constants of its kind enum: the type
+// <Union>Kind declared with //gounion:enum. The constant of a member M is
+// named MKind, KindM or <Union>KindM, and the constants and members must be
+// in bijection. Both functions switch exhaustively, so that gounion reports
+// them when a member or a constant is added without regenerating.
+func genKind(f *File, u Union) error {
+	enum, ok := f.Enum(u.Name + "Kind")
+	if !ok {
+		return fmt.Errorf("no //gounion:enum type %sKind", u.Name)
+	}
+	kinds, err := pairKinds(u, enum)
+	if err != nil {
+		return err
+	}
+	kind := enum.Obj.Name()
+
+	f.Import("fmt")
+	f.Printf("\n// %sFromKind returns a new zero member of %s of the given kind.\n", u.Name, u.Name)
+	f.Printf("func %sFromKind(k %s) (%s, error) {\n", u.Name, kind, u.Name)
+	f.Printf("\tswitch k {\n")
+	for i, m := range u.Members {
+		f.Printf("\tcase %s:\n\t\treturn %s, nil\n", kinds[i], newMemberExpr(m))
+	}
+	f.Printf("\t}\n")
+	f.Printf("\treturn nil, fmt.Errorf(\"unknown %s %%v\", k)\n}\n", kind)
+
+	f.Printf("\n// %sKindOf returns the kind of the member held by u.\n", u.Name)
+	f.Printf("// It panics if u is nil.\n")
+	f.Printf("func %sKindOf(u %s) %s {\n", u.Name, u.Name, kind)
+	f.Printf("\tswitch u.(type) {\n")
+	for i, m := range u.Members {
+		f.Printf("\tcase %s:\n\t\treturn %s\n", m.TypeExpr(), kinds[i])
+	}
+	f.Printf("\t}\n")
+	f.Printf("\tpanic(fmt.Sprintf(\"unhandled %s member %%T\", u))\n}\n", u.Name)
+
+	return nil
+}
+
+// pairKinds returns the constant of enum for each member of u, or an error
+// if they are not in bijection.
+func pairKinds(u Union, enum registry.Enum) ([]string, error) {
+	byName := make(map[string]int, len(u.Members)) // member name -> index
+	names := make([]string, len(u.Members))
+	for i, m := range u.Members {
+		byName[m.Name] = i
+		names[i] = m.Name
+	}
+
+	kinds := make([]string, len(u.Members))
+	for _, c := range enum.Fact.Constants {
+		i, ok := byName[registry.KindName(c, enum.Obj.Name(), names)]
+		if !ok {
+			return nil, fmt.Errorf("%s constant %s names no member of %s", enum.Obj.Name(), c, u.Name)
+		}
+		if kinds[i] != "" {
+			return nil, fmt.Errorf("%s constants %s and %s both name member %s", enum.Obj.Name(), kinds[i], c, u.Members[i].Name)
+		}
+		kinds[i] = c
+	}
+	for i, m := range u.Members {
+		if kinds[i] == "" {
+			return nil, fmt.Errorf("%s has no constant for member %s; add %sKind", enum.Obj.Name(), m.Name, m.Name)
+		}
+	}
+	return kinds, nil
+}
@@ -0,0 +1,65 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// noneLabel is the metric label value of nil unions.
+const noneLabel = "none"
+
+// genLabels emits <Union>KindLabel, returning a stable, low-cardinality
+// metric label value per member: its name in snake case, e.g.
+// "not_found_error". <Union>KindLabels lists them all, e.g. to initialize
+// metric series. Members whose labels collide, with each other or with
+// the label "none" of nil values, are an error.
+func genLabels(f *File, u Union) error {
+	labels := make([]string, len(u.Members))
+	seen := make(map[string]string)
+	for i, m := range u.Members {
+		label := snakeCase(m.Name)
+		if label == noneLabel {
+			return fmt.Errorf("member %s has the label %q of nil values", m.Name, label)
+		}
+		if other, ok := seen[label]; ok {
+			return fmt.Errorf("members %s and %s have the same label %q", other, m.Name, label)
+		}
+		seen[label] = m.Name
+		labels[i] = label
+	}
+
+	fn := u.Name + "KindLabel"
+	f.Printf("\n// %s returns the metric label value of the member held by u,\n", fn)
+	f.Printf("// or %q if u is nil.\n", noneLabel)
+	f.Printf("func %s(u %s) string {\n", fn, u.Name)
+	f.Printf("\tswitch u.(type) {\n")
+	for i, m := range u.Members {
+		f.Printf("\tcase %s:\n\t\treturn %q\n", m.TypeExpr(), labels[i])
+	}
+	f.Printf("\t}\n\treturn %q\n}\n", noneLabel)
+
+	f.Printf("\n// %ss returns the metric label values of all %s members.\n", fn, u.Name)
+	f.Printf("func %ss() []string {\n", fn)
+	f.Printf("\treturn []string{%s}\n}\n", quoteList(labels))
+
+	return nil
+}
+
+// snakeCase converts a Go identifier to snake case, keeping acronyms
+// together, e.g. "HTTPError" to "http_error".
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prev != '_' && (unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package gen
+
+// genPool emits sync.Pool backed constructors for the pointer members of
+// the union: NewPooled<Member>() returns a zero member from a pool, and
+// Release<Member>(m) resets it and puts it back. Release<Union>(u)
+// releases whichever pooled member u holds. Value members are not pooled.
+func genPool(f *File, u Union) error {
+	var pooled []Member
+	for _, m := range u.Members {
+		if m.Pointer {
+			pooled = append(pooled, m)
+		}
+	}
+	if len(pooled) == 0 {
+		return nil
+	}
+	f.Import("sync")
+
+	for _, m := range pooled {
+		// Members shared by several unions are pooled once.
+		f.Helper("pool"+m.Name, func() {
+			pool := lowerFirst(m.Name) + "Pool"
+			f.Printf("\n// %s pools released %s values.\n", pool, m.TypeExpr())
+			f.Printf("var %s = sync.Pool{New: func() any { return new(%s) }}\n", pool, m.Name)
+
+			f.Printf("\n// NewPooled%s returns a zero %s from a pool.\n", m.Name, m.TypeExpr())
+			f.Printf("// Pass it to Release%s once it is no longer used.\n", m.Name)
+			f.Printf("func NewPooled%s() %s {\n", m.Name, m.TypeExpr())
+			f.Printf("\treturn %s.Get().(%s)\n}\n", pool, m.TypeExpr())
+
+			f.Printf("\n// Release%s resets m and returns it to the pool of NewPooled%s.\n", m.Name, m.Name)
+			f.Printf("// m must not be used afterwards.\n")
+			f.Printf("func Release%s(m %s) {\n", m.Name, m.TypeExpr())
+			if structOf(m) != nil {
+				f.Printf("\t*m = %s{}\n", m.Name)
+			} else {
+				f.Printf("\tvar zero %s\n\t*m = zero\n", m.Name)
+			}
+			f.Printf("\t%s.Put(m)\n}\n", pool)
+		})
+	}
+
+	f.Printf("\n// Release%s releases the member held by u to its pool, if it is pooled.\n", u.Name)
+	f.Printf("// u must not be used afterwards.\n")
+	f.Printf("func Release%s(u %s) {\n", u.Name, u.Name)
+	f.Printf("\tswitch m := u.(type) {\n")
+	for _, m := range pooled {
+		f.Printf("\tcase %s:\n\t\tif m != nil {\n\t\t\tRelease%s(m)\n\t\t}\n", m.TypeExpr(), m.Name)
+	}
+	f.Printf("\t}\n}\n")
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package gen
+
+// genPrism emits a prism type per member, <Union><Member>Prism, focusing on
+// that variant of the union: Get extracts the member if the union holds it,
+// ReverseGet converts the member back into the union, and Modify applies a
+// function to the union if it holds the member.
+func genPrism(f *File, u Union) error {
+	for _, m := range u.Members {
+		prism := u.Name + m.Name + "Prism"
+		typ := m.TypeExpr()
+
+		f.Printf("\n// %s focuses on the %s member of the %s union.\n", prism, typ, u.Name)
+		f.Printf("type %s struct{}\n", prism)
+
+		f.Printf("\n// Get returns the member held by u, and whether u holds a %s.\n", typ)
+		f.Printf("func (%s) Get(u %s) (%s, bool) {\n", prism, u.Name, typ)
+		f.Printf("\tm, ok := u.(%s)\n\treturn m, ok\n}\n", typ)
+
+		f.Printf("\n// ReverseGet converts m into the %s union.\n", u.Name)
+		f.Printf("func (%s) ReverseGet(m %s) %s {\n", prism, typ, u.Name)
+		f.Printf("\treturn m\n}\n")
+
+		f.Printf("\n// Modify returns fn applied to the member held by u if u holds a %s,\n", typ)
+		f.Printf("// and u unchanged otherwise.\n")
+		f.Printf("func (%s) Modify(u %s, fn func(%s) %s) %s {\n", prism, u.Name, typ, typ, u.Name)
+		f.Printf("\tif m, ok := u.(%s); ok {\n\t\treturn fn(m)\n\t}\n\treturn u\n}\n", typ)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package gen
+
+import "errors"
+
+// genQuick emits testing/quick Generator implementations: a Generate
+// method per member and a Quick<Union> wrapper type generating random
+// union values. Fields of generated union types are filled recursively
+// with half the size, which bounds the depth of generated values. Unions
+// without members are rejected, as they have no values to generate.
+func genQuick(f *File, u Union) error {
+	if len(u.Members) == 0 {
+		return errors.New("union has no members to generate")
+	}
+	f.Import("math/rand")
+	f.Import("reflect")
+	f.Import("testing/quick")
+
+	f.Helper("quickValue", func() {
+		f.Printf("\n// gounionQuickValue sets *p to a random value generated by testing/quick,\n")
+		f.Printf("// leaving it unchanged if testing/quick can't generate values of its type.\n")
+		f.Printf("func gounionQuickValue[T any](p *T, r *rand.Rand) {\n")
+		f.Printf("\tif v, ok := quick.Value(reflect.TypeFor[T](), r); ok {\n\t\t*p = v.Interface().(T)\n\t}\n}\n")
+	})
+
+	// Members without fields of generated union types come first, so that
+	// they alone can be chosen once the size budget is exhausted.
+	var leaves, recursive []Member
+	for _, m := range u.Members {
+		if isRecursiveMember(f, m) {
+			recursive = append(recursive, m)
+		} else {
+			leaves = append(leaves, m)
+		}
+	}
+	ordered := append(append([]Member{}, leaves...), recursive...)
+
+	gen := "generate" + u.Name
+	f.Printf("\n// %s returns a random %s. Once size is exhausted, only members\n", gen, u.Name)
+	f.Printf("// without fields of union types are chosen, which bounds recursion.\n")
+	f.Printf("func %s(r *rand.Rand, size int) %s {\n", gen, u.Name)
+	f.Printf("\tn := %d\n", len(ordered))
+	if len(leaves) > 0 && len(recursive) > 0 {
+		f.Printf("\tif size <= 0 {\n\t\tn = %d\n\t}\n", len(leaves))
+	}
+	f.Printf("\tswitch r.Intn(n) {\n")
+	for i, m := range ordered {
+		f.Printf("\tcase %d:\n\t\treturn generate%s%s(r, size)\n", i, u.Name, m.Name)
+	}
+	f.Printf("\t}\n\tpanic(\"unreachable\")\n}\n")
+
+	wrapper := "Quick" + u.Name
+	f.Printf("\n// %s wraps the %s union so that testing/quick can generate random values of it.\n", wrapper, u.Name)
+	f.Printf("type %s struct {\n\t%s\n}\n", wrapper, u.Name)
+	f.Printf("\n// Generate implements quick.Generator.\n")
+	f.Printf("func (%s) Generate(r *rand.Rand, size int) reflect.Value {\n", wrapper)
+	f.Printf("\treturn reflect.ValueOf(%s{%s(r, size)})\n}\n", wrapper, gen)
+
+	for _, m := range u.Members {
+		genQuickMember(f, u, m)
+	}
+
+	return nil
+}
+
+// genQuickMember emits the random constructor and Generate method of a member.
+func genQuickMember(f *File, u Union, m Member) {
+	gen := "generate" + u.Name + m.Name
+	f.Printf("\n// %s returns a random %s.\n", gen, m.TypeExpr())
+	f.Printf("func %s(r *rand.Rand, size int) %s {\n", gen, m.TypeExpr())
+	f.Printf("\tvar v %s\n", m.Name)
+	if st := structOf(m); st != nil {
+		for i := 0; i < st.NumFields(); i++ {
+			field := st.Field(i)
+			if field.Name() == "_" {
+				continue
+			}
+			if fu, ok := f.UnionOf(field.Type()); ok {
+				f.Printf("\tif size > 0 {\n\t\tv.%s = generate%s(r, size/2)\n\t}\n", field.Name(), fu.Name)
+			} else {
+				f.Printf("\tgounionQuickValue(&v.%s, r)\n", field.Name())
+			}
+		}
+	} else {
+		f.Printf("\tgounionQuickValue(&v, r)\n")
+	}
+	if m.Pointer {
+		f.Printf("\treturn &v\n}\n")
+	} else {
+		f.Printf("\treturn v\n}\n")
+	}
+
+	f.Printf("\n// Generate implements quick.Generator.\n")
+	f.Printf("func (%s) Generate(r *rand.Rand, size int) reflect.Value {\n", m.TypeExpr())
+	f.Printf("\treturn reflect.ValueOf(%s(r, size))\n}\n", gen)
+}
+
+// isRecursiveMember reports whether the member has a field of a generated union type.
+func isRecursiveMember(f *File, m Member) bool {
+	st := structOf(m)
+	if st == nil {
+		return false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if _, ok := f.UnionOf(st.Field(i).Type()); ok {
+			return true
+		}
+	}
+	return false
+}
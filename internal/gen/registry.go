@@ -0,0 +1,43 @@
+package gen
+
+// genRegistry emits a runtime member registry for the union:
+// <Union>Members() returning the member types, and lookups between
+// member names and types.
+func genRegistry(f *File, u Union) error {
+	f.Import("reflect")
+
+	f.Printf("\n// %sMembers returns the member types of the %s union.\n", u.Name, u.Name)
+	f.Printf("func %sMembers() []reflect.Type {\n", u.Name)
+	f.Printf("\treturn []reflect.Type{\n")
+	for _, m := range u.Members {
+		f.Printf("\t\t%s,\n", reflectTypeExpr(m))
+	}
+	f.Printf("\t}\n}\n")
+
+	table := lowerFirst(u.Name) + "MemberTypes"
+	f.Printf("\n// %s maps the member names of the %s union to their types.\n", table, u.Name)
+	f.Printf("var %s = map[string]reflect.Type{\n", table)
+	for _, m := range u.Members {
+		f.Printf("\t%q: %s,\n", m.Name, reflectTypeExpr(m))
+	}
+	f.Printf("}\n")
+
+	f.Printf("\n// %sMemberType returns the type of the %s member with the given name.\n", u.Name, u.Name)
+	f.Printf("func %sMemberType(name string) (reflect.Type, bool) {\n", u.Name)
+	f.Printf("\tt, ok := %s[name]\n\treturn t, ok\n}\n", table)
+
+	f.Printf("\n// %sMemberName returns the name of the %s member with the given type.\n", u.Name, u.Name)
+	f.Printf("func %sMemberName(t reflect.Type) (string, bool) {\n", u.Name)
+	f.Printf("\tfor name, mt := range %s {\n\t\tif mt == t {\n\t\t\treturn name, true\n\t\t}\n\t}\n", table)
+	f.Printf("\treturn \"\", false\n}\n")
+
+	return nil
+}
+
+// reflectTypeExpr returns an expression evaluating to the reflect.Type of the member.
+func reflectTypeExpr(m Member) string {
+	if m.Pointer {
+		return "reflect.TypeOf((*" + m.Name + ")(nil))"
+	}
+	return "reflect.TypeOf((*" + m.Name + ")(nil)).Elem()"
+}
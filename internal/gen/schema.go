@@ -0,0 +1,207 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+// genSchema emits <Union>JSONSchema, a constant holding a JSON Schema
+// (draft 2020-12) of the union as encoded by gounionjson: a oneOf of the
+// member objects, each with a const "type" discriminator. Fields follow
+// the encoding/json rules for names, omitempty and embedded structs.
+func genSchema(f *File, u Union) error {
+	root := &jsonSchema{
+		Schema: "https://json-schema.org/draft/2020-12/schema",
+		Title:  u.Name,
+	}
+	for _, m := range u.Members {
+		member := &jsonSchema{Type: "object", Title: m.Name}
+		member.Properties = append(member.Properties, schemaProperty{
+			Name:   "type",
+			Schema: &jsonSchema{Const: m.Discriminator},
+		})
+		member.Required = append(member.Required, "type")
+		if st := structOf(m); st != nil {
+			addStructProperties(member, st, u, make(map[*types.TypeName]bool))
+		}
+		root.OneOf = append(root.OneOf, member)
+	}
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return err
+	}
+	if bytes.IndexByte(data, '`') >= 0 {
+		return fmt.Errorf("schema contains a backquote")
+	}
+
+	name := u.Name + "JSONSchema"
+	f.Printf("\n// %s is the JSON Schema (draft 2020-12) of %s values encoded by\n", name, u.Name)
+	f.Printf("// gounionjson, with the default \"type\" discriminator field.\n")
+	f.Printf("const %s = `%s`\n", name, data)
+
+	return nil
+}
+
+// jsonSchema is the subset of JSON Schema generated, in output order.
+type jsonSchema struct {
+	Schema               string           `json:"$schema,omitempty"`
+	Ref                  string           `json:"$ref,omitempty"`
+	Title                string           `json:"title,omitempty"`
+	Type                 string           `json:"type,omitempty"`
+	Format               string           `json:"format,omitempty"`
+	ContentEncoding      string           `json:"contentEncoding,omitempty"`
+	Const                any              `json:"const,omitempty"`
+	Items                *jsonSchema      `json:"items,omitempty"`
+	AdditionalProperties *jsonSchema      `json:"additionalProperties,omitempty"`
+	Properties           schemaProperties `json:"properties,omitempty"`
+	Required             []string         `json:"required,omitempty"`
+	OneOf                []*jsonSchema    `json:"oneOf,omitempty"`
+}
+
+// schemaProperty is a property of an object schema.
+type schemaProperty struct {
+	Name   string
+	Schema *jsonSchema
+}
+
+// schemaProperties are the properties of an object schema, in field order.
+type schemaProperties []schemaProperty
+
+// MarshalJSON implements json.Marshaler, keeping the properties in order.
+func (p schemaProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, prop := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(prop.Name)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := json.Marshal(prop.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(schema)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// addStructProperties adds the properties encoding/json encodes for the
+// fields of st to the object schema s. visited holds the named structs
+// being described, whose recursive occurrences are not described again.
+func addStructProperties(s *jsonSchema, st *types.Struct, u Union, visited map[*types.TypeName]bool) {
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		name, opts, _ := strings.Cut(reflect.StructTag(st.Tag(i)).Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		if field.Embedded() && name == "" {
+			typ := field.Type()
+			if ptr, ok := typ.(*types.Pointer); ok {
+				typ = ptr.Elem()
+			}
+			if embedded, ok := typ.Underlying().(*types.Struct); ok {
+				addStructProperties(s, embedded, u, visited)
+				continue
+			}
+		}
+		if !field.Exported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name()
+		}
+
+		s.Properties = append(s.Properties, schemaProperty{Name: name, Schema: schemaOf(field.Type(), u, visited)})
+		if !strings.Contains(","+opts+",", ",omitempty,") && !strings.Contains(","+opts+",", ",omitzero,") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// schemaOf returns the schema of values of typ encoded by encoding/json.
+// gounionjson.Field fields of the union refer to the root schema; types
+// whose encoding can't be derived, such as interfaces (including unions
+// held directly, which encoding/json encodes without discriminator) and
+// types with their own MarshalJSON, accept any value.
+func schemaOf(typ types.Type, u Union, visited map[*types.TypeName]bool) *jsonSchema {
+	typ = types.Unalias(typ)
+	if named, ok := typ.(*types.Named); ok {
+		obj := named.Obj()
+		switch {
+		case isUnionField(named, u):
+			return &jsonSchema{Ref: "#"}
+		case obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time":
+			return &jsonSchema{Type: "string", Format: "date-time"}
+		}
+		if m, _, _ := types.LookupFieldOrMethod(named, true, obj.Pkg(), "MarshalJSON"); m != nil {
+			return &jsonSchema{}
+		}
+	}
+
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case t.Info()&types.IsBoolean != 0:
+			return &jsonSchema{Type: "boolean"}
+		case t.Info()&types.IsInteger != 0:
+			return &jsonSchema{Type: "integer"}
+		case t.Info()&types.IsFloat != 0:
+			return &jsonSchema{Type: "number"}
+		case t.Info()&types.IsString != 0:
+			return &jsonSchema{Type: "string"}
+		}
+	case *types.Pointer:
+		return schemaOf(t.Elem(), u, visited)
+	case *types.Slice:
+		if b, ok := t.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return &jsonSchema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &jsonSchema{Type: "array", Items: schemaOf(t.Elem(), u, visited)}
+	case *types.Array:
+		return &jsonSchema{Type: "array", Items: schemaOf(t.Elem(), u, visited)}
+	case *types.Map:
+		if b, ok := t.Key().Underlying().(*types.Basic); ok && b.Info()&types.IsString != 0 {
+			return &jsonSchema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), u, visited)}
+		}
+	case *types.Struct:
+		named, _ := typ.(*types.Named)
+		if named != nil {
+			if visited[named.Obj()] {
+				return &jsonSchema{Type: "object"}
+			}
+			visited[named.Obj()] = true
+			defer delete(visited, named.Obj())
+		}
+		s := &jsonSchema{Type: "object"}
+		addStructProperties(s, t, u, visited)
+		return s
+	}
+	return &jsonSchema{}
+}
+
+// isUnionField reports whether named is gounionjson.Field of the union u.
+func isUnionField(named *types.Named, u Union) bool {
+	obj := named.Origin().Obj()
+	if obj.Pkg() == nil || obj.Pkg().Path() != "github.com/YuitoSato/gounion/gounionjson" || obj.Name() != "Field" {
+		return false
+	}
+	args := named.TypeArgs()
+	if args.Len() != 1 {
+		return false
+	}
+	arg, ok := types.Unalias(args.At(0)).(*types.Named)
+	return ok && arg.Obj() == u.Obj
+}
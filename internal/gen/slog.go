@@ -0,0 +1,48 @@
+package gen
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// genSlog emits a slog.LogValuer implementation per member, logging the
+// member as a group of its kind (the member name) and its exported fields,
+// so that log output is uniform across the members of the union.
+func genSlog(f *File, u Union) error {
+	f.Import("log/slog")
+
+	for _, m := range u.Members {
+		if m.Obj == nil {
+			continue
+		}
+		if obj, _, _ := types.LookupFieldOrMethod(m.Obj.Type(), true, m.Obj.Pkg(), "LogValue"); obj != nil {
+			return fmt.Errorf("%s already has a LogValue field or method", m.Name)
+		}
+
+		// Members shared by several unions get one method.
+		f.Helper("slog"+m.Name, func() {
+			f.Printf("\n// LogValue implements slog.LogValuer, logging the kind of the member\n")
+			f.Printf("// and its exported fields.\n")
+			f.Printf("func (m %s) LogValue() slog.Value {\n", m.TypeExpr())
+			f.Printf("\tkind := slog.String(\"kind\", %q)\n", m.Name)
+			var fields []string
+			if st := structOf(m); st != nil {
+				for i := 0; i < st.NumFields(); i++ {
+					if field := st.Field(i); field.Exported() {
+						fields = append(fields, field.Name())
+					}
+				}
+			}
+			if len(fields) > 0 && m.Pointer {
+				f.Printf("\tif m == nil {\n\t\treturn slog.GroupValue(kind)\n\t}\n")
+			}
+			f.Printf("\treturn slog.GroupValue(\n\t\tkind,\n")
+			for _, field := range fields {
+				f.Printf("\t\tslog.Any(%q, m.%s),\n", field, field)
+			}
+			f.Printf("\t)\n}\n")
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package gen
+
+// genStates emits <Union>Transitions, a transition table for unions whose
+// members are the states of a state machine, mapping each state to the
+// states it may transition to. Its Transition method checks a transition
+// by member type. Table literals marked with //gounion:all-members are
+// checked by gounion to have a key for every state.
+func genStates(f *File, u Union) error {
+	f.Import("fmt")
+
+	table := u.Name + "Transitions"
+	index := lowerFirst(u.Name) + "StateIndex"
+
+	f.Printf("\n// %s is a transition table of %s states: it maps\n", table, u.Name)
+	f.Printf("// each state, by its member type, to the states it may transition to.\n")
+	f.Printf("// Mark table literals with //gounion:all-members so that states missing\n")
+	f.Printf("// from them are reported:\n")
+	f.Printf("//\n//\t//gounion:all-members\n//\tvar transitions = %s{\n", table)
+	for i, m := range u.Members {
+		if i == 2 {
+			f.Printf("//\t\t...\n")
+			break
+		}
+		f.Printf("//\t\t%s: {...},\n", newMemberLit(m))
+	}
+	f.Printf("//\t}\n")
+	f.Printf("type %s map[%s][]%s\n", table, u.Name, u.Name)
+
+	f.Printf("\n// Transition returns an error unless t allows the transition from the\n")
+	f.Printf("// state of from to the state of to.\n")
+	f.Printf("func (t %s) Transition(from, to %s) error {\n", table, u.Name)
+	f.Printf("\tfromIndex, toIndex := %s(from), %s(to)\n", index, index)
+	f.Printf("\tif fromIndex >= 0 && toIndex >= 0 {\n")
+	f.Printf("\t\tfor state, next := range t {\n")
+	f.Printf("\t\t\tif %s(state) != fromIndex {\n\t\t\t\tcontinue\n\t\t\t}\n", index)
+	f.Printf("\t\t\tfor _, s := range next {\n")
+	f.Printf("\t\t\t\tif %s(s) == toIndex {\n\t\t\t\t\treturn nil\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n", index)
+	f.Printf("\treturn fmt.Errorf(\"transition from %%T to %%T is not allowed by the %s table\", from, to)\n}\n", u.Name)
+
+	f.Printf("\n// %s returns the index of the member held by s, or -1.\n", index)
+	f.Printf("func %s(s %s) int {\n", index, u.Name)
+	f.Printf("\tswitch s.(type) {\n")
+	for i, m := range u.Members {
+		f.Printf("\tcase %s:\n\t\treturn %d\n", m.TypeExpr(), i)
+	}
+	f.Printf("\t}\n\treturn -1\n}\n")
+
+	return nil
+}
+
+// newMemberLit returns a composite literal of a zero member, e.g. "&Circle{}".
+func newMemberLit(m Member) string {
+	if m.Pointer {
+		return "&" + m.Name + "{}"
+	}
+	return m.Name + "{}"
+}
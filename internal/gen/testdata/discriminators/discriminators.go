@@ -0,0 +1,34 @@
+package discriminators
+
+// Event is a union with a member renamed in encoded values.
+type Event interface {
+	isEvent()
+}
+
+type Created struct {
+	_  struct{} `discriminator:"created"`
+	ID string
+}
+
+type Deleted struct {
+	ID string
+}
+
+func (*Created) isEvent() {}
+func (*Deleted) isEvent() {}
+
+// Command is a union whose members share a discriminator.
+type Command interface {
+	isCommand()
+}
+
+type Start struct {
+	_ struct{} `discriminator:"run"`
+}
+
+type Resume struct {
+	_ struct{} `discriminator:"run"`
+}
+
+func (Start) isCommand()  {}
+func (Resume) isCommand() {}
@@ -0,0 +1,6 @@
+package empty
+
+// Nothing is a union without members.
+type Nothing interface {
+	isNothing()
+}
@@ -0,0 +1,37 @@
+package errs
+
+import "errors"
+
+// QueryError is a union whose sentinel ErrRejected is declared already.
+type QueryError interface {
+	error
+	isQueryError()
+}
+
+type RejectedError struct{}
+type SyntaxError struct{}
+
+func (*RejectedError) Error() string { return "rejected" }
+func (*SyntaxError) Error() string   { return "syntax" }
+
+func (*RejectedError) isQueryError() {}
+func (*SyntaxError) isQueryError()   {}
+
+var ErrRejected = errors.New("rejected")
+
+// AuthError is a union with a member matching sentinels already.
+type AuthError interface {
+	error
+	isAuthError()
+}
+
+type ExpiredError struct{}
+type DeniedError struct{}
+
+func (ExpiredError) Error() string { return "expired" }
+func (DeniedError) Error() string  { return "denied" }
+
+func (ExpiredError) Is(target error) bool { return target == ErrRejected }
+
+func (ExpiredError) isAuthError() {}
+func (DeniedError) isAuthError()  {}
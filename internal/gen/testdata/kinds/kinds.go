@@ -0,0 +1,72 @@
+package kinds
+
+// Event has a member without a kind.
+type Event interface {
+	isEvent()
+}
+
+type Created struct{}
+type Deleted struct{}
+
+func (Created) isEvent() {}
+func (Deleted) isEvent() {}
+
+type EventKind int
+
+//gounion:enum
+const (
+	CreatedKind EventKind = iota
+)
+
+// Command has a kind naming no member.
+type Command interface {
+	isCommand()
+}
+
+type Start struct{}
+
+func (Start) isCommand() {}
+
+type CommandKind int
+
+//gounion:enum
+const (
+	StartKind CommandKind = iota
+	StopKind
+)
+
+// Job has two kinds naming the same member.
+type Job interface {
+	isJob()
+}
+
+type Run struct{}
+
+func (Run) isJob() {}
+
+type JobKind int
+
+//gounion:enum
+const (
+	KindRun JobKind = iota
+	JobKindRun
+)
+
+// Device has a member whose name starts with "Kind".
+type Device interface {
+	isDevice()
+}
+
+type Kindle struct{}
+type Phone struct{}
+
+func (Kindle) isDevice() {}
+func (Phone) isDevice()  {}
+
+type DeviceKind int
+
+//gounion:enum
+const (
+	KindleKind DeviceKind = iota
+	PhoneKind
+)
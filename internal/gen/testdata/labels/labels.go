@@ -0,0 +1,23 @@
+package labels
+
+// Failure has members whose metric labels collide.
+type Failure interface {
+	isFailure()
+}
+
+type HTTPError struct{}
+type HttpError struct{}
+
+func (HTTPError) isFailure() {}
+func (HttpError) isFailure() {}
+
+// Outcome has a member whose metric label is that of nil values.
+type Outcome interface {
+	isOutcome()
+}
+
+type None struct{}
+type Some struct{}
+
+func (None) isOutcome() {}
+func (Some) isOutcome() {}
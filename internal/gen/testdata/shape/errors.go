@@ -0,0 +1,27 @@
+package shape
+
+// StoreError is a union of error variants.
+type StoreError interface {
+	error
+	isStoreError()
+}
+
+type NotFoundError struct {
+	Key string
+	_   struct{} `grpc:"NotFound"`
+}
+
+type ConflictError struct {
+	Key string
+	_   struct{} `grpc:"AlreadyExists"`
+}
+
+type TimeoutError struct{}
+
+func (*NotFoundError) Error() string { return "not found" }
+func (*ConflictError) Error() string { return "conflict" }
+func (*TimeoutError) Error() string  { return "timeout" }
+
+func (*NotFoundError) isStoreError() {}
+func (*ConflictError) isStoreError() {}
+func (*TimeoutError) isStoreError()  {}
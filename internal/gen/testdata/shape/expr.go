@@ -0,0 +1,23 @@
+package shape
+
+// Expr is a recursive union type representing arithmetic expressions.
+type Expr interface {
+	isExpr()
+}
+
+type Lit struct {
+	Value int
+}
+
+type Add struct {
+	Left  Expr
+	Right Expr
+}
+
+type Neg struct {
+	Operand Expr
+}
+
+func (*Lit) isExpr() {}
+func (*Add) isExpr() {}
+func (*Neg) isExpr() {}
@@ -0,0 +1,33 @@
+package shape
+
+// Shape is a union type representing geometric shapes.
+type Shape interface {
+	isShape()
+}
+
+type Circle struct {
+	Radius float64
+}
+
+type Rectangle struct {
+	Width  float64
+	Height float64
+}
+
+type Point struct {
+	X, Y int
+}
+
+func (*Circle) isShape()    {}
+func (*Rectangle) isShape() {}
+func (Point) isShape()      {}
+
+// ShapeKind names the members of Shape.
+type ShapeKind int
+
+//gounion:enum
+const (
+	CircleKind ShapeKind = iota
+	RectangleKind
+	KindPoint
+)
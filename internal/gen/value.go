@@ -0,0 +1,73 @@
+package gen
+
+// genValue emits a flat representation of the union for hot paths:
+// <Union>Value holds a <Union>Tag and one field per member, stored by
+// value, so that slices of values need no per-value allocation. It comes
+// with conversions from and to the union and an exhaustive Switch method.
+func genValue(f *File, u Union) error {
+	tag := u.Name + "Tag"
+	value := u.Name + "Value"
+
+	f.Printf("\n// %s identifies the member held by a %s.\n", tag, value)
+	f.Printf("type %s uint8\n", tag)
+	f.Printf("\n// The members of the %s union; the zero %s holds none.\n", u.Name, tag)
+	f.Printf("const (\n\t%sNone %s = iota\n", tag, tag)
+	for _, m := range u.Members {
+		f.Printf("\t%s%s\n", tag, m.Name)
+	}
+	f.Printf(")\n")
+
+	f.Printf("\n// %s is an allocation-free representation of the %s union: Tag\n", value, u.Name)
+	f.Printf("// selects the field holding the member. A nil pointer member is held\n")
+	f.Printf("// as its zero value.\n")
+	f.Printf("type %s struct {\n\tTag %s\n\n", value, tag)
+	for _, m := range u.Members {
+		f.Printf("\t%s %s\n", m.Name, m.Name)
+	}
+	f.Printf("}\n")
+
+	f.Printf("\n// %sOf returns the %s holding the member of u, or the zero\n", value, value)
+	f.Printf("// %s if u is nil.\n", value)
+	f.Printf("func %sOf(u %s) %s {\n", value, u.Name, value)
+	f.Printf("\tvar v %s\n", value)
+	f.Printf("\tswitch m := u.(type) {\n")
+	for _, m := range u.Members {
+		f.Printf("\tcase %s:\n\t\tv.Tag = %s%s\n", m.TypeExpr(), tag, m.Name)
+		if m.Pointer {
+			f.Printf("\t\tif m != nil {\n\t\t\tv.%s = *m\n\t\t}\n", m.Name)
+		} else {
+			f.Printf("\t\tv.%s = m\n", m.Name)
+		}
+	}
+	f.Printf("\t}\n\treturn v\n}\n")
+
+	f.Printf("\n// %s returns the member held by v as a %s, or nil if v holds none.\n", u.Name, u.Name)
+	f.Printf("func (v *%s) %s() %s {\n", value, u.Name, u.Name)
+	f.Printf("\tswitch v.Tag {\n")
+	for _, m := range u.Members {
+		f.Printf("\tcase %s%s:\n", tag, m.Name)
+		if m.Pointer {
+			f.Printf("\t\tm := v.%s\n\t\treturn &m\n", m.Name)
+		} else {
+			f.Printf("\t\treturn v.%s\n", m.Name)
+		}
+	}
+	f.Printf("\t}\n\treturn nil\n}\n")
+
+	f.Printf("\n// Switch calls the function for the member held by v with a pointer to\n")
+	f.Printf("// its field. It panics if v holds no member.\n")
+	f.Printf("func (v *%s) Switch(", value)
+	for i, m := range u.Members {
+		if i > 0 {
+			f.Printf(", ")
+		}
+		f.Printf("on%s func(*%s)", m.Name, m.Name)
+	}
+	f.Printf(") {\n\tswitch v.Tag {\n")
+	for _, m := range u.Members {
+		f.Printf("\tcase %s%s:\n\t\ton%s(&v.%s)\n", tag, m.Name, m.Name, m.Name)
+	}
+	f.Printf("\tdefault:\n\t\tpanic(\"%s holds no member\")\n\t}\n}\n", value)
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package gen
+
+// genWire emits <Union>Wire, a flat struct with one optional pointer field
+// per member, for systems that can't express sums (SQL rows, form
+// encoders, proto2-style messages), with conversions from and to the union
+// and a Validate method checking that exactly one field is set.
+func genWire(f *File, u Union) error {
+	f.Import("errors")
+	f.Import("fmt")
+
+	wire := u.Name + "Wire"
+	f.Printf("\n// %s is a flat representation of the %s union, with one\n", wire, u.Name)
+	f.Printf("// optional field per member, of which exactly one is set in a valid value.\n")
+	f.Printf("type %s struct {\n", wire)
+	for _, m := range u.Members {
+		f.Printf("\t%s *%s\n", m.Name, m.Name)
+	}
+	f.Printf("}\n")
+
+	f.Printf("\n// %sOf returns the %s with the field of the member held by\n", wire, wire)
+	f.Printf("// u set, or with no field set if u is nil or a nil pointer member.\n")
+	f.Printf("func %sOf(u %s) %s {\n", wire, u.Name, wire)
+	f.Printf("\tvar w %s\n", wire)
+	f.Printf("\tswitch m := u.(type) {\n")
+	for _, m := range u.Members {
+		f.Printf("\tcase %s:\n", m.TypeExpr())
+		if m.Pointer {
+			f.Printf("\t\tw.%s = m\n", m.Name)
+		} else {
+			f.Printf("\t\tw.%s = &m\n", m.Name)
+		}
+	}
+	f.Printf("\t}\n\treturn w\n}\n")
+
+	f.Printf("\n// Validate returns an error unless exactly one field of w is set.\n")
+	f.Printf("func (w %s) Validate() error {\n", wire)
+	f.Printf("\tvar set []string\n")
+	for _, m := range u.Members {
+		f.Printf("\tif w.%s != nil {\n\t\tset = append(set, %q)\n\t}\n", m.Name, m.Name)
+	}
+	f.Printf("\tswitch len(set) {\n")
+	f.Printf("\tcase 0:\n\t\treturn errors.New(\"no %s member set\")\n", u.Name)
+	f.Printf("\tcase 1:\n\t\treturn nil\n\t}\n")
+	f.Printf("\treturn fmt.Errorf(\"several %s members set: %%v\", set)\n}\n", u.Name)
+
+	f.Printf("\n// %s returns the member set in w as a %s, or the error of Validate.\n", u.Name, u.Name)
+	f.Printf("func (w %s) %s() (%s, error) {\n", wire, u.Name, u.Name)
+	f.Printf("\tif err := w.Validate(); err != nil {\n\t\treturn nil, err\n\t}\n")
+	f.Printf("\tswitch {\n")
+	for _, m := range u.Members {
+		f.Printf("\tcase w.%s != nil:\n", m.Name)
+		if m.Pointer {
+			f.Printf("\t\treturn w.%s, nil\n", m.Name)
+		} else {
+			f.Printf("\t\treturn *w.%s, nil\n", m.Name)
+		}
+	}
+	f.Printf("\t}\n\tpanic(\"unreachable\")\n}\n")
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package gen
+
+// genXML emits <Union>XML, a wrapper holding a union value that implements
+// xml.Marshaler and xml.Unmarshaler. The member is encoded as the element,
+// with its name in a "type" attribute as discriminator. Decoding uses that
+// attribute, or the element name if there is none, so that members can
+// also be told apart by element, e.g. <Circle>.
+func genXML(f *File, u Union) error {
+	f.Import("encoding/xml")
+	f.Import("fmt")
+
+	f.Helper("xmlDiscriminator", func() {
+		f.Printf("\n// gounionXMLDiscriminator is the attribute naming the member of an XML encoded union.\n")
+		f.Printf("const gounionXMLDiscriminator = \"type\"\n")
+	})
+
+	wrapper := u.Name + "XML"
+	f.Printf("\n// %s holds a %s union value encoded as XML, with the member name in\n", wrapper, u.Name)
+	f.Printf("// a \"type\" attribute, or as the element name when decoding.\n")
+	// A named field, unlike an embedded one, does not make the wrapper a member.
+	f.Printf("type %s struct {\n\t%s %s\n}\n", wrapper, u.Name, u.Name)
+
+	f.Printf("\n// MarshalXML implements xml.Marshaler. A nil %s is omitted.\n", u.Name)
+	f.Printf("func (x %s) MarshalXML(e *xml.Encoder, start xml.StartElement) error {\n", wrapper)
+	f.Printf("\tvar name string\n")
+	f.Printf("\tswitch x.%s.(type) {\n", u.Name)
+	f.Printf("\tcase nil:\n\t\treturn nil\n")
+	for _, m := range u.Members {
+		f.Printf("\tcase %s:\n\t\tname = %q\n", m.TypeExpr(), m.Discriminator)
+	}
+	f.Printf("\tdefault:\n\t\treturn fmt.Errorf(\"%%T is not a member of %s\", x.%s)\n\t}\n", u.Name, u.Name)
+	f.Printf("\tstart.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: gounionXMLDiscriminator}, Value: name})\n")
+	f.Printf("\treturn e.EncodeElement(x.%s, start)\n}\n", u.Name)
+
+	f.Printf("\n// UnmarshalXML implements xml.Unmarshaler.\n")
+	f.Printf("func (x *%s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n", wrapper)
+	f.Printf("\tname := start.Name.Local\n")
+	f.Printf("\tfor _, attr := range start.Attr {\n")
+	f.Printf("\t\tif attr.Name.Local == gounionXMLDiscriminator {\n\t\t\tname = attr.Value\n\t\t\tbreak\n\t\t}\n\t}\n")
+	f.Printf("\tswitch name {\n")
+	for _, m := range u.Members {
+		f.Printf("\tcase %q:\n", m.Discriminator)
+		f.Printf("\t\tvar m %s\n", m.Name)
+		f.Printf("\t\tif err := d.DecodeElement(&m, &start); err != nil {\n\t\t\treturn err\n\t\t}\n")
+		if m.Pointer {
+			f.Printf("\t\tx.%s = &m\n", u.Name)
+		} else {
+			f.Printf("\t\tx.%s = m\n", u.Name)
+		}
+	}
+	f.Printf("\tdefault:\n\t\treturn fmt.Errorf(\"unknown %s member %%q\", name)\n\t}\n", u.Name)
+	f.Printf("\treturn nil\n}\n")
+
+	return nil
+}
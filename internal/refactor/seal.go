@@ -0,0 +1,318 @@
+package refactor
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// categorySeal is the category of the diagnostics of Seal.
+const categorySeal = "seal"
+
+// Seal creates a union interface from existing types.
+var Seal = &analysis.Analyzer{
+	Name: "seal",
+	Doc: `creates a union interface from existing types
+
+Seal declares the interface named by -interface with the marker method
+isShape (for -interface=Shape), before the first of the types listed by
+-types, and declares the marker method on each of them, with a pointer
+receiver for types listed as *T. If the interface is already declared in
+the package, the marker method is added to it instead. With -constructors,
+it also declares a function NewT for each type T, taking the fields of a
+struct type as parameters, returning a T as the interface.`,
+	Run: runSeal,
+}
+
+// Flags of Seal.
+var (
+	sealInterface    string
+	sealTypes        string
+	sealConstructors bool
+)
+
+func init() {
+	Seal.Flags.StringVar(&sealInterface, "interface", "", "name of the union interface, e.g. Shape")
+	Seal.Flags.StringVar(&sealTypes, "types", "", "comma-separated members of the union, declared in one package, as *T for pointer receivers, e.g. Circle,*Rectangle")
+	Seal.Flags.BoolVar(&sealConstructors, "constructors", false, "also declare a constructor NewT returning each member as the interface")
+}
+
+// sealMember is a type listed by -types.
+type sealMember struct {
+	obj     *types.TypeName
+	pointer bool // whether the marker method has a pointer receiver
+}
+
+// typ returns the type of the member implementing the union.
+func (m sealMember) typ() types.Type {
+	if m.pointer {
+		return types.NewPointer(m.obj.Type())
+	}
+	return m.obj.Type()
+}
+
+func runSeal(pass *analysis.Pass) (interface{}, error) {
+	if sealInterface == "" || sealTypes == "" {
+		return nil, errors.New("-interface and -types are required")
+	}
+	marker := "is" + sealInterface
+
+	var (
+		members []sealMember
+		missing []string
+	)
+	for _, name := range strings.Split(sealTypes, ",") {
+		name = strings.TrimSpace(name)
+		ptr := strings.HasPrefix(name, "*")
+		name = strings.TrimPrefix(name, "*")
+		if obj, ok := pass.Pkg.Scope().Lookup(name).(*types.TypeName); ok {
+			members = append(members, sealMember{obj: obj, pointer: ptr})
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	if len(members) == 0 {
+		return nil, nil // The types are declared in another package
+	}
+	report := func(pos token.Pos, format string, args ...interface{}) (interface{}, error) {
+		pass.Report(analysis.Diagnostic{
+			Pos:      pos,
+			Category: categorySeal,
+			Message:  fmt.Sprintf("cannot seal %s: ", sealInterface) + fmt.Sprintf(format, args...),
+		})
+		return nil, nil
+	}
+	if len(missing) > 0 {
+		return report(members[0].obj.Pos(), "%s not declared in package %s", strings.Join(missing, ", "), pass.Pkg.Name())
+	}
+
+	var iface *types.Interface
+	if obj := pass.Pkg.Scope().Lookup(sealInterface); obj != nil {
+		var ok bool
+		if iface, ok = obj.Type().Underlying().(*types.Interface); !ok || obj.(*types.TypeName).IsAlias() {
+			return report(obj.Pos(), "%s is declared and is not an interface", sealInterface)
+		}
+		if m, _, _ := types.LookupFieldOrMethod(obj.Type(), false, pass.Pkg, marker); m != nil {
+			return report(obj.Pos(), "%s already has a method %s", sealInterface, marker)
+		}
+	}
+	for _, m := range members {
+		named, ok := m.obj.Type().(*types.Named)
+		switch {
+		case !ok || m.obj.IsAlias():
+			return report(m.obj.Pos(), "%s is an alias", m.obj.Name())
+		case named.TypeParams() != nil:
+			return report(m.obj.Pos(), "%s is generic", m.obj.Name())
+		case types.IsInterface(named):
+			return report(m.obj.Pos(), "%s is an interface", m.obj.Name())
+		}
+		if obj, _, _ := types.LookupFieldOrMethod(m.typ(), false, pass.Pkg, marker); obj != nil {
+			return report(m.obj.Pos(), "%s already has a field or method %s", m.obj.Name(), marker)
+		}
+		if iface != nil && !types.Implements(m.typ(), iface) {
+			return report(m.obj.Pos(), "%s does not implement %s", types.TypeString(m.typ(), types.RelativeTo(pass.Pkg)), sealInterface)
+		}
+		if sealConstructors && pass.Pkg.Scope().Lookup("New"+m.obj.Name()) != nil {
+			return report(m.obj.Pos(), "New%s is already declared", m.obj.Name())
+		}
+	}
+
+	decls := typeDecls(pass)
+	var edits []analysis.TextEdit
+	if iface == nil {
+		first := decls[members[0].obj]
+		pos := first.decl.Pos()
+		if first.decl.Doc != nil {
+			pos = first.decl.Doc.Pos()
+		}
+		edits = append(edits, analysis.TextEdit{
+			Pos:     pos,
+			NewText: []byte(fmt.Sprintf("// %s is a union of %s.\ntype %s interface {\n\t%s()\n}\n\n", sealInterface, memberList(pass, members), sealInterface, marker)),
+		})
+	} else {
+		edit, err := addMarker(pass, decls[pass.Pkg.Scope().Lookup(sealInterface).(*types.TypeName)], marker)
+		if err != nil {
+			return report(pass.Pkg.Scope().Lookup(sealInterface).Pos(), "%v", err)
+		}
+		edits = append(edits, edit)
+	}
+	for _, m := range members {
+		d := decls[m.obj]
+		recv := m.obj.Name()
+		if m.pointer {
+			recv = "*" + recv
+		}
+		text := fmt.Sprintf("\n\nfunc (%s) %s() {}", recv, marker)
+		if sealConstructors {
+			text += constructor(d.file, m)
+		}
+		edits = append(edits, analysis.TextEdit{Pos: lineEnd(pass, d.decl.End()), NewText: []byte(text)})
+	}
+
+	pass.Report(analysis.Diagnostic{
+		Pos:      members[0].obj.Pos(),
+		Category: categorySeal,
+		Message:  fmt.Sprintf("%s can be sealed into the union %s", memberList(pass, members), sealInterface),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "Seal into " + sealInterface,
+			TextEdits: edits,
+		}},
+	})
+	return nil, nil
+}
+
+// memberList returns the members as a list for messages.
+func memberList(pass *analysis.Pass, members []sealMember) string {
+	names := make([]string, len(members))
+	for i, m := range members {
+		names[i] = types.TypeString(m.typ(), types.RelativeTo(pass.Pkg))
+	}
+	return strings.Join(names, ", ")
+}
+
+// typeDecl is the declaration of a type.
+type typeDecl struct {
+	file *ast.File
+	decl *ast.GenDecl
+	spec *ast.TypeSpec
+}
+
+// typeDecls returns the declarations of the types of the package.
+func typeDecls(pass *analysis.Pass) map[*types.TypeName]typeDecl {
+	decls := make(map[*types.TypeName]typeDecl)
+	for _, f := range pass.Files {
+		for _, decl := range f.Decls {
+			d, ok := decl.(*ast.GenDecl)
+			if !ok || d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				spec := spec.(*ast.TypeSpec)
+				if obj, ok := pass.TypesInfo.Defs[spec.Name].(*types.TypeName); ok {
+					decls[obj] = typeDecl{file: f, decl: d, spec: spec}
+				}
+			}
+		}
+	}
+	return decls
+}
+
+// addMarker returns the edit adding the marker method to the interface
+// declared by d. An interface on one line is rewritten over several lines,
+// as gofmt would.
+func addMarker(pass *analysis.Pass, d typeDecl, marker string) (analysis.TextEdit, error) {
+	it, ok := d.spec.Type.(*ast.InterfaceType)
+	if !ok {
+		return analysis.TextEdit{}, errors.New("its declaration is not an interface type literal")
+	}
+	indent := ""
+	if d.decl.Lparen.IsValid() {
+		indent = "\t"
+	}
+	fields := it.Methods
+	if pass.Fset.Position(fields.Opening).Line != pass.Fset.Position(fields.Closing).Line {
+		return analysis.TextEdit{Pos: fields.Closing, NewText: []byte("\t" + marker + "()\n" + indent)}, nil
+	}
+
+	src, err := pass.ReadFile(pass.Fset.File(it.Pos()).Name())
+	if err != nil {
+		return analysis.TextEdit{}, err
+	}
+	tf := pass.Fset.File(it.Pos())
+	var b strings.Builder
+	b.WriteString("interface {\n")
+	for _, field := range fields.List {
+		fmt.Fprintf(&b, "%s\t%s\n", indent, src[tf.Offset(field.Pos()):tf.Offset(field.End())])
+	}
+	fmt.Fprintf(&b, "%s\t%s()\n%s}", indent, marker, indent)
+	return analysis.TextEdit{Pos: it.Pos(), End: it.End(), NewText: []byte(b.String())}, nil
+}
+
+// constructor returns the declaration of the constructor of m, preceded
+// by a blank line. The constructor of a struct type takes its fields, of
+// other types a value of their underlying type.
+func constructor(file *ast.File, m sealMember) string {
+	qualifier := fileQualifier(file, m.obj.Pkg())
+	name := m.obj.Name()
+	article := "a"
+	if strings.ContainsRune("AEIOU", rune(name[0])) {
+		article = "an"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n\n// New%s returns %s %s as a %s.\n", name, article, name, sealInterface)
+	st, ok := m.obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		fmt.Fprintf(&b, "func New%s(v %s) %s {\n", name, types.TypeString(m.obj.Type().Underlying(), qualifier), sealInterface)
+		if m.pointer {
+			fmt.Fprintf(&b, "\tu := %s(v)\n\treturn &u\n}", name)
+		} else {
+			fmt.Fprintf(&b, "\treturn %s(v)\n}", name)
+		}
+		return b.String()
+	}
+
+	var params, keys []string
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if field.Name() == "_" {
+			continue
+		}
+		param := paramName(field.Name())
+		params = append(params, param+" "+types.TypeString(field.Type(), qualifier))
+		keys = append(keys, field.Name()+": "+param)
+	}
+	amp := ""
+	if m.pointer {
+		amp = "&"
+	}
+	fmt.Fprintf(&b, "func New%s(%s) %s {\n", name, strings.Join(params, ", "), sealInterface)
+	fmt.Fprintf(&b, "\treturn %s%s{%s}\n}", amp, name, strings.Join(keys, ", "))
+	return b.String()
+}
+
+// paramName returns the parameter name for a field, with its leading
+// upper case letters, as in an initialism, in lower case, e.g. "urlPath"
+// for URLPath.
+func paramName(field string) string {
+	runes := []rune(field)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	// Keep the first letter of the next word, as P in URLPath.
+	if n > 1 && n < len(runes) {
+		n--
+	}
+	for i := 0; i < n; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	name := string(runes)
+	if token.IsKeyword(name) {
+		name += "_"
+	}
+	return name
+}
+
+// fileQualifier returns a types.Qualifier writing the packages imported by
+// file by the name they are imported as, and pkg unqualified.
+func fileQualifier(file *ast.File, pkg *types.Package) types.Qualifier {
+	return func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		for _, imp := range file.Imports {
+			if path, err := strconv.Unquote(imp.Path.Value); err == nil && path == p.Path() && imp.Name != nil {
+				return imp.Name.Name
+			}
+		}
+		return p.Name()
+	}
+}
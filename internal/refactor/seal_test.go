@@ -0,0 +1,40 @@
+package refactor_test
+
+import (
+	"testing"
+
+	"github.com/YuitoSato/gounion/internal/refactor"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func setSealFlags(t *testing.T, flags map[string]string) {
+	t.Helper()
+	for name, value := range flags {
+		if err := refactor.Seal.Flags.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSeal(t *testing.T) {
+	setSealFlags(t, map[string]string{"interface": "Shape", "types": "Circle,*Rectangle,Meters", "constructors": "true"})
+	defer setSealFlags(t, map[string]string{"interface": "", "types": "", "constructors": "false"})
+
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), refactor.Seal, "shapes")
+}
+
+func TestSealExistingInterface(t *testing.T) {
+	setSealFlags(t, map[string]string{"interface": "Node", "types": "Leaf,Branch"})
+	defer setSealFlags(t, map[string]string{"interface": "", "types": ""})
+
+	// The marker method is added to the declared interface.
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), refactor.Seal, "sealed")
+}
+
+func TestSealNotImplemented(t *testing.T) {
+	setSealFlags(t, map[string]string{"interface": "Expr", "types": "Lit"})
+	defer setSealFlags(t, map[string]string{"interface": "", "types": ""})
+
+	analysistest.Run(t, analysistest.TestData(), refactor.Seal, "unsealable")
+}
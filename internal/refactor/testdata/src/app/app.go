@@ -0,0 +1,21 @@
+package app
+
+import (
+	"ui"
+)
+
+func Press(code rune) ui.Event {
+	return ui.Event{Kind: ui.KindKey, Key: &ui.KeyData{Code: code}} // want `Event literal can be rewritten as a \*KeyData`
+}
+
+func Code(e ui.Event) rune {
+	switch e.Kind { // want `switch on e.Kind can be rewritten as a type switch on e`
+	case ui.KindKey:
+		return e.Key.Code
+	}
+	return 0
+}
+
+func Clicked(e ui.Event) bool {
+	return e.Click != nil // want `use of Event.Click must be rewritten by hand`
+}
@@ -0,0 +1,17 @@
+package notagged
+
+type Kind int
+
+const (
+	CircleKind Kind = iota
+)
+
+type Circle struct{ R float64 }
+
+type Square struct{ S float64 }
+
+type Shape struct { // want `Shape cannot be rewritten as a union: Kind has no constant for variant Square; add SquareKind`
+	Kind   Kind
+	Circle *Circle
+	Square *Square
+}
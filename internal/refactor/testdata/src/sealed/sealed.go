@@ -0,0 +1,11 @@
+package sealed
+
+type Node interface{ Pos() int }
+
+type Leaf struct{ P int } // want `Leaf, Branch can be sealed into the union Node`
+
+func (l Leaf) Pos() int { return l.P }
+
+type Branch struct{ Kids []Node }
+
+func (Branch) Pos() int { return 0 }
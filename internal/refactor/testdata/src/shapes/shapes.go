@@ -0,0 +1,16 @@
+package shapes
+
+import "image/color"
+
+// Circle is a circle.
+type Circle struct { // want `Circle, \*Rectangle, Meters can be sealed into the union Shape`
+	Radius float64
+	Fill   color.Color
+}
+
+type Rectangle struct {
+	W, H    float64
+	URLPath string
+}
+
+type Meters float64
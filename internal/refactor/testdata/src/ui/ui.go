@@ -0,0 +1,60 @@
+package ui
+
+import "fmt"
+
+type EventKind int
+
+const (
+	KindUnknown EventKind = iota
+	KindClick
+	KindKey
+)
+
+type ClickData struct{ X, Y int }
+
+type KeyData struct{ Code rune }
+
+type Event struct { // want `Event can be rewritten as a union of \*ClickData, \*KeyData`
+	Kind  EventKind
+	Click *ClickData
+	Key   *KeyData
+}
+
+func click(x, y int) Event {
+	return Event{Kind: KindClick, Click: &ClickData{X: x, Y: y}} // want `Event literal can be rewritten as a \*ClickData`
+}
+
+func key() Event {
+	return Event{Kind: KindKey} // want `Event literal can be rewritten as a \*KeyData`
+}
+
+func unknown() *Event {
+	return &Event{Kind: KindUnknown} // want `Event literal must be rewritten by hand`
+}
+
+func describe(e Event) string {
+	switch e.Kind { // want `switch on e.Kind can be rewritten as a type switch on e`
+	case KindClick:
+		return fmt.Sprintf("click at %d,%d", e.Click.X, e.Click.Y)
+	case KindKey:
+		return fmt.Sprintf("key %c", e.Key.Code)
+	}
+	return ""
+}
+
+func isInput(e Event) bool {
+	switch e.Kind { // want `switch on e.Kind can be rewritten as a type switch on e`
+	case KindClick, KindKey:
+		return true
+	}
+	return false
+}
+
+func debug(e Event) string {
+	switch e.Kind { // want `use of Event.Kind must be rewritten by hand`
+	case KindClick:
+		return "click"
+	default:
+		return fmt.Sprint(e.Kind) // want `use of Event.Kind must be rewritten by hand`
+	}
+}
@@ -0,0 +1,7 @@
+package unsealable
+
+type Expr interface {
+	Eval() int
+}
+
+type Lit int // want `cannot seal Expr: Lit does not implement Expr`
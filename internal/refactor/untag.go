@@ -0,0 +1,547 @@
+// Package refactor provides analyzers whose suggested fixes rewrite code
+// toward union interfaces. The gounion command runs them as codemods,
+// applying their fixes like those of the gounion analyzer.
+package refactor
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/YuitoSato/gounion/gounion/registry"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// categoryUntag is the category of the diagnostics of Untag.
+const categoryUntag = "untag"
+
+// Untag rewrites a tagged struct into a union interface.
+var Untag = &analysis.Analyzer{
+	Name: "untag",
+	Doc: `rewrites a tagged struct into a union interface
+
+A tagged struct has a kind field, of a named type with constants, and one
+pointer field per variant, to a struct type of the same package:
+
+	type Event struct {
+		Kind  EventKind
+		Click *ClickData
+		Key   *KeyData
+	}
+
+where the constant of each variant is named after its field, as ClickKind,
+KindClick or EventKindClick. Untag rewrites the struct named by -type into
+a union of the variant types with the marker method isEvent, its keyed
+literals into the variant they hold, and switches on the kind of a variable
+into type switches on the variable. Other uses of the fields of the struct
+are reported, to be rewritten by hand.`,
+	Run:      runUntag,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+// untagType is the -type flag of Untag.
+var untagType string
+
+func init() {
+	Untag.Flags.StringVar(&untagType, "type", "",
+		"tagged struct to rewrite, by name or qualified by its package path, e.g. example.com/ui.Event")
+}
+
+// tagged is a tagged struct.
+type tagged struct {
+	obj      *types.TypeName
+	kind     *types.Var // kind field
+	variants []variant  // in field order
+	marker   string     // marker method of the union
+}
+
+// variant is a variant of a tagged struct.
+type variant struct {
+	field    *types.Var      // pointer field holding the variant
+	typ      *types.TypeName // struct type the field points to
+	constant *types.Const    // kind constant of the variant
+}
+
+// byConstant returns the variant of the kind constant obj, or nil.
+func (t *tagged) byConstant(obj types.Object) *variant {
+	for i := range t.variants {
+		if t.variants[i].constant == obj {
+			return &t.variants[i]
+		}
+	}
+	return nil
+}
+
+// isField reports whether obj is a field of the struct.
+func (t *tagged) isField(obj types.Object) bool {
+	if obj == t.kind {
+		return true
+	}
+	for _, v := range t.variants {
+		if v.field == obj {
+			return true
+		}
+	}
+	return false
+}
+
+func runUntag(pass *analysis.Pass) (interface{}, error) {
+	if untagType == "" {
+		return nil, errors.New("-type is required")
+	}
+	obj := lookupType(pass.Pkg, untagType)
+	if obj == nil {
+		return nil, nil // The package cannot refer to the struct
+	}
+	t, err := newTagged(obj)
+	if err != nil {
+		if obj.Pkg() == pass.Pkg {
+			pass.Report(analysis.Diagnostic{
+				Pos:      obj.Pos(),
+				Category: categoryUntag,
+				Message:  fmt.Sprintf("%s cannot be rewritten as a union: %v", obj.Name(), err),
+			})
+		}
+		return nil, nil
+	}
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{
+		(*ast.File)(nil),
+		(*ast.TypeSpec)(nil),
+		(*ast.UnaryExpr)(nil),
+		(*ast.CompositeLit)(nil),
+		(*ast.SwitchStmt)(nil),
+		(*ast.SelectorExpr)(nil),
+	}
+
+	var (
+		file      *ast.File
+		genDecl   *ast.GenDecl
+		addressed = make(map[*ast.CompositeLit]bool) // operands of &
+		rewritten = make(map[ast.Node]bool)          // selectors rewritten by a fix
+	)
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.File:
+			file = n
+			for _, decl := range n.Decls {
+				if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.TYPE {
+					for _, spec := range d.Specs {
+						if pass.TypesInfo.Defs[spec.(*ast.TypeSpec).Name] == t.obj {
+							genDecl = d
+						}
+					}
+				}
+			}
+		case *ast.TypeSpec:
+			if pass.TypesInfo.Defs[n.Name] == t.obj {
+				pass.Report(t.declDiagnostic(pass, genDecl, n))
+			}
+		case *ast.UnaryExpr:
+			if lit, ok := ast.Unparen(n.X).(*ast.CompositeLit); ok && n.Op == token.AND {
+				addressed[lit] = true
+			}
+		case *ast.CompositeLit:
+			if !types.Identical(pass.TypesInfo.TypeOf(n), t.obj.Type()) {
+				return
+			}
+			if d, ok := t.litDiagnostic(pass, file, n, addressed[n]); ok {
+				pass.Report(d)
+				return
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:      n.Pos(),
+				Category: categoryUntag,
+				Message:  fmt.Sprintf("%s literal must be rewritten by hand", t.obj.Name()),
+			})
+		case *ast.SwitchStmt:
+			if d, ok := t.switchDiagnostic(pass, file, n, rewritten); ok {
+				pass.Report(d)
+			}
+		case *ast.SelectorExpr:
+			if rewritten[n] || !t.isField(pass.TypesInfo.Uses[n.Sel]) {
+				return
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:      n.Pos(),
+				Category: categoryUntag,
+				Message:  fmt.Sprintf("use of %s.%s must be rewritten by hand", t.obj.Name(), n.Sel.Name),
+			})
+		}
+	})
+	return nil, nil
+}
+
+// lookupType returns the type named name, optionally qualified by its
+// package path, declared in pkg or in a package it imports, or nil.
+func lookupType(pkg *types.Package, name string) *types.TypeName {
+	path := ""
+	if i := strings.LastIndex(name, "."); i >= 0 && i > strings.LastIndex(name, "/") {
+		path, name = name[:i], name[i+1:]
+	}
+	for _, p := range append([]*types.Package{pkg}, pkg.Imports()...) {
+		if path != "" && p.Path() != path {
+			continue
+		}
+		if obj, ok := p.Scope().Lookup(name).(*types.TypeName); ok {
+			return obj
+		}
+	}
+	return nil
+}
+
+// newTagged returns the tagged struct obj, or an error explaining why obj
+// is not one.
+func newTagged(obj *types.TypeName) (*tagged, error) {
+	named, ok := obj.Type().(*types.Named)
+	if !ok || named.TypeParams() != nil {
+		return nil, errors.New("not a defined, non-generic type")
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil, errors.New("not a struct")
+	}
+	if named.NumMethods() > 0 {
+		return nil, errors.New("it has methods")
+	}
+
+	t := &tagged{obj: obj, marker: "is" + obj.Name()}
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if t.kind == nil && isKindType(field.Type()) {
+			t.kind = field
+			continue
+		}
+		typ := variantType(field.Type(), obj.Pkg())
+		if typ == nil || field.Embedded() {
+			return nil, fmt.Errorf("field %s is neither its kind nor a pointer to a struct of package %s", field.Name(), obj.Pkg().Name())
+		}
+		if m, _, _ := types.LookupFieldOrMethod(typ.Type(), true, obj.Pkg(), t.marker); m != nil {
+			return nil, fmt.Errorf("%s already has a field or method %s", typ.Name(), t.marker)
+		}
+		t.variants = append(t.variants, variant{field: field, typ: typ})
+	}
+	if t.kind == nil {
+		return nil, errors.New("it has no kind field")
+	}
+	if len(t.variants) == 0 {
+		return nil, errors.New("it has no variant fields")
+	}
+
+	// Pair the variants with the constants of the kind type. Constants
+	// naming no variant, such as KindUnknown, are left out.
+	kind := t.kind.Type().(*types.Named).Obj()
+	scope := kind.Pkg().Scope()
+	fields := make([]string, len(t.variants))
+	for i, v := range t.variants {
+		fields[i] = v.field.Name()
+	}
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || !types.Identical(c.Type(), kind.Type()) {
+			continue
+		}
+		for i := range t.variants {
+			v := &t.variants[i]
+			if registry.KindName(name, kind.Name(), fields) != v.field.Name() {
+				continue
+			}
+			if v.constant != nil {
+				return nil, fmt.Errorf("constants %s and %s both name variant %s", v.constant.Name(), name, v.field.Name())
+			}
+			v.constant = c
+		}
+	}
+	for _, v := range t.variants {
+		if v.constant == nil {
+			return nil, fmt.Errorf("%s has no constant for variant %s; add %sKind", kind.Name(), v.field.Name(), v.field.Name())
+		}
+	}
+	return t, nil
+}
+
+// isKindType reports whether typ can be the type of a kind field: a
+// defined type of integers or strings.
+func isKindType(typ types.Type) bool {
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return false
+	}
+	basic, ok := named.Underlying().(*types.Basic)
+	return ok && basic.Info()&(types.IsInteger|types.IsString) != 0
+}
+
+// variantType returns the struct type of pkg a variant field of type typ
+// points to, or nil.
+func variantType(typ types.Type, pkg *types.Package) *types.TypeName {
+	ptr, ok := typ.(*types.Pointer)
+	if !ok {
+		return nil
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok || named.Obj().Pkg() != pkg || named.TypeArgs() != nil {
+		return nil
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return nil
+	}
+	return named.Obj()
+}
+
+// declDiagnostic reports the declaration of the struct, in spec of
+// genDecl, with a fix replacing it by the union interface and declaring
+// the marker method on each variant type.
+func (t *tagged) declDiagnostic(pass *analysis.Pass, genDecl *ast.GenDecl, spec *ast.TypeSpec) analysis.Diagnostic {
+	indent := ""
+	if genDecl.Lparen.IsValid() {
+		indent = "\t"
+	}
+	members := make([]string, len(t.variants))
+	var methods strings.Builder
+	for i, v := range t.variants {
+		members[i] = "*" + v.typ.Name()
+		fmt.Fprintf(&methods, "\n\nfunc (*%s) %s() {}", v.typ.Name(), t.marker)
+	}
+
+	return analysis.Diagnostic{
+		Pos:      spec.Name.Pos(),
+		Category: categoryUntag,
+		Message:  fmt.Sprintf("%s can be rewritten as a union of %s", t.obj.Name(), strings.Join(members, ", ")),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: fmt.Sprintf("Rewrite %s as a union interface", t.obj.Name()),
+			TextEdits: []analysis.TextEdit{
+				{
+					Pos:     spec.Type.Pos(),
+					End:     spec.Type.End(),
+					NewText: []byte(fmt.Sprintf("interface {\n%s\t%s()\n%s}", indent, t.marker, indent)),
+				},
+				{
+					Pos:     lineEnd(pass, genDecl.End()),
+					NewText: []byte(methods.String()),
+				},
+			},
+		}},
+	}
+}
+
+// litDiagnostic reports a literal of the struct with a fix replacing it by
+// the variant it holds. It reports false unless the literal is keyed, sets
+// the kind to the constant of a variant and sets no other variant, and is
+// not the operand of &, whose result would no longer have the same type.
+func (t *tagged) litDiagnostic(pass *analysis.Pass, file *ast.File, lit *ast.CompositeLit, addressed bool) (analysis.Diagnostic, bool) {
+	if addressed {
+		return analysis.Diagnostic{}, false
+	}
+	var (
+		v       *variant
+		payload ast.Expr
+		fields  = make(map[types.Object]ast.Expr)
+	)
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return analysis.Diagnostic{}, false
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			return analysis.Diagnostic{}, false
+		}
+		fields[pass.TypesInfo.Uses[key]] = kv.Value
+	}
+	kind, ok := fields[t.kind]
+	if !ok {
+		return analysis.Diagnostic{}, false
+	}
+	if v = t.byConstant(constantOf(pass, kind)); v == nil {
+		return analysis.Diagnostic{}, false
+	}
+	for obj, value := range fields {
+		switch obj {
+		case t.kind:
+		case v.field:
+			payload = value
+		default:
+			return analysis.Diagnostic{}, false
+		}
+	}
+
+	member := "*" + v.typ.Name()
+	var edits []analysis.TextEdit
+	if payload != nil {
+		if id, ok := ast.Unparen(payload).(*ast.Ident); ok && pass.TypesInfo.Uses[id] == types.Universe.Lookup("nil") {
+			return analysis.Diagnostic{}, false // A nil union is not a nil variant
+		}
+		edits = []analysis.TextEdit{
+			{Pos: lit.Pos(), End: payload.Pos()},
+			{Pos: payload.End(), End: lit.End()},
+		}
+	} else {
+		expr, ok := typeExpr(pass, file, v.typ)
+		if !ok {
+			return analysis.Diagnostic{}, false
+		}
+		edits = []analysis.TextEdit{{Pos: lit.Pos(), End: lit.End(), NewText: []byte("&" + expr + "{}")}}
+	}
+
+	return analysis.Diagnostic{
+		Pos:      lit.Pos(),
+		Category: categoryUntag,
+		Message:  fmt.Sprintf("%s literal can be rewritten as a %s", t.obj.Name(), member),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "Rewrite as a " + member,
+			TextEdits: edits,
+		}},
+	}, true
+}
+
+// switchDiagnostic reports a switch on the kind field of a variable with a
+// fix rewriting it into a type switch on the variable, whose case clauses
+// refer to the variant of their constant as the variable itself. It
+// reports false unless every case names the constant of a variant, and
+// the variable is only used in the clauses to select the variant of a
+// clause naming a single constant. The selectors rewritten by the fix are
+// added to rewritten.
+func (t *tagged) switchDiagnostic(pass *analysis.Pass, file *ast.File, stmt *ast.SwitchStmt, rewritten map[ast.Node]bool) (analysis.Diagnostic, bool) {
+	tag, ok := stmt.Tag.(*ast.SelectorExpr)
+	if !ok || pass.TypesInfo.Uses[tag.Sel] != t.kind {
+		return analysis.Diagnostic{}, false
+	}
+	id, ok := tag.X.(*ast.Ident)
+	if !ok || !types.Identical(pass.TypesInfo.TypeOf(id), t.obj.Type()) {
+		return analysis.Diagnostic{}, false
+	}
+	x := pass.TypesInfo.Uses[id]
+
+	var (
+		edits     []analysis.TextEdit
+		selectors []ast.Node
+	)
+	for _, clause := range stmt.Body.List {
+		cc := clause.(*ast.CaseClause)
+		var v *variant
+		for _, expr := range cc.List {
+			if v = t.byConstant(constantOf(pass, expr)); v == nil {
+				return analysis.Diagnostic{}, false
+			}
+			member, ok := typeExpr(pass, file, v.typ)
+			if !ok {
+				return analysis.Diagnostic{}, false
+			}
+			edits = append(edits, analysis.TextEdit{Pos: expr.Pos(), End: expr.End(), NewText: []byte("*" + member)})
+		}
+		if len(cc.List) != 1 {
+			v = nil // The variable keeps the union type
+		}
+		if n := len(cc.Body); n > 0 {
+			if br, ok := cc.Body[n-1].(*ast.BranchStmt); ok && br.Tok == token.FALLTHROUGH {
+				return analysis.Diagnostic{}, false
+			}
+		}
+
+		safe := true
+		for _, stmt := range cc.Body {
+			ast.Inspect(stmt, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.SelectorExpr:
+					if sid, ok := n.X.(*ast.Ident); ok && pass.TypesInfo.Uses[sid] == x {
+						if v == nil || pass.TypesInfo.Uses[n.Sel] != v.field {
+							safe = false
+						}
+						selectors = append(selectors, n)
+						edits = append(edits, analysis.TextEdit{Pos: n.Pos(), End: n.End(), NewText: []byte(id.Name)})
+						return false
+					}
+				case *ast.Ident:
+					if pass.TypesInfo.Uses[n] == x {
+						safe = false
+					}
+				}
+				return true
+			})
+		}
+		if !safe {
+			return analysis.Diagnostic{}, false
+		}
+	}
+
+	// A type switch variable must be used in some clause.
+	guard := id.Name + ".(type)"
+	if len(selectors) > 0 {
+		guard = id.Name + " := " + guard
+	}
+	edits = append(edits, analysis.TextEdit{Pos: tag.Pos(), End: tag.End(), NewText: []byte(guard)})
+	sort.Slice(edits, func(i, j int) bool { return edits[i].Pos < edits[j].Pos })
+
+	rewritten[tag] = true
+	for _, sel := range selectors {
+		rewritten[sel] = true
+	}
+	return analysis.Diagnostic{
+		Pos:      stmt.Pos(),
+		Category: categoryUntag,
+		Message:  fmt.Sprintf("switch on %s.%s can be rewritten as a type switch on %s", id.Name, tag.Sel.Name, id.Name),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "Rewrite as a type switch",
+			TextEdits: edits,
+		}},
+	}, true
+}
+
+// constantOf returns the constant denoted by expr, an identifier or a
+// qualified identifier, or nil.
+func constantOf(pass *analysis.Pass, expr ast.Expr) types.Object {
+	var id *ast.Ident
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	default:
+		return nil
+	}
+	c, _ := pass.TypesInfo.Uses[id].(*types.Const)
+	if c == nil {
+		return nil
+	}
+	return c
+}
+
+// typeExpr returns the expression denoting the type obj in file. It
+// reports false if the package of obj is not imported by name in file.
+func typeExpr(pass *analysis.Pass, file *ast.File, obj *types.TypeName) (string, bool) {
+	if obj.Pkg() == pass.Pkg {
+		return obj.Name(), true
+	}
+	for _, imp := range file.Imports {
+		if path, err := strconv.Unquote(imp.Path.Value); err != nil || path != obj.Pkg().Path() {
+			continue
+		}
+		switch {
+		case imp.Name == nil:
+			return obj.Pkg().Name() + "." + obj.Name(), true
+		case imp.Name.Name == ".":
+			return obj.Name(), true
+		case imp.Name.Name != "_":
+			return imp.Name.Name + "." + obj.Name(), true
+		}
+	}
+	return "", false
+}
+
+// lineEnd returns the end of the line of pos, after any comment ending
+// the line, for inserting declarations after the one ending at pos.
+func lineEnd(pass *analysis.Pass, pos token.Pos) token.Pos {
+	tf := pass.Fset.File(pos)
+	if line := tf.Line(pos); line < tf.LineCount() {
+		return tf.LineStart(line+1) - 1
+	}
+	return token.Pos(tf.Base() + tf.Size())
+}
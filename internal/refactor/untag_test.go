@@ -0,0 +1,29 @@
+package refactor_test
+
+import (
+	"testing"
+
+	"github.com/YuitoSato/gounion/internal/refactor"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestUntag(t *testing.T) {
+	if err := refactor.Untag.Flags.Set("type", "Event"); err != nil {
+		t.Fatal(err)
+	}
+	defer refactor.Untag.Flags.Set("type", "")
+
+	// The struct is rewritten in its package, and its uses in the
+	// importing package.
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), refactor.Untag, "ui", "app")
+}
+
+func TestUntagNotTagged(t *testing.T) {
+	if err := refactor.Untag.Flags.Set("type", "notagged.Shape"); err != nil {
+		t.Fatal(err)
+	}
+	defer refactor.Untag.Flags.Set("type", "")
+
+	analysistest.Run(t, analysistest.TestData(), refactor.Untag, "notagged")
+}
@@ -0,0 +1,177 @@
+// Package settings holds the options shared by the union and enum checks
+// of the gounion analyzer, so that they are configured once, with the same
+// names, whether the analyzer runs standalone, under go vet or as a
+// golangci-lint plugin.
+package settings
+
+import (
+	"flag"
+	"go/types"
+	"regexp"
+	"strings"
+)
+
+// Common holds the shared options. It is embedded in the analyzer's
+// configuration, and its fields are bound to the analyzer's flags by
+// RegisterFlags alongside the other options.
+type Common struct {
+	// StrictDefault checks switches for exhaustiveness even when they have
+	// a default case that handles unknown members.
+	StrictDefault bool
+
+	// SkipTests drops diagnostics in _test.go files. Unions and enums
+	// declared in them are still recognized.
+	SkipTests bool
+
+	// Include restricts diagnostics to the packages matching one of these
+	// import path patterns, where "..." matches any string, as for the go
+	// command (e.g. "example.com/app/..."). Empty means all packages.
+	Include []string
+
+	// Exclude drops diagnostics in the packages matching one of these
+	// patterns, even if they match Include.
+	Exclude []string
+
+	// Terminators lists functions that never return, such as log.Fatal,
+	// by their full name as printed by types.Func.FullName (e.g.
+	// "log.Fatal" or "(*go.uber.org/zap.Logger).Fatal"). Methods can also
+	// be named by their receiver type without parentheses, which covers
+	// pointer, value and interface receivers alike (e.g.
+	// "go.uber.org/zap.Logger.Fatal"), and names can contain * wildcards
+	// (e.g. "log.Panic*" or "*.Fatal"). A default case ending with a call
+	// to one of them is a safety guard, like one ending with panic, so its
+	// switch is still checked.
+	Terminators Funcs
+}
+
+// RegisterFlags binds the options to fs, using the current values as
+// defaults.
+func (c *Common) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&c.StrictDefault, "strict-default", c.StrictDefault,
+		"check switches for exhaustiveness even when they have a default case")
+	fs.BoolVar(&c.SkipTests, "skip-tests", c.SkipTests,
+		"do not report diagnostics in _test.go files")
+	fs.Var((*listFlag)(&c.Include), "include",
+		"only report diagnostics in packages matching these comma-separated import path patterns, e.g. example.com/app/...")
+	fs.Var((*listFlag)(&c.Exclude), "exclude",
+		"do not report diagnostics in packages matching these comma-separated import path patterns")
+	fs.Var((*listFlag)(&c.Terminators), "terminators",
+		"comma-separated functions or methods that never return, e.g. log.Fatal,go.uber.org/zap.Logger.Fatal*, treated like panic at the end of a default case")
+}
+
+// ChecksPackage reports whether diagnostics are reported in the package
+// with the given import path, according to Include and Exclude.
+func (c *Common) ChecksPackage(path string) bool {
+	if len(c.Include) > 0 && !matchAny(c.Include, path) {
+		return false
+	}
+	return !matchAny(c.Exclude, path)
+}
+
+// ChecksFile reports whether diagnostics are reported in the named file,
+// according to SkipTests.
+func (c *Common) ChecksFile(filename string) bool {
+	return !c.SkipTests || !strings.HasSuffix(filename, "_test.go")
+}
+
+// Funcs is a list of functions by full name, as printed by
+// types.Func.FullName, or by patterns as described for
+// Common.Terminators.
+type Funcs []string
+
+// Contains reports whether fn, or the generic function it instantiates, is
+// in the list. Methods of interfaces and of named types match both their
+// full name and the name of the method on the receiver type, without
+// parentheses or pointer, e.g. "go.uber.org/zap.Logger.Fatal".
+func (f Funcs) Contains(fn *types.Func) bool {
+	if len(f) == 0 {
+		return false
+	}
+	fn = fn.Origin()
+	name := fn.FullName()
+	recvName := ""
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+		recv := sig.Recv().Type()
+		if ptr, ok := recv.(*types.Pointer); ok {
+			recv = ptr.Elem()
+		}
+		if named, ok := types.Unalias(recv).(*types.Named); ok && named.Obj().Pkg() != nil {
+			recvName = named.Obj().Pkg().Path() + "." + named.Obj().Name() + "." + fn.Name()
+		}
+	}
+	for _, t := range f {
+		if matchName(t, name) || (recvName != "" && matchName(t, recvName)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchName reports whether the function name matches pattern, in which *
+// matches any string, except in the pointer receiver "(*".
+func matchName(pattern, name string) bool {
+	if pattern == name || !strings.Contains(strings.ReplaceAll(pattern, "(*", ""), "*") {
+		return pattern == name
+	}
+	re := regexp.QuoteMeta(pattern)
+	re = strings.ReplaceAll(re, `\(\*`, "\x00")
+	re = strings.ReplaceAll(re, `\*`, `.*`)
+	re = strings.ReplaceAll(re, "\x00", `\(\*`)
+	matched, _ := regexp.MatchString("^"+re+"$", name)
+	return matched
+}
+
+// matchAny reports whether path matches one of patterns.
+func matchAny(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern reports whether path matches the import path pattern, in
+// which "..." matches any string. As for the go command, a trailing "/..."
+// also matches the path without it, so "net/..." matches net.
+func matchPattern(pattern, path string) bool {
+	if !strings.Contains(pattern, "...") {
+		return pattern == path
+	}
+	re := regexp.QuoteMeta(pattern)
+	re = strings.ReplaceAll(re, `\.\.\.`, `.*`)
+	if strings.HasSuffix(re, `/.*`) {
+		re = strings.TrimSuffix(re, `/.*`) + `(/.*)?`
+	}
+	matched, _ := regexp.MatchString("^"+re+"$", path)
+	return matched
+}
+
+// ListVar defines a flag with the specified name and usage string in fs,
+// holding a comma-separated list that is stored in p.
+func ListVar(fs *flag.FlagSet, p *[]string, name, usage string) {
+	fs.Var((*listFlag)(p), name, usage)
+}
+
+// listFlag is a flag holding a comma-separated list. An empty value clears
+// the list.
+type listFlag []string
+
+// String implements flag.Value.
+func (f *listFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return strings.Join(*f, ",")
+}
+
+// Set implements flag.Value.
+func (f *listFlag) Set(value string) error {
+	*f = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*f = append(*f, item)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package settings_test
+
+import (
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/YuitoSato/gounion/internal/settings"
+)
+
+func TestChecksPackage(t *testing.T) {
+	c := settings.Common{
+		Include: []string{"example.com/app/..."},
+		Exclude: []string{"example.com/app/internal/gen", ".../mocks"},
+	}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"example.com/app", true},
+		{"example.com/app/shape", true},
+		{"example.com/application", false},
+		{"example.com/lib", false},
+		{"example.com/app/internal/gen", false},
+		{"example.com/app/internal/gen/sub", true},
+		{"example.com/app/shape/mocks", false},
+	}
+	for _, tt := range tests {
+		if got := c.ChecksPackage(tt.path); got != tt.want {
+			t.Errorf("ChecksPackage(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestChecksFile(t *testing.T) {
+	c := settings.Common{SkipTests: true}
+	if c.ChecksFile("/src/shape_test.go") {
+		t.Error("ChecksFile(shape_test.go) = true with SkipTests, want false")
+	}
+	if !c.ChecksFile("/src/shape.go") {
+		t.Error("ChecksFile(shape.go) = false, want true")
+	}
+}
+
+func TestRegisterFlags(t *testing.T) {
+	var c settings.Common
+	fs := flag.NewFlagSet("gounion", flag.ContinueOnError)
+	c.RegisterFlags(fs)
+
+	if err := fs.Parse([]string{"-terminators=log.Fatal, log.Panic,", "-exclude=example.com/gen"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := fs.Lookup("terminators").Value.String(); got != "log.Fatal,log.Panic" {
+		t.Errorf("-terminators = %q, want %q", got, "log.Fatal,log.Panic")
+	}
+	if c.ChecksPackage("example.com/gen") {
+		t.Error("ChecksPackage(example.com/gen) = true, want false")
+	}
+
+	if err := fs.Set("exclude", ""); err != nil || len(c.Exclude) != 0 {
+		t.Errorf("-exclude= left %v, want an empty list", c.Exclude)
+	}
+}
+
+func TestFuncsContains(t *testing.T) {
+	const src = `package log
+
+func Fatal(v ...any)  {}
+func Panicf(f string) {}
+
+type Logger struct{}
+
+func (*Logger) Fatal(msg string) {}
+func (Logger) Info(msg string)   {}
+
+type Sink interface{ Fatalw(msg string) }
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "log.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("example.com/log", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := func(typ, name string) *types.Func {
+		obj, _, _ := types.LookupFieldOrMethod(pkg.Scope().Lookup(typ).Type(), true, pkg, name)
+		return obj.(*types.Func)
+	}
+	fatal := pkg.Scope().Lookup("Fatal").(*types.Func)
+	panicf := pkg.Scope().Lookup("Panicf").(*types.Func)
+	loggerFatal, loggerInfo, sinkFatalw := method("Logger", "Fatal"), method("Logger", "Info"), method("Sink", "Fatalw")
+
+	tests := []struct {
+		funcs settings.Funcs
+		fn    *types.Func
+		want  bool
+	}{
+		{settings.Funcs{"example.com/log.Fatal"}, fatal, true},
+		{settings.Funcs{"example.com/log.Fatal"}, panicf, false},
+		{settings.Funcs{"example.com/log.Panic*"}, panicf, true},
+		{settings.Funcs{"(*example.com/log.Logger).Fatal"}, loggerFatal, true},
+		{settings.Funcs{"example.com/log.Logger.Fatal"}, loggerFatal, true},
+		{settings.Funcs{"example.com/log.Logger.*"}, loggerInfo, true},
+		{settings.Funcs{"(*example.com/log.Logger).Info"}, loggerInfo, false},
+		{settings.Funcs{"example.com/log.Sink.Fatal*"}, sinkFatalw, true},
+		{settings.Funcs{"*.Fatal"}, fatal, true},
+		{settings.Funcs{"*.Fatal"}, loggerFatal, true},
+		{settings.Funcs{"*.Fatal"}, sinkFatalw, false},
+	}
+	for _, tt := range tests {
+		if got := tt.funcs.Contains(tt.fn); got != tt.want {
+			t.Errorf("%q.Contains(%s) = %v, want %v", tt.funcs, tt.fn.FullName(), got, tt.want)
+		}
+	}
+}
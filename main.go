@@ -2,10 +2,9 @@ package main
 
 import (
 	"github.com/YuitoSato/gounion/gounion"
-
-	"golang.org/x/tools/go/analysis/singlechecker"
+	"github.com/YuitoSato/gounion/internal/driver"
 )
 
 func main() {
-	singlechecker.Main(gounion.Analyzer)
+	driver.Main(gounion.Analyzer)
 }
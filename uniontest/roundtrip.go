@@ -0,0 +1,69 @@
+package uniontest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/YuitoSato/gounion/gounionjson"
+)
+
+// Codec encodes and decodes values of the union S in some wire format.
+// Only JSONCodec is provided, as the module depends on no YAML or CBOR
+// library; codecs of other formats are written against this type.
+type Codec[S any] struct {
+	Name      string
+	Marshal   func(S) ([]byte, error)
+	Unmarshal func([]byte) (S, error)
+}
+
+// JSONCodec returns the gounionjson codec for the union S, which relies on
+// the registrations generated by gounion-gen -target=json.
+func JSONCodec[S any]() Codec[S] {
+	return Codec[S]{
+		Name:      "json",
+		Marshal:   gounionjson.Marshal[S],
+		Unmarshal: gounionjson.Unmarshal[S],
+	}
+}
+
+// AssertRoundTrips reports a test failure for every value that doesn't
+// survive an encode→decode round trip with every codec. Decoded values are
+// compared with equal, typically generated by gounion-gen -target=equal
+// (e.g. shape.EqualShape), or with reflect.DeepEqual if equal is nil.
+//
+// AssertMemberRoundTrips also checks that values cover every member.
+func AssertRoundTrips[S any](t testing.TB, codecs []Codec[S], equal func(a, b S) bool, values ...S) {
+	t.Helper()
+
+	if equal == nil {
+		equal = func(a, b S) bool { return reflect.DeepEqual(a, b) }
+	}
+
+	for _, codec := range codecs {
+		for _, v := range values {
+			data, err := codec.Marshal(v)
+			if err != nil {
+				t.Errorf("%s: encoding %T: %v", codec.Name, v, err)
+				continue
+			}
+			got, err := codec.Unmarshal(data)
+			if err != nil {
+				t.Errorf("%s: decoding %T from %s: %v", codec.Name, v, data, err)
+				continue
+			}
+			if !equal(v, got) {
+				t.Errorf("%s: round trip of %T changed the value: got %+v, want %+v", codec.Name, v, got, v)
+			}
+		}
+	}
+}
+
+// AssertMemberRoundTrips is AssertRoundTrips, reporting a test failure as
+// well if values do not cover every member in members, e.g. the generated
+// ShapeMembers(), so that a new member cannot go untested with the codecs.
+func AssertMemberRoundTrips[S any](t testing.TB, members []reflect.Type, codecs []Codec[S], equal func(a, b S) bool, values ...S) {
+	t.Helper()
+
+	AssertCovers(t, members, values...)
+	AssertRoundTrips(t, codecs, equal, values...)
+}
@@ -0,0 +1,33 @@
+// Package uniontest provides test helpers for union interfaces
+// (interfaces with unexported marker methods).
+package uniontest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// AssertCovers reports a test failure if cases does not contain at least
+// one value of every member type in members. members is typically a
+// union's member registry, e.g. the generated ShapeMembers(), so that test
+// tables stay exhaustive as the union grows.
+func AssertCovers[S any](t testing.TB, members []reflect.Type, cases ...S) {
+	t.Helper()
+
+	covered := make(map[reflect.Type]bool, len(cases))
+	for _, c := range cases {
+		covered[reflect.TypeOf(c)] = true
+	}
+
+	var missing []string
+	for _, member := range members {
+		if !covered[member] {
+			missing = append(missing, member.String())
+		}
+	}
+
+	if len(missing) > 0 {
+		t.Errorf("test cases do not cover union members: %s", strings.Join(missing, ", "))
+	}
+}
@@ -0,0 +1,95 @@
+package uniontest_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/YuitoSato/gounion/gounionjson"
+	"github.com/YuitoSato/gounion/uniontest"
+)
+
+type shape interface{ isShape() }
+
+type circle struct {
+	Radius float64
+}
+
+type square struct {
+	Side float64
+}
+
+func (*circle) isShape() {}
+func (*square) isShape() {}
+
+var shapeMembers = []reflect.Type{
+	reflect.TypeOf((*circle)(nil)),
+	reflect.TypeOf((*square)(nil)),
+}
+
+func init() {
+	gounionjson.Register[shape]("circle", func() shape { return new(circle) })
+	gounionjson.Register[shape]("square", func() shape { return new(square) })
+}
+
+// recorder captures failures instead of failing the enclosing test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertCovers(t *testing.T) {
+	uniontest.AssertCovers[shape](t, shapeMembers, &circle{}, &square{})
+}
+
+func TestAssertCoversMissing(t *testing.T) {
+	r := &recorder{TB: t}
+	uniontest.AssertCovers[shape](r, shapeMembers, &circle{})
+
+	want := "test cases do not cover union members: *uniontest_test.square"
+	if len(r.errors) != 1 || r.errors[0] != want {
+		t.Errorf("errors = %q, want [%q]", r.errors, want)
+	}
+}
+
+func TestAssertRoundTrips(t *testing.T) {
+	codecs := []uniontest.Codec[shape]{uniontest.JSONCodec[shape]()}
+	uniontest.AssertRoundTrips[shape](t, codecs, nil, &circle{Radius: 1}, &square{Side: 2})
+}
+
+func TestAssertMemberRoundTripsMissing(t *testing.T) {
+	codecs := []uniontest.Codec[shape]{uniontest.JSONCodec[shape]()}
+	r := &recorder{TB: t}
+	uniontest.AssertMemberRoundTrips[shape](r, shapeMembers, codecs, nil, &circle{Radius: 1})
+
+	want := "test cases do not cover union members: *uniontest_test.square"
+	if len(r.errors) != 1 || r.errors[0] != want {
+		t.Errorf("errors = %q, want [%q]", r.errors, want)
+	}
+}
+
+func TestAssertRoundTripsDrift(t *testing.T) {
+	// A codec that loses the square's side.
+	lossy := uniontest.Codec[shape]{
+		Name: "lossy",
+		Marshal: func(s shape) ([]byte, error) {
+			if _, ok := s.(*square); ok {
+				return gounionjson.Marshal[shape](&square{})
+			}
+			return gounionjson.Marshal(s)
+		},
+		Unmarshal: gounionjson.Unmarshal[shape],
+	}
+
+	r := &recorder{TB: t}
+	uniontest.AssertRoundTrips[shape](r, []uniontest.Codec[shape]{lossy}, nil, &circle{Radius: 1}, &square{Side: 2})
+
+	want := "lossy: round trip of *uniontest_test.square changed the value: got &{Side:0}, want &{Side:2}"
+	if len(r.errors) != 1 || r.errors[0] != want {
+		t.Errorf("errors = %q, want [%q]", r.errors, want)
+	}
+}